@@ -0,0 +1,37 @@
+package main
+
+import (
+	"airbnb/models"
+	"airbnb/repository"
+	"context"
+	"fmt"
+	"os"
+)
+
+const grantAdminUsage = `usage: server grant-admin EMAIL
+
+gives the account with this email the admin role; it signs in again to use it`
+
+// runGrantAdmin handles the grant-admin subcommand, which is how the first
+// admin is made. The API never lets accounts give themselves the role.
+func runGrantAdmin(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("%s", grantAdminUsage)
+	}
+	db, err := repository.ConnectToDB(os.Getenv("DSN"))
+	if err != nil {
+		return err
+	}
+	accounts := repository.NewAccountRepo(db)
+	ctx := context.Background()
+
+	account, err := accounts.GetAccountByEmail(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	if err := accounts.AddRole(ctx, account, models.AdminRole); err != nil {
+		return err
+	}
+	fmt.Println("granted admin to", account.Email)
+	return nil
+}
@@ -2,30 +2,96 @@ package main
 
 import (
 	"airbnb/handlers"
+	"airbnb/mailer"
+	"airbnb/middleware"
+	"airbnb/migrations"
+	"airbnb/models"
 	"airbnb/repository"
 	"airbnb/routes"
+	"airbnb/storage"
+	"context"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"time"
+)
+
+const (
+	denylistRefreshInterval = 30 * time.Second
+	keysReloadInterval      = time.Minute
 )
 
 // @title AirBnb API
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "grant-admin" {
+		if err := runGrantAdmin(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	db, err := repository.ConnectToDB(os.Getenv("DSN"))
 	if err != nil {
 		log.Println(err)
 		return
 	}
 	log.Println("connected to db ")
-	userRepo := repository.NewUserRepo(db)
+	migrator, err := migrations.NewMigrator(db)
+	if err != nil {
+		log.Fatalf("unable to load migrations: %v", err)
+	}
+	requireCurrentSchema(migrator)
+
+	accountRepo := repository.NewAccountRepo(db)
 	bookingRepo := repository.NewBookingRepo(db)
 	propertyRepo := repository.NewPropertyRepo(db)
+	tokenRepo := repository.NewTokenRepo(db)
+	auditRepo := repository.NewAuditRepo(db)
+	reviewRepo := repository.NewReviewRepo(db)
+	messageRepo := repository.NewMessageRepo(db)
+	wishlistRepo := repository.NewWishlistRepo(db)
+	photoRepo := repository.NewPhotoRepo(db)
+	amenityRepo := repository.NewAmenityRepo(db)
+	priceRuleRepo := repository.NewPriceRuleRepo(db)
+
+	denylist := middleware.NewDenylist(tokenRepo)
+	if err := denylist.Reload(context.Background()); err != nil {
+		log.Fatalf("unable to load revoked tokens: %v", err)
+	}
+	go refreshDenylist(denylist, tokenRepo)
+
+	keys, err := loadKeys()
+	if err != nil {
+		log.Fatalf("unable to load signing keys: %v", err)
+	}
+	auth := middleware.NewAuthenticator(accountRepo, denylist, keys)
+
+	go expirePendingBookings(bookingRepo)
 
-	userHandlers := handlers.NewUserHandlers(userRepo)
+	baseURL := os.Getenv("APP_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:8080"
+	}
+	accountHandlers := handlers.NewAccountHandlers(accountRepo, tokenRepo, auth, mailer.FromEnv(), baseURL)
 	bookingHandlers := handlers.NewBookingHandlers(bookingRepo)
-	propertyHandlers := handlers.NewPropertyHandlers(propertyRepo)
+	blobs := storage.FromEnv(baseURL)
+	propertyHandlers := handlers.NewPropertyHandlers(propertyRepo, blobs)
+	adminHandlers := handlers.NewAdminHandlers(accountRepo, bookingRepo, propertyRepo, auditRepo, denylist)
+	reviewHandlers := handlers.NewReviewHandlers(reviewRepo)
+	messageHandlers := handlers.NewMessageHandlers(messageRepo)
+	wishlistHandlers := handlers.NewWishlistHandlers(wishlistRepo, blobs, baseURL)
+	photoHandlers := handlers.NewPhotoHandlers(photoRepo, blobs)
+	amenityHandlers := handlers.NewAmenityHandlers(amenityRepo)
+	priceRuleHandlers := handlers.NewPriceRuleHandlers(priceRuleRepo)
 
-	r := routes.Routes(userRepo, propertyRepo, propertyHandlers, userHandlers, bookingHandlers)
+	r := routes.Routes(auth, propertyRepo, propertyHandlers, accountHandlers, bookingHandlers, adminHandlers, reviewHandlers, messageHandlers, wishlistHandlers, photoHandlers, amenityHandlers, priceRuleHandlers)
 	serverPort := ":8080"
 	srv := &http.Server{
 		Addr:    serverPort,
@@ -37,3 +103,74 @@ func main() {
 	}
 	log.Println("server running....")
 }
+
+// expirePendingBookings periodically expires pending bookings that were never
+// confirmed before their check-in date.
+func expirePendingBookings(repo *repository.BookingRepo) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for {
+		today := time.Now().UTC().Truncate(24 * time.Hour)
+		expired, err := repo.ExpirePendingBookings(context.Background(), today)
+		if err != nil {
+			log.Println("unable to expire pending bookings", err)
+		} else if expired > 0 {
+			log.Printf("expired %d pending bookings", expired)
+		}
+		<-ticker.C
+	}
+}
+
+// refreshDenylist reloads revoked access tokens so revocations made by other
+// instances take effect here within denylistRefreshInterval, and prunes
+// tokens that have expired.
+func refreshDenylist(denylist *middleware.Denylist, repo *repository.TokenRepo) {
+	ticker := time.NewTicker(denylistRefreshInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := repo.PruneExpiredTokens(context.Background(), time.Now()); err != nil {
+			log.Println("unable to prune expired tokens", err)
+		}
+		if err := denylist.Reload(context.Background()); err != nil {
+			log.Println("unable to reload revoked tokens", err)
+		}
+	}
+}
+
+// loadKeys builds the signing key manager. With JWT_KEYS_FILE set the key set
+// is read from that file and reloaded every keysReloadInterval, so keys can be
+// rotated without a restart. Otherwise SECRET_KEY is the only key.
+func loadKeys() (*middleware.KeyManager, error) {
+	path := os.Getenv("JWT_KEYS_FILE")
+	if path == "" {
+		secret := os.Getenv("SECRET_KEY")
+		if secret == "" {
+			return nil, fmt.Errorf("set JWT_KEYS_FILE or SECRET_KEY")
+		}
+		key := middleware.SigningKey{ID: "default", Algorithm: middleware.HS256, Secret: []byte(secret)}
+		return middleware.NewKeyManager(models.AccessTokenTTL, key.ID, key)
+	}
+
+	active, keySet, err := middleware.LoadKeyFile(path)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := middleware.NewKeyManager(models.AccessTokenTTL, active, keySet...)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		ticker := time.NewTicker(keysReloadInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			active, keySet, err := middleware.LoadKeyFile(path)
+			if err == nil {
+				err = keys.SetKeys(active, keySet, time.Now())
+			}
+			if err != nil {
+				log.Println("unable to reload signing keys, keeping the current ones", err)
+			}
+		}
+	}()
+	return keys, nil
+}
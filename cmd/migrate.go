@@ -0,0 +1,103 @@
+package main
+
+import (
+	"airbnb/migrations"
+	"airbnb/repository"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+)
+
+const migrateUsage = `usage: server migrate <command>
+
+commands:
+  up           apply every pending migration
+  down N       roll back the N most recently applied migrations
+  status       list migrations and when they were applied
+  create NAME  write empty up and down files for a new migration`
+
+// runMigrate handles the migrate subcommand. create only writes files, so it
+// runs without a database connection.
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%s", migrateUsage)
+	}
+	if args[0] == "create" {
+		if len(args) != 2 {
+			return fmt.Errorf("%s", migrateUsage)
+		}
+		dir := os.Getenv("MIGRATIONS_DIR")
+		if dir == "" {
+			dir = "migrations"
+		}
+		up, down, err := migrations.Create(dir, args[1])
+		if err != nil {
+			return err
+		}
+		fmt.Println("created", up)
+		fmt.Println("created", down)
+		return nil
+	}
+
+	db, err := repository.ConnectToDB(os.Getenv("DSN"))
+	if err != nil {
+		return err
+	}
+	migrator, err := migrations.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	switch {
+	case args[0] == "up" && len(args) == 1:
+		applied, err := migrator.Up(ctx)
+		for _, m := range applied {
+			fmt.Printf("applied %04d_%s\n", m.Version, m.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("schema is up to date")
+		}
+		return err
+	case args[0] == "down" && len(args) == 2:
+		n, err := strconv.Atoi(args[1])
+		if err != nil {
+			return migrations.ErrInvalidSteps
+		}
+		rolledBack, err := migrator.Down(ctx, n)
+		for _, m := range rolledBack {
+			fmt.Printf("rolled back %04d_%s\n", m.Version, m.Name)
+		}
+		return err
+	case args[0] == "status" && len(args) == 1:
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%s\t%s\n", s.Version, s.Name, applied)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%s", migrateUsage)
+	}
+}
+
+// requireCurrentSchema stops the server from starting against a database that
+// is missing migrations this binary expects.
+func requireCurrentSchema(migrator *migrations.Migrator) {
+	pending, err := migrator.Pending(context.Background())
+	if err != nil {
+		log.Fatalf("unable to check schema migrations: %v", err)
+	}
+	if len(pending) > 0 {
+		log.Fatalf("database schema is behind by %d migrations (next %04d_%s); run `server migrate up`",
+			len(pending), pending[0].Version, pending[0].Name)
+	}
+}
@@ -1,4 +1,5 @@
-// Package docs Code generated by swaggo/swag. DO NOT EDIT
+// Code generated by swaggo/swag. DO NOT EDIT.
+
 package docs
 
 import "github.com/swaggo/swag"
@@ -15,73 +16,73 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/cancel/booking/{bookingid}": {
-            "delete": {
-                "description": "A Property owner or a user can cancel a booking",
+        "/.well-known/jwks.json": {
+            "get": {
+                "description": "Publishes the public keys access tokens are signed with, as a JSON Web Key Set. Match a token's kid header to a key to verify it",
                 "tags": [
-                    "Bookings"
-                ],
-                "summary": "Confirm Bookings",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "ID",
-                        "name": "bookingid",
-                        "in": "path",
-                        "required": true
-                    }
+                    "Auth"
                 ],
+                "summary": "Signing Keys",
                 "responses": {
                     "200": {
-                        "description": "booking confirmed"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.JWKS"
+                        }
                     }
                 }
             }
         },
-        "/owner/booking/all": {
-            "get": {
-                "description": "A Property owner gets all  booking",
+        "/account/login": {
+            "post": {
+                "description": "Guests, hosts and admins all sign in here. The access token carries the account's roles and expires after 15 minutes; use the refresh token to get a new one",
                 "tags": [
-                    "Bookings"
+                    "Account"
                 ],
-                "summary": "Get Bookings",
+                "summary": "Signin",
                 "parameters": [
                     {
-                        "type": "string",
-                        "default": "Bearer \u003cAdd access token here\u003e",
-                        "description": "Insert your access token",
-                        "name": "Authorization",
-                        "in": "header",
-                        "required": true
+                        "description": "Login Request",
+                        "name": "LoginAccount",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.LoginAccount"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.PropertyBooking"
-                            }
+                            "$ref": "#/definitions/models.AuthTokens"
                         }
+                    },
+                    "401": {
+                        "description": "invalid email or password"
+                    },
+                    "403": {
+                        "description": "account is suspended"
                     }
                 }
             }
         },
-        "/owner/booking/{bookingid}": {
-            "get": {
-                "description": "A Property owner gets a particular  bookings data",
+        "/account/roles": {
+            "post": {
+                "description": "Gives the signed-in account the guest or host role, e.g. a guest who starts hosting. Refresh the access token to use the new role",
                 "tags": [
-                    "Bookings"
+                    "Account"
                 ],
-                "summary": "Get Bookings",
+                "summary": "Add Role",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "ID",
-                        "name": "bookingid",
-                        "in": "path",
-                        "required": true
+                        "description": "Add Role Request",
+                        "name": "AddRole",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.AddRole"
+                        }
                     },
                     {
                         "type": "string",
@@ -94,79 +95,92 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "role added"
+                    },
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/models.PropertyBooking"
+                            "$ref": "#/definitions/models.Problem"
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "A Property owner confirms a booking",
+            }
+        },
+        "/account/signup": {
+            "post": {
+                "description": "Creates an account with the guest role, the host role or both. Roles defaults to guest. A verification link is emailed to the account, which must be opened before it can book",
                 "tags": [
-                    "Bookings"
+                    "Account"
                 ],
-                "summary": "Confirm Bookings",
+                "summary": "SignUp",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "ID",
-                        "name": "bookingid",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "default": "Bearer \u003cAdd access token here\u003e",
-                        "description": "Insert your access token",
-                        "name": "Authorization",
-                        "in": "header",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "booking confirmed"
+                        "description": "Create Account Request",
+                        "name": "CreateAccount",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateAccount"
+                        }
                     }
-                }
-            }
-        },
-        "/property/all": {
-            "get": {
-                "description": "A User Owner gets all  properties  available",
-                "tags": [
-                    "Property Owner"
                 ],
-                "summary": "Get all Property",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.GetProperty"
-                            }
+                            "$ref": "#/definitions/models.AuthTokens"
                         }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "an account with this email already exists"
                     }
                 }
             }
         },
-        "/property/create": {
-            "post": {
-                "description": "A Property Owner creates a property",
+        "/admin/accounts": {
+            "get": {
+                "description": "Lists guests, hosts and admins, newest first. q matches name or email; filter by role and by status (active or suspended). Pass next_cursor back as cursor for the next page",
                 "tags": [
-                    "Property Owner"
+                    "Admin"
                 ],
-                "summary": "Create Property",
+                "summary": "List Accounts",
                 "parameters": [
                     {
-                        "description": "Create Property Request",
-                        "name": "Owner",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.CreateProperty"
-                        }
+                        "type": "string",
+                        "description": "Name or email contains",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "guest, host or admin",
+                        "name": "role",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "active or suspended",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size, 1-100",
+                        "name": "limit",
+                        "in": "query"
                     },
                     {
                         "type": "string",
@@ -179,19 +193,35 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "property created  successfully"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.AdminAccounts"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
                     }
                 }
             }
         },
-        "/property/owner": {
+        "/admin/accounts/{accountid}": {
             "get": {
-                "description": "A Property Owner gets all his  properties and its details",
+                "description": "Gets an account, including whether and why it is suspended",
                 "tags": [
-                    "Property Owner"
+                    "Admin"
                 ],
-                "summary": "Get all Property",
+                "summary": "Get Account",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID",
+                        "name": "accountid",
+                        "in": "path",
+                        "required": true
+                    },
                     {
                         "type": "string",
                         "default": "Bearer \u003cAdd access token here\u003e",
@@ -205,73 +235,85 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.GetProperty"
-                            }
+                            "$ref": "#/definitions/models.AdminAccount"
                         }
+                    },
+                    "404": {
+                        "description": "account not found"
                     }
                 }
             }
         },
-        "/property/owner/login": {
+        "/admin/accounts/{accountid}/reinstate": {
             "post": {
-                "description": "A Property Owner signs-in",
+                "description": "Lifts an account's suspension. Its holder signs in again to get new tokens",
                 "tags": [
-                    "Property Owner"
+                    "Admin"
                 ],
-                "summary": "SignIn Property Owner",
+                "summary": "Reinstate Account",
                 "parameters": [
                     {
-                        "description": "Create Property Owner Request",
-                        "name": "Owner",
+                        "type": "string",
+                        "description": "ID",
+                        "name": "accountid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reason",
+                        "name": "Action",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.LoginPropertyOwner"
+                            "$ref": "#/definitions/models.AdminAction"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "property owner successfully"
+                        "description": "account reinstated"
+                    },
+                    "404": {
+                        "description": "account not found"
+                    },
+                    "409": {
+                        "description": "account is not suspended"
                     }
                 }
             }
         },
-        "/property/owner/signup": {
+        "/admin/accounts/{accountid}/suspend": {
             "post": {
-                "description": "A Property Owner signups",
+                "description": "Suspends an account and signs it out of every session. A suspended account cannot sign in or use its tokens until it is reinstated",
                 "tags": [
-                    "Property Owner"
+                    "Admin"
                 ],
-                "summary": "SignUp Property Owner",
+                "summary": "Suspend Account",
                 "parameters": [
                     {
-                        "description": "Create Property Owner Request",
-                        "name": "Owner",
+                        "type": "string",
+                        "description": "ID",
+                        "name": "accountid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reason",
+                        "name": "Action",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.CreatePropertyOwner"
+                            "$ref": "#/definitions/models.AdminAction"
                         }
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "property owner successfully"
-                    }
-                }
-            }
-        },
-        "/property/{propertyid}": {
-            "get": {
-                "description": "A Property Owner gets a property details",
-                "tags": [
-                    "Property Owner"
-                ],
-                "summary": "Get a  Property",
-                "parameters": [
+                    },
                     {
                         "type": "string",
                         "default": "Bearer \u003cAdd access token here\u003e",
@@ -283,22 +325,37 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "property created  successfully",
-                        "schema": {
-                            "$ref": "#/definitions/models.GetProperty"
-                        }
+                        "description": "account suspended"
+                    },
+                    "403": {
+                        "description": "admins cannot suspend their own account"
+                    },
+                    "404": {
+                        "description": "account not found"
+                    },
+                    "409": {
+                        "description": "account is already suspended"
                     }
                 }
             }
         },
-        "/user/booking": {
-            "get": {
-                "description": "A User gets his list of bookings",
+        "/admin/amenities": {
+            "post": {
+                "description": "Adds an amenity to the catalogue. Its key, made of lowercase letters, digits and underscores, cannot be changed later",
                 "tags": [
-                    "Bookings"
+                    "Admin"
                 ],
-                "summary": "Get Bookings",
+                "summary": "Create Amenity",
                 "parameters": [
+                    {
+                        "description": "Create Amenity Request",
+                        "name": "Amenity",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateAmenity"
+                        }
+                    },
                     {
                         "type": "string",
                         "default": "Bearer \u003cAdd access token here\u003e",
@@ -312,27 +369,33 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.UserGetBooking"
-                            }
+                            "$ref": "#/definitions/models.GetAmenity"
                         }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "an amenity with this key already exists"
                     }
                 }
             }
         },
-        "/user/booking/{bookingid}": {
-            "get": {
-                "description": "A User gets a booking",
+        "/admin/amenities/{key}": {
+            "delete": {
+                "description": "Removes an amenity from the catalogue and from every property that has it",
                 "tags": [
-                    "Bookings"
+                    "Admin"
                 ],
-                "summary": "Get Bookings",
+                "summary": "Delete Amenity",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "ID",
-                        "name": "bookingid",
+                        "description": "Key",
+                        "name": "key",
                         "in": "path",
                         "required": true
                     },
@@ -347,29 +410,36 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/models.UserGetBooking"
-                        }
+                        "description": "amenity deleted"
+                    },
+                    "404": {
+                        "description": "amenity not found"
                     }
                 }
-            }
-        },
-        "/user/booking/{propertyid}": {
-            "post": {
-                "description": "A User Books a property or apartment",
+            },
+            "patch": {
+                "description": "Changes the name shown for an amenity",
                 "tags": [
-                    "Bookings"
+                    "Admin"
                 ],
-                "summary": "Book Property",
+                "summary": "Rename Amenity",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "ID",
-                        "name": "propertyid",
+                        "description": "Key",
+                        "name": "key",
                         "in": "path",
                         "required": true
                     },
+                    {
+                        "description": "Update Amenity Request",
+                        "name": "Amenity",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateAmenity"
+                        }
+                    },
                     {
                         "type": "string",
                         "default": "Bearer \u003cAdd access token here\u003e",
@@ -381,175 +451,4426 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "successfully booked"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.GetAmenity"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "amenity not found"
                     }
                 }
             }
         },
-        "/user/login": {
+        "/admin/audit": {
+            "get": {
+                "description": "Lists admin actions, newest first, optionally filtered by the admin who took them, what they acted on or the action, e.g. account.suspend",
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Audit Log",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Admin account ID",
+                        "name": "actor_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Account, booking or property ID",
+                        "name": "target_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Action",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size, 1-100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.AuditLog"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/bookings/{bookingid}/cancel": {
+            "post": {
+                "description": "Cancels any pending or confirmed booking with a full refund to the guest, whatever the property's cancellation policy",
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Cancel Booking",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID",
+                        "name": "bookingid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reason",
+                        "name": "Action",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.AdminAction"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.CancelledBooking"
+                        }
+                    },
+                    "404": {
+                        "description": "booking not found"
+                    },
+                    "409": {
+                        "description": "booking can no longer be cancelled"
+                    }
+                }
+            }
+        },
+        "/admin/properties/{propertyid}/relist": {
+            "post": {
+                "description": "Makes an unlisted property visible and bookable again",
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Relist Property",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID",
+                        "name": "propertyid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reason",
+                        "name": "Action",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.AdminAction"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "property relisted"
+                    },
+                    "404": {
+                        "description": "property not found"
+                    },
+                    "409": {
+                        "description": "property is not unlisted"
+                    }
+                }
+            }
+        },
+        "/admin/properties/{propertyid}/unlist": {
+            "post": {
+                "description": "Hides a property from search, quotes and new bookings. Its owner still sees it; existing bookings are not cancelled",
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Unlist Property",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID",
+                        "name": "propertyid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reason",
+                        "name": "Action",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.AdminAction"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "property unlisted"
+                    },
+                    "404": {
+                        "description": "property not found"
+                    },
+                    "409": {
+                        "description": "property is already unlisted"
+                    }
+                }
+            }
+        },
+        "/amenities": {
+            "get": {
+                "description": "Lists the amenities a property can have, sorted by name. Their keys are used in a property's amenities and to filter the listing",
+                "tags": [
+                    "Property"
+                ],
+                "summary": "List Amenities",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.GetAmenity"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/logout": {
+            "post": {
+                "description": "Signs out the session the refresh token belongs to and revokes the access token used for the request",
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Logout",
+                "parameters": [
+                    {
+                        "description": "Logout Request",
+                        "name": "Logout",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RefreshTokenRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "logged out"
+                    },
+                    "400": {
+                        "description": "refresh token is invalid or expired"
+                    }
+                }
+            }
+        },
+        "/auth/logout-all": {
+            "post": {
+                "description": "Signs the account out of every session by revoking all of its refresh tokens and the access tokens issued with them",
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Logout Everywhere",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "logged out of all sessions"
+                    }
+                }
+            }
+        },
+        "/auth/password/forgot": {
+            "post": {
+                "description": "Emails a password reset code, valid for an hour, if an account uses the email. The response is the same either way so it does not reveal which emails are registered",
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Forgot Password",
+                "parameters": [
+                    {
+                        "description": "Forgot Password Request",
+                        "name": "ForgotPassword",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ForgotPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "if an account uses this email, a reset code has been sent"
+                    }
+                }
+            }
+        },
+        "/auth/password/reset": {
+            "post": {
+                "description": "Sets a new password with the emailed reset code. Each code works once, and every session of the account is signed out",
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Reset Password",
+                "parameters": [
+                    {
+                        "description": "Reset Password Request",
+                        "name": "ResetPassword",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ResetPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "password reset"
+                    },
+                    "400": {
+                        "description": "token is invalid, expired or already used"
+                    }
+                }
+            }
+        },
+        "/auth/refresh": {
             "post": {
-                "description": "A User log's in",
+                "description": "Exchanges a refresh token for a new access token and refresh token. Each refresh token works once; presenting a used one signs out every session that descends from it",
                 "tags": [
-                    "User"
+                    "Auth"
                 ],
-                "summary": "Signin user",
+                "summary": "Refresh Tokens",
                 "parameters": [
                     {
-                        "description": "Create User Request",
-                        "name": "CreateUser",
+                        "description": "Refresh Request",
+                        "name": "Refresh",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.LoginUser"
+                            "$ref": "#/definitions/models.RefreshTokenRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "login successful"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.AuthTokens"
+                        }
+                    },
+                    "401": {
+                        "description": "refresh token is invalid or expired"
+                    },
+                    "403": {
+                        "description": "account is suspended"
+                    }
+                }
+            }
+        },
+        "/auth/verify-email": {
+            "get": {
+                "description": "Confirms the account's email address with the token from the verification link. Each link works once",
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Verify Email",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Verification token",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
                     }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "email verified"
+                    },
+                    "400": {
+                        "description": "token is invalid, expired or already used"
+                    }
+                }
+            }
+        },
+        "/auth/verify-email/resend": {
+            "post": {
+                "description": "Emails a new verification link to the signed-in account. Earlier links stop working",
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Resend Verification Email",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "verification email sent"
+                    },
+                    "409": {
+                        "description": "email is already verified"
+                    }
+                }
+            }
+        },
+        "/cancel/booking/{bookingid}": {
+            "delete": {
+                "description": "The guest or the owning host cancels a pending or confirmed booking. The refund follows the property's cancellation policy when the guest cancels a confirmed booking, and is the full total otherwise",
+                "tags": [
+                    "Bookings"
+                ],
+                "summary": "Cancel Bookings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID",
+                        "name": "bookingid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Cancel Booking Request",
+                        "name": "Cancel",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.CancelBooking"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.CancelledBooking"
+                        }
+                    },
+                    "404": {
+                        "description": "booking not found"
+                    },
+                    "409": {
+                        "description": "booking cannot be cancelled from its current status"
+                    }
+                }
+            }
+        },
+        "/conversations/{conversationid}/messages": {
+            "get": {
+                "description": "Lists a conversation's messages, newest first, each marked read once the recipient has read it. Listing does not mark messages read; see Mark Read",
+                "tags": [
+                    "Messages"
+                ],
+                "summary": "Get Messages",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID",
+                        "name": "conversationid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size, 1-100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.GetMessages"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "conversation not found"
+                    }
+                }
+            },
+            "post": {
+                "description": "Replies in a conversation. Replying marks the conversation read for the sender",
+                "tags": [
+                    "Messages"
+                ],
+                "summary": "Send Message",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID",
+                        "name": "conversationid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Send Message Request",
+                        "name": "Message",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SendMessage"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.GetMessage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "conversation not found"
+                    }
+                }
+            }
+        },
+        "/conversations/{conversationid}/read": {
+            "put": {
+                "description": "Marks every message in the conversation read for the signed-in participant",
+                "tags": [
+                    "Messages"
+                ],
+                "summary": "Mark Read",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID",
+                        "name": "conversationid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.GetConversation"
+                        }
+                    },
+                    "404": {
+                        "description": "conversation not found"
+                    }
+                }
+            }
+        },
+        "/inbox": {
+            "get": {
+                "description": "Lists the conversations the account takes part in as a guest or a host, most recently active first, with unread counts. Pass next_cursor back as cursor for the next page",
+                "tags": [
+                    "Messages"
+                ],
+                "summary": "Inbox",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size, 1-100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Inbox"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/me": {
+            "get": {
+                "description": "Gets the signed-in account's profile",
+                "tags": [
+                    "Profile"
+                ],
+                "summary": "Get Profile",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Profile"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes the account and its properties after checking the password. Upcoming bookings, as a guest or on the account's properties, must be cancelled first. Past bookings are kept for the other party's records without the account's name or email",
+                "tags": [
+                    "Profile"
+                ],
+                "summary": "Delete Account",
+                "parameters": [
+                    {
+                        "description": "Delete Account Request",
+                        "name": "Confirm",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.DeleteAccount"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "account deleted"
+                    },
+                    "401": {
+                        "description": "current password is incorrect"
+                    },
+                    "409": {
+                        "description": "account has upcoming bookings"
+                    }
+                }
+            },
+            "patch": {
+                "description": "Changes the account's name and email. Fields left out of the body are unchanged. A new email is sent a verification link and must be verified again before the account can book",
+                "tags": [
+                    "Profile"
+                ],
+                "summary": "Update Profile",
+                "parameters": [
+                    {
+                        "description": "Update Profile Request",
+                        "name": "Profile",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateProfile"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Profile"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "an account with this email already exists"
+                    }
+                }
+            }
+        },
+        "/me/password": {
+            "put": {
+                "description": "Changes the password after checking the current one. Every session is signed out and a new pair of tokens is returned for this one",
+                "tags": [
+                    "Profile"
+                ],
+                "summary": "Change Password",
+                "parameters": [
+                    {
+                        "description": "Change Password Request",
+                        "name": "Password",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ChangePassword"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.AuthTokens"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "current password is incorrect"
+                    }
+                }
+            }
+        },
+        "/media/{key}": {
+            "get": {
+                "description": "Downloads a stored file, such as a photo from a property's photo URLs",
+                "tags": [
+                    "Media"
+                ],
+                "summary": "Get File",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Key of the file",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "the file"
+                    },
+                    "404": {
+                        "description": "file not found"
+                    }
+                }
+            }
+        },
+        "/owner/booking/all": {
+            "get": {
+                "description": "A Property owner gets all  booking",
+                "tags": [
+                    "Bookings"
+                ],
+                "summary": "Get Bookings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.PropertyBooking"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/owner/booking/{bookingid}": {
+            "get": {
+                "description": "A Property owner gets a particular  bookings data",
+                "tags": [
+                    "Bookings"
+                ],
+                "summary": "Get Bookings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID",
+                        "name": "bookingid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.PropertyBooking"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "A Property owner confirms a pending booking",
+                "tags": [
+                    "Bookings"
+                ],
+                "summary": "Confirm Bookings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID",
+                        "name": "bookingid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "booking confirmed"
+                    },
+                    "404": {
+                        "description": "booking not found"
+                    },
+                    "409": {
+                        "description": "booking cannot be confirmed from its current status"
+                    }
+                }
+            }
+        },
+        "/owner/booking/{bookingid}/checkin": {
+            "put": {
+                "description": "A Property owner marks a confirmed booking's guest as checked in",
+                "tags": [
+                    "Bookings"
+                ],
+                "summary": "Check In Bookings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID",
+                        "name": "bookingid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "guest checked in"
+                    },
+                    "404": {
+                        "description": "booking not found"
+                    },
+                    "409": {
+                        "description": "booking cannot be checked in from its current status"
+                    }
+                }
+            }
+        },
+        "/owner/booking/{bookingid}/complete": {
+            "put": {
+                "description": "A Property owner marks a checked-in booking as completed",
+                "tags": [
+                    "Bookings"
+                ],
+                "summary": "Complete Bookings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID",
+                        "name": "bookingid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "booking completed"
+                    },
+                    "404": {
+                        "description": "booking not found"
+                    },
+                    "409": {
+                        "description": "booking cannot be completed from its current status"
+                    }
+                }
+            }
+        },
+        "/owner/booking/{bookingid}/decline": {
+            "put": {
+                "description": "A Property owner declines a pending booking",
+                "tags": [
+                    "Bookings"
+                ],
+                "summary": "Decline Bookings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID",
+                        "name": "bookingid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "booking declined"
+                    },
+                    "404": {
+                        "description": "booking not found"
+                    },
+                    "409": {
+                        "description": "booking cannot be declined from its current status"
+                    }
+                }
+            }
+        },
+        "/owner/booking/{bookingid}/review": {
+            "post": {
+                "description": "A host rates a guest from 1 to 5 after their stay. Allowed once per booking, after a confirmed stay's check-out date",
+                "tags": [
+                    "Reviews"
+                ],
+                "summary": "Review Guest",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID",
+                        "name": "bookingid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Create Review Request",
+                        "name": "Review",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateReview"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.GetReview"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "booking not found"
+                    },
+                    "409": {
+                        "description": "reviews can be left once a confirmed stay has checked out"
+                    }
+                }
+            }
+        },
+        "/owner/review/{reviewid}/reply": {
+            "put": {
+                "description": "A host replies once to a guest's review of one of their properties",
+                "tags": [
+                    "Reviews"
+                ],
+                "summary": "Reply to Review",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID",
+                        "name": "reviewid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reply Request",
+                        "name": "Reply",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ReplyToReview"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.GetReview"
+                        }
+                    },
+                    "404": {
+                        "description": "review not found"
+                    },
+                    "409": {
+                        "description": "this review already has a reply"
+                    }
+                }
+            }
+        },
+        "/property/all": {
+            "get": {
+                "description": "A User searches the properties available. Results are paginated; pass next_cursor back as cursor to fetch the next page",
+                "tags": [
+                    "Property Owner"
+                ],
+                "summary": "Get all Property",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Text to find in the name or description",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Text to find in the address",
+                        "name": "location",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum nightly price in minor units",
+                        "name": "min_price",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum nightly price in minor units",
+                        "name": "max_price",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of guests the property must fit",
+                        "name": "guests",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2025-01-10",
+                        "description": "Only properties free from this date",
+                        "name": "check_in",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2025-01-15",
+                        "description": "Only properties free until this date",
+                        "name": "check_out",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "entire_home, private_room or shared_room",
+                        "name": "property_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum number of bedrooms",
+                        "name": "min_bedrooms",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum number of beds",
+                        "name": "min_beds",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum number of bathrooms",
+                        "name": "min_bathrooms",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "wifi,kitchen",
+                        "description": "Comma-separated amenity keys the property must all have",
+                        "name": "amenities",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only properties that allow pets",
+                        "name": "pets_allowed",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only properties that allow smoking",
+                        "name": "smoking_allowed",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only properties that allow events",
+                        "name": "events_allowed",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "newest (default), price_asc, price_desc or rating",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, 1-100 (default 20)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Map viewport south edge",
+                        "name": "min_lat",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Map viewport west edge",
+                        "name": "min_lng",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Map viewport north edge",
+                        "name": "max_lat",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Map viewport east edge; below min_lng when crossing the antimeridian",
+                        "name": "max_lng",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.GetAllProperties"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid search"
+                    }
+                }
+            }
+        },
+        "/property/create": {
+            "post": {
+                "description": "A Property Owner creates a property",
+                "tags": [
+                    "Property Owner"
+                ],
+                "summary": "Create Property",
+                "parameters": [
+                    {
+                        "description": "Create Property Request",
+                        "name": "Owner",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateProperty"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "property created  successfully"
+                    }
+                }
+            }
+        },
+        "/property/nearby": {
+            "get": {
+                "description": "A User finds properties within a radius of a point, closest first",
+                "tags": [
+                    "Property Owner"
+                ],
+                "summary": "Nearby Properties",
+                "parameters": [
+                    {
+                        "type": "number",
+                        "description": "Latitude",
+                        "name": "lat",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "Longitude",
+                        "name": "lng",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "Search radius in kilometres, up to 500 (default 10)",
+                        "name": "radius_km",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum results, 1-100 (default 20)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.GetNearbyProperties"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid coordinates or radius"
+                    }
+                }
+            }
+        },
+        "/property/owner": {
+            "get": {
+                "description": "A Property Owner gets all his  properties and its details",
+                "tags": [
+                    "Property Owner"
+                ],
+                "summary": "Get all Property",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.GetProperty"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/property/{propertyid}": {
+            "get": {
+                "description": "A Property Owner gets one of his property details",
+                "tags": [
+                    "Property Owner"
+                ],
+                "summary": "Get a  Property",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID",
+                        "name": "propertyid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "property created  successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.GetProperty"
+                        }
+                    },
+                    "404": {
+                        "description": "property not found"
+                    }
+                }
+            },
+            "delete": {
+                "description": "A Property Owner delists a property. Pending bookings are declined. Upcoming confirmed bookings block deletion unless force=true, which cancels them with a full refund",
+                "tags": [
+                    "Property Owner"
+                ],
+                "summary": "Delete a Property",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID",
+                        "name": "propertyid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Cancel upcoming confirmed bookings",
+                        "name": "force",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "property deleted"
+                    },
+                    "404": {
+                        "description": "property not found"
+                    },
+                    "409": {
+                        "description": "property has upcoming confirmed bookings or guests checked in"
+                    }
+                }
+            },
+            "patch": {
+                "description": "A Property Owner updates some of his property's fields. Fields left out of the body are unchanged",
+                "tags": [
+                    "Property Owner"
+                ],
+                "summary": "Update a Property",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID",
+                        "name": "propertyid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update Property Request",
+                        "name": "Property",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateProperty"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.GetProperty"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid field"
+                    },
+                    "404": {
+                        "description": "property not found"
+                    }
+                }
+            }
+        },
+        "/property/{propertyid}/availability": {
+            "get": {
+                "description": "Returns a per-night calendar for a property. Nights run from the from date up to, but not including, the to date. Dates use the YYYY-MM-DD format. Free nights no stay allowed by the property's stay rules can cover are unbookable, with the reason: past, advance_notice, booking_window, min_nights or check_in_day",
+                "tags": [
+                    "Bookings"
+                ],
+                "summary": "Property Availability",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID",
+                        "name": "propertyid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "2025-01-01",
+                        "description": "First night",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "2025-02-01",
+                        "description": "Night after the last night",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.PropertyAvailability"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid dates"
+                    },
+                    "404": {
+                        "description": "property not found"
+                    }
+                }
+            }
+        },
+        "/property/{propertyid}/block": {
+            "post": {
+                "description": "A Property owner blocks a range of nights, e.g. for maintenance. The end date is the first night that is available again",
+                "tags": [
+                    "Bookings"
+                ],
+                "summary": "Block Dates",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID",
+                        "name": "propertyid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Block Dates Request",
+                        "name": "Block",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.BlockDates"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "dates blocked"
+                    },
+                    "400": {
+                        "description": "invalid dates"
+                    },
+                    "404": {
+                        "description": "property not found"
+                    },
+                    "409": {
+                        "description": "property is already booked for the selected dates"
+                    }
+                }
+            }
+        },
+        "/property/{propertyid}/block/{blockid}": {
+            "delete": {
+                "description": "A Property owner removes a blocked date range",
+                "tags": [
+                    "Bookings"
+                ],
+                "summary": "Unblock Dates",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID",
+                        "name": "propertyid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Block ID",
+                        "name": "blockid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "dates unblocked"
+                    },
+                    "404": {
+                        "description": "blocked date range not found"
+                    }
+                }
+            }
+        },
+        "/property/{propertyid}/photos": {
+            "put": {
+                "description": "A Property Owner sets the order of the property's photos by listing each of them once. The first is the cover",
+                "tags": [
+                    "Property Owner"
+                ],
+                "summary": "Reorder Photos",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID",
+                        "name": "propertyid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reorder Photos Request",
+                        "name": "Photos",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ReorderPhotos"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.GetPhotos"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "property not found"
+                    }
+                }
+            },
+            "post": {
+                "description": "A Property Owner adds a JPEG or PNG photo, up to 10 MiB, after the property's other photos. The type is taken from the file's content. The stored copies are re-encoded at several sizes without the original's metadata, turned upright first if the EXIF orientation says so",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "tags": [
+                    "Property Owner"
+                ],
+                "summary": "Upload Photo",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID",
+                        "name": "propertyid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Photo",
+                        "name": "photo",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.GetPhoto"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "property not found"
+                    },
+                    "409": {
+                        "description": "a property can have up to 30 photos"
+                    },
+                    "413": {
+                        "description": "photo must be at most 10 MiB"
+                    },
+                    "415": {
+                        "description": "photo must be a JPEG or PNG image"
+                    }
+                }
+            }
+        },
+        "/property/{propertyid}/photos/{photoid}": {
+            "delete": {
+                "description": "A Property Owner deletes a photo and its files. The photos after it move up; deleting the cover makes the next photo the cover",
+                "tags": [
+                    "Property Owner"
+                ],
+                "summary": "Delete Photo",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID",
+                        "name": "propertyid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID",
+                        "name": "photoid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "photo deleted"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "property or photo not found"
+                    }
+                }
+            }
+        },
+        "/property/{propertyid}/photos/{photoid}/cover": {
+            "put": {
+                "description": "A Property Owner makes a photo the cover by moving it to the front; the others keep their order",
+                "tags": [
+                    "Property Owner"
+                ],
+                "summary": "Set Cover Photo",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID",
+                        "name": "propertyid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID",
+                        "name": "photoid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.GetPhotos"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "property or photo not found"
+                    }
+                }
+            }
+        },
+        "/property/{propertyid}/pricing": {
+            "get": {
+                "description": "A Property Owner gets the property's base price, length-of-stay discounts and price rules, in the order they were added",
+                "tags": [
+                    "Property Owner"
+                ],
+                "summary": "Get Pricing",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID",
+                        "name": "propertyid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.GetPricing"
+                        }
+                    },
+                    "404": {
+                        "description": "property not found"
+                    }
+                }
+            }
+        },
+        "/property/{propertyid}/pricing/rules": {
+            "post": {
+                "description": "A Property Owner adds a date_range rule, which sets the price of the nights from start_date up to end_date, or a weekday rule, which raises or lowers the base price on some weekdays by adjustment basis points. Where date ranges overlap the shortest one wins, then the one added last; weekday rules do not apply to nights a date range covers, and weekday rules for the same day add up",
+                "tags": [
+                    "Property Owner"
+                ],
+                "summary": "Add Price Rule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID",
+                        "name": "propertyid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Create Price Rule Request",
+                        "name": "Rule",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreatePriceRule"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.GetPriceRule"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "property not found"
+                    },
+                    "409": {
+                        "description": "a property can have up to 100 price rules"
+                    }
+                }
+            }
+        },
+        "/property/{propertyid}/pricing/rules/{ruleid}": {
+            "delete": {
+                "description": "A Property Owner deletes a price rule",
+                "tags": [
+                    "Property Owner"
+                ],
+                "summary": "Delete Price Rule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID",
+                        "name": "propertyid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID",
+                        "name": "ruleid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "price rule deleted"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "property or price rule not found"
+                    }
+                }
+            }
+        },
+        "/property/{propertyid}/quote": {
+            "post": {
+                "description": "Returns an itemised price quote for a stay. Amounts are integers in minor units of the returned currency, e.g. cents for USD. Stays can be at most 365 nights. A stay the property's stay rules do not allow is refused with stay_not_allowed, listing each broken rule in errors, as when booking it",
+                "tags": [
+                    "Property Owner"
+                ],
+                "summary": "Quote a stay",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID",
+                        "name": "propertyid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Quote Request",
+                        "name": "Quote",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateQuote"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Quote"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid dates, more guests than the property sleeps, or a stay the stay rules do not allow"
+                    },
+                    "404": {
+                        "description": "property not found"
+                    }
+                }
+            }
+        },
+        "/property/{propertyid}/reviews": {
+            "get": {
+                "description": "Lists guests' reviews of a property with the host's replies, newest first. Pass next_cursor back as cursor for the next page",
+                "tags": [
+                    "Reviews"
+                ],
+                "summary": "Property Reviews",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID",
+                        "name": "propertyid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size, 1-100",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.GetReviews"
+                        }
+                    },
+                    "404": {
+                        "description": "property not found"
+                    }
+                }
+            }
+        },
+        "/user/booking": {
+            "get": {
+                "description": "A User gets his list of bookings",
+                "tags": [
+                    "Bookings"
+                ],
+                "summary": "Get Bookings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.UserGetBooking"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/user/booking/{bookingid}": {
+            "get": {
+                "description": "A User gets a booking",
+                "tags": [
+                    "Bookings"
+                ],
+                "summary": "Get Bookings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID",
+                        "name": "bookingid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserGetBooking"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/booking/{propertyid}": {
+            "post": {
+                "description": "A User Books a property or apartment for a date range. Dates use the YYYY-MM-DD format and stays can be at most 365 nights. The quoted total is frozen on the booking in minor currency units. A stay the property's stay rules do not allow is refused with stay_not_allowed, listing each broken rule in errors",
+                "tags": [
+                    "Bookings"
+                ],
+                "summary": "Book Property",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID",
+                        "name": "propertyid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Create Booking Request",
+                        "name": "Booking",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateBooking"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "successfully booked"
+                    },
+                    "400": {
+                        "description": "invalid dates, or the stay breaks the property's stay rules",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "property not found"
+                    },
+                    "409": {
+                        "description": "property is already booked for the selected dates"
+                    }
+                }
+            }
+        },
+        "/user/conversations": {
+            "post": {
+                "description": "Sends a message to a property's host, opening a conversation the first time. Pass booking_id to talk about one of your bookings of the property; otherwise the property must be listed",
+                "tags": [
+                    "Messages"
+                ],
+                "summary": "Message Host",
+                "parameters": [
+                    {
+                        "description": "Start Conversation Request",
+                        "name": "Message",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.StartConversation"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.GetConversation"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "hosts cannot start a conversation about their own property"
+                    },
+                    "404": {
+                        "description": "property or booking not found"
+                    }
+                }
+            }
+        },
+        "/user/review/{bookingid}": {
+            "post": {
+                "description": "A guest rates a stay from 1 to 5 and describes it. Allowed once per booking, after a confirmed stay's check-out date",
+                "tags": [
+                    "Reviews"
+                ],
+                "summary": "Review Property",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID",
+                        "name": "bookingid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Create Review Request",
+                        "name": "Review",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateReview"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.GetReview"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "booking not found"
+                    },
+                    "409": {
+                        "description": "reviews can be left once a confirmed stay has checked out"
+                    }
+                }
+            }
+        },
+        "/user/reviews": {
+            "get": {
+                "description": "A guest lists what hosts have said about them, newest first",
+                "tags": [
+                    "Reviews"
+                ],
+                "summary": "My Reviews",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size, 1-100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.GetReviews"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/wishlists": {
+            "get": {
+                "description": "A guest lists their wishlists, newest first",
+                "tags": [
+                    "Wishlists"
+                ],
+                "summary": "List Wishlists",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.WishlistSummary"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "A guest creates a named list to save properties to",
+                "tags": [
+                    "Wishlists"
+                ],
+                "summary": "Create Wishlist",
+                "parameters": [
+                    {
+                        "description": "Create Wishlist Request",
+                        "name": "Wishlist",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateWishlist"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WishlistSummary"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/wishlists/{wishlistid}": {
+            "get": {
+                "description": "A guest gets one of their wishlists. Given check_in and check_out, each property shows its current price for the stay and whether it is free",
+                "tags": [
+                    "Wishlists"
+                ],
+                "summary": "Get Wishlist",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID",
+                        "name": "wishlistid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "2025-01-10",
+                        "description": "Check-in date",
+                        "name": "check_in",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2025-01-15",
+                        "description": "Check-out date",
+                        "name": "check_out",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of guests (default 1)",
+                        "name": "guests",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.GetWishlist"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "wishlist not found"
+                    }
+                }
+            },
+            "delete": {
+                "description": "A guest deletes one of their wishlists. Its share link stops working",
+                "tags": [
+                    "Wishlists"
+                ],
+                "summary": "Delete Wishlist",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID",
+                        "name": "wishlistid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "wishlist deleted"
+                    },
+                    "404": {
+                        "description": "wishlist not found"
+                    }
+                }
+            }
+        },
+        "/user/wishlists/{wishlistid}/properties/{propertyid}": {
+            "put": {
+                "description": "A guest saves a property to one of their wishlists. Saving it twice has no effect",
+                "tags": [
+                    "Wishlists"
+                ],
+                "summary": "Save Property",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID",
+                        "name": "wishlistid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID",
+                        "name": "propertyid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "property saved"
+                    },
+                    "404": {
+                        "description": "wishlist or property not found"
+                    },
+                    "409": {
+                        "description": "a wishlist can hold up to 100 properties"
+                    }
+                }
+            },
+            "delete": {
+                "description": "A guest takes a property off one of their wishlists",
+                "tags": [
+                    "Wishlists"
+                ],
+                "summary": "Remove Property",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID",
+                        "name": "wishlistid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID",
+                        "name": "propertyid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "property removed"
+                    },
+                    "404": {
+                        "description": "wishlist not found"
+                    }
+                }
+            }
+        },
+        "/user/wishlists/{wishlistid}/share": {
+            "post": {
+                "description": "A guest gets a read-only link to one of their wishlists. The link is only shown once; sharing again replaces it, and the old link stops working",
+                "tags": [
+                    "Wishlists"
+                ],
+                "summary": "Share Wishlist",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID",
+                        "name": "wishlistid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WishlistShare"
+                        }
+                    },
+                    "404": {
+                        "description": "wishlist not found"
+                    }
+                }
+            },
+            "delete": {
+                "description": "A guest turns off a wishlist's share link",
+                "tags": [
+                    "Wishlists"
+                ],
+                "summary": "Stop Sharing Wishlist",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID",
+                        "name": "wishlistid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cAdd access token here\u003e",
+                        "description": "Insert your access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "wishlist no longer shared"
+                    },
+                    "404": {
+                        "description": "wishlist not found"
+                    }
+                }
+            }
+        },
+        "/wishlists/shared/{token}": {
+            "get": {
+                "description": "Anyone with a wishlist's share link can read it, with prices and availability as in Get Wishlist",
+                "tags": [
+                    "Wishlists"
+                ],
+                "summary": "Shared Wishlist",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token from the share link",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "2025-01-10",
+                        "description": "Check-in date",
+                        "name": "check_in",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2025-01-15",
+                        "description": "Check-out date",
+                        "name": "check_out",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of guests (default 1)",
+                        "name": "guests",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.GetWishlist"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "wishlist not found"
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "models.AddRole": {
+            "type": "object",
+            "required": [
+                "role"
+            ],
+            "properties": {
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "guest",
+                        "host"
+                    ],
+                    "example": "host"
+                }
+            }
+        },
+        "models.Address": {
+            "type": "object",
+            "required": [
+                "city",
+                "country"
+            ],
+            "properties": {
+                "city": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "country": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "postal_code": {
+                    "type": "string",
+                    "maxLength": 20
+                },
+                "region": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "street": {
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
+        },
+        "models.AdminAccount": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "email_verified_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "suspended_at": {
+                    "type": "string"
+                },
+                "suspension_reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.AdminAccounts": {
+            "type": "object",
+            "properties": {
+                "accounts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.AdminAccount"
+                    }
+                },
+                "next_cursor": {
+                    "description": "NextCursor fetches the next page when passed back as cursor. It is\nempty on the last page.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.AdminAction": {
+            "type": "object",
+            "required": [
+                "reason"
+            ],
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "maxLength": 500,
+                    "example": "repeated spam reports"
+                }
+            }
+        },
+        "models.AuditLog": {
+            "type": "object",
+            "properties": {
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.GetAuditEntry"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.AuthTokens": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "refresh_expires_at": {
+                    "type": "string"
+                },
+                "refresh_token": {
+                    "type": "string"
+                },
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.BlockDates": {
+            "type": "object",
+            "required": [
+                "end_date",
+                "start_date"
+            ],
+            "properties": {
+                "end_date": {
+                    "type": "string",
+                    "example": "2025-02-05"
+                },
+                "reason": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "maintenance"
+                },
+                "start_date": {
+                    "type": "string",
+                    "example": "2025-02-01"
+                }
+            }
+        },
+        "models.CancelBooking": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "maxLength": 500,
+                    "example": "change of plans"
+                }
+            }
+        },
+        "models.CancelledBooking": {
+            "type": "object",
+            "properties": {
+                "booking_id": {
+                    "type": "string"
+                },
+                "cancellation_reason": {
+                    "type": "string"
+                },
+                "cancelled_by_id": {
+                    "type": "string"
+                },
+                "cancelled_by_role": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "refund_amount": {
+                    "type": "integer"
+                },
+                "total_price": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ChangePassword": {
+            "type": "object",
+            "required": [
+                "current_password",
+                "new_password"
+            ],
+            "properties": {
+                "current_password": {
+                    "type": "string"
+                },
+                "new_password": {
+                    "type": "string",
+                    "maxLength": 72
+                }
+            }
+        },
+        "models.CreateAccount": {
+            "type": "object",
+            "required": [
+                "email",
+                "name",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "password": {
+                    "type": "string",
+                    "maxLength": 72
+                },
+                "roles": {
+                    "description": "Roles defaults to guest. Sign up with host to list properties.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "guest",
+                        "host"
+                    ]
+                }
+            }
+        },
+        "models.CreateAmenity": {
+            "type": "object",
+            "required": [
+                "key",
+                "name"
+            ],
+            "properties": {
+                "key": {
+                    "type": "string",
+                    "maxLength": 50,
+                    "example": "hot_tub"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "Hot tub"
+                }
+            }
+        },
+        "models.CreateBooking": {
+            "type": "object",
+            "required": [
+                "check_in",
+                "check_out"
+            ],
+            "properties": {
+                "check_in": {
+                    "type": "string",
+                    "example": "2025-01-10"
+                },
+                "check_out": {
+                    "type": "string",
+                    "example": "2025-01-15"
+                },
+                "guests": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 2
+                }
+            }
+        },
+        "models.CreatePriceRule": {
+            "type": "object",
+            "required": [
+                "kind"
+            ],
+            "properties": {
+                "adjustment": {
+                    "type": "integer",
+                    "example": 2000
+                },
+                "end_date": {
+                    "type": "string",
+                    "example": "2026-01-03"
+                },
+                "kind": {
+                    "type": "string",
+                    "enum": [
+                        "date_range",
+                        "weekday"
+                    ],
+                    "example": "weekday"
+                },
+                "price": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 25000
+                },
+                "start_date": {
+                    "type": "string",
+                    "example": "2025-12-20"
+                },
+                "weekdays": {
+                    "description": "Weekdays are three-letter names, e.g. [\"fri\",\"sat\"].",
+                    "type": "array",
+                    "maxItems": 7,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "fri",
+                        "sat"
+                    ]
+                }
+            }
+        },
+        "models.CreateProperty": {
+            "type": "object",
+            "required": [
+                "property_name"
+            ],
+            "properties": {
+                "address": {
+                    "$ref": "#/definitions/models.Address"
+                },
+                "amenities": {
+                    "description": "Amenities are keys from the catalogue at GET /amenities.",
+                    "type": "array",
+                    "maxItems": 50,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "wifi",
+                        "kitchen"
+                    ]
+                },
+                "bathrooms": {
+                    "type": "number",
+                    "maximum": 50,
+                    "minimum": 0,
+                    "example": 1.5
+                },
+                "bedrooms": {
+                    "type": "integer",
+                    "maximum": 50,
+                    "minimum": 0,
+                    "example": 2
+                },
+                "beds": {
+                    "description": "Beds defaults to 1 and Bathrooms to 1, which may end in .5.",
+                    "type": "integer",
+                    "maximum": 100,
+                    "minimum": 0,
+                    "example": 3
+                },
+                "cancellation_policy": {
+                    "description": "CancellationPolicy is one of flexible (default), moderate or strict.",
+                    "type": "string",
+                    "enum": [
+                        "flexible",
+                        "moderate",
+                        "strict"
+                    ],
+                    "example": "moderate"
+                },
+                "check_in_time": {
+                    "description": "CheckInTime and CheckOutTime default to 15:00 and 11:00.",
+                    "type": "string",
+                    "example": "15:00"
+                },
+                "check_out_time": {
+                    "type": "string",
+                    "example": "11:00"
+                },
+                "cleaning_fee": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 3000
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "description": {
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "house_rules": {
+                    "$ref": "#/definitions/models.HouseRules"
+                },
+                "latitude": {
+                    "type": "number",
+                    "maximum": 90,
+                    "minimum": -90,
+                    "example": 6.4281
+                },
+                "longitude": {
+                    "type": "number",
+                    "maximum": 180,
+                    "minimum": -180,
+                    "example": 3.4219
+                },
+                "max_guests": {
+                    "description": "MaxGuests defaults to 1.",
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 4
+                },
+                "monthly_discount": {
+                    "type": "integer",
+                    "maximum": 9000,
+                    "minimum": 0,
+                    "example": 2000
+                },
+                "price": {
+                    "type": "integer",
+                    "example": 12550
+                },
+                "property_name": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "property_type": {
+                    "description": "PropertyType is one of entire_home (default), private_room or shared_room.",
+                    "type": "string",
+                    "enum": [
+                        "entire_home",
+                        "private_room",
+                        "shared_room"
+                    ],
+                    "example": "entire_home"
+                },
+                "stay_rules": {
+                    "description": "StayRules default to stays of at least one night, bookable from today\nwith no other limits.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.StayRules"
+                        }
+                    ]
+                },
+                "weekly_discount": {
+                    "description": "WeeklyDiscount and MonthlyDiscount come off stays of 7 and 28 nights or\nmore, in basis points.",
+                    "type": "integer",
+                    "maximum": 9000,
+                    "minimum": 0,
+                    "example": 1000
+                }
+            }
+        },
+        "models.CreateQuote": {
+            "type": "object",
+            "required": [
+                "check_in",
+                "check_out"
+            ],
+            "properties": {
+                "check_in": {
+                    "type": "string",
+                    "example": "2025-01-10"
+                },
+                "check_out": {
+                    "type": "string",
+                    "example": "2025-01-15"
+                },
+                "guests": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 2
+                }
+            }
+        },
+        "models.CreateReview": {
+            "type": "object",
+            "required": [
+                "comment",
+                "rating"
+            ],
+            "properties": {
+                "comment": {
+                    "type": "string",
+                    "maxLength": 2000,
+                    "example": "Spotless and quiet, would stay again"
+                },
+                "rating": {
+                    "type": "integer",
+                    "maximum": 5,
+                    "minimum": 1,
+                    "example": 5
+                }
+            }
+        },
+        "models.CreateWishlist": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "Summer by the lake"
+                }
+            }
+        },
+        "models.DeleteAccount": {
+            "type": "object",
+            "required": [
+                "password"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.FieldError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "field": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ForgotPasswordRequest": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.GetAllProperties": {
+            "type": "object",
+            "properties": {
+                "next_cursor": {
+                    "description": "NextCursor fetches the next page when passed back as cursor. It is\nempty on the last page.",
+                    "type": "string"
+                },
+                "properties": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.GetProperty"
+                    }
+                }
+            }
+        },
+        "models.GetAmenity": {
+            "type": "object",
+            "properties": {
+                "key": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.GetAuditEntry": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "actor_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "entry_id": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "target_id": {
+                    "type": "string"
+                },
+                "target_type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.GetConversation": {
+            "type": "object",
+            "properties": {
+                "booking_id": {
+                    "type": "string"
+                },
+                "conversation_id": {
+                    "type": "string"
+                },
+                "guest": {
+                    "$ref": "#/definitions/models.Participant"
+                },
+                "host": {
+                    "$ref": "#/definitions/models.Participant"
+                },
+                "last_message_at": {
+                    "type": "string"
+                },
+                "property_id": {
+                    "type": "string"
+                },
+                "property_name": {
+                    "type": "string"
+                },
+                "unread_count": {
+                    "description": "UnreadCount is how many of the other participant's messages the\nsigned-in account has not read.",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.GetMessage": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "message_id": {
+                    "type": "string"
+                },
+                "read": {
+                    "description": "Read reports whether the recipient has read the message.",
+                    "type": "boolean"
+                },
+                "sender_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.GetMessages": {
+            "type": "object",
+            "properties": {
+                "messages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.GetMessage"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.GetNearbyProperties": {
+            "type": "object",
+            "properties": {
+                "properties": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.GetNearbyProperty"
+                    }
+                }
+            }
+        },
+        "models.GetNearbyProperty": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "$ref": "#/definitions/models.Address"
+                },
+                "amenities": {
+                    "description": "Amenities are sorted by name.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.GetAmenity"
+                    }
+                },
+                "bathrooms": {
+                    "type": "number"
+                },
+                "bedrooms": {
+                    "type": "integer"
+                },
+                "beds": {
+                    "type": "integer"
+                },
+                "cancellation_policy": {
+                    "type": "string"
+                },
+                "check_in_time": {
+                    "type": "string"
+                },
+                "check_out_time": {
+                    "type": "string"
+                },
+                "cleaning_fee": {
+                    "type": "integer"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "distance_km": {
+                    "type": "number"
+                },
+                "house_rules": {
+                    "$ref": "#/definitions/models.HouseRules"
+                },
+                "latitude": {
+                    "type": "number"
+                },
+                "longitude": {
+                    "type": "number"
+                },
+                "max_guests": {
+                    "type": "integer"
+                },
+                "monthly_discount": {
+                    "type": "integer"
+                },
+                "photos": {
+                    "description": "Photos are in display order; the first is the cover.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.GetPhoto"
+                    }
+                },
+                "price": {
+                    "type": "integer"
+                },
+                "property_id": {
+                    "type": "string"
+                },
+                "property_name": {
+                    "type": "string"
+                },
+                "property_owner": {
+                    "$ref": "#/definitions/models.GetPropertyOwner"
+                },
+                "property_type": {
+                    "type": "string"
+                },
+                "rating_average": {
+                    "description": "RatingAverage is the mean of guests' 1-5 ratings over RatingCount\nreviews, 0 until the first review.",
+                    "type": "number"
+                },
+                "rating_count": {
+                    "type": "integer"
+                },
+                "stay_rules": {
+                    "$ref": "#/definitions/models.StayRules"
+                },
+                "unlisted_at": {
+                    "description": "UnlistedAt is set when an admin has hidden the property from guests.",
+                    "type": "string"
+                },
+                "weekly_discount": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.GetPhoto": {
+            "type": "object",
+            "properties": {
+                "height": {
+                    "type": "integer"
+                },
+                "is_cover": {
+                    "type": "boolean"
+                },
+                "photo_id": {
+                    "type": "string"
+                },
+                "urls": {
+                    "description": "URLs maps each size name to where that copy can be downloaded.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "width": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.GetPhotos": {
+            "type": "object",
+            "properties": {
+                "photos": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.GetPhoto"
+                    }
+                }
+            }
+        },
+        "models.GetPriceRule": {
+            "type": "object",
+            "properties": {
+                "adjustment": {
+                    "type": "integer"
+                },
+                "end_date": {
+                    "type": "string"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "integer"
+                },
+                "rule_id": {
+                    "type": "string"
+                },
+                "start_date": {
+                    "type": "string"
+                },
+                "weekdays": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.GetPricing": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "type": "string"
+                },
+                "monthly_discount": {
+                    "type": "integer"
+                },
+                "price": {
+                    "type": "integer"
+                },
+                "rules": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.GetPriceRule"
+                    }
+                },
+                "weekly_discount": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.GetProperty": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "$ref": "#/definitions/models.Address"
+                },
+                "amenities": {
+                    "description": "Amenities are sorted by name.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.GetAmenity"
+                    }
+                },
+                "bathrooms": {
+                    "type": "number"
+                },
+                "bedrooms": {
+                    "type": "integer"
+                },
+                "beds": {
+                    "type": "integer"
+                },
+                "cancellation_policy": {
+                    "type": "string"
+                },
+                "check_in_time": {
+                    "type": "string"
+                },
+                "check_out_time": {
+                    "type": "string"
+                },
+                "cleaning_fee": {
+                    "type": "integer"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "house_rules": {
+                    "$ref": "#/definitions/models.HouseRules"
+                },
+                "latitude": {
+                    "type": "number"
+                },
+                "longitude": {
+                    "type": "number"
+                },
+                "max_guests": {
+                    "type": "integer"
+                },
+                "monthly_discount": {
+                    "type": "integer"
+                },
+                "photos": {
+                    "description": "Photos are in display order; the first is the cover.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.GetPhoto"
+                    }
+                },
+                "price": {
+                    "type": "integer"
+                },
+                "property_id": {
+                    "type": "string"
+                },
+                "property_name": {
+                    "type": "string"
+                },
+                "property_owner": {
+                    "$ref": "#/definitions/models.GetPropertyOwner"
+                },
+                "property_type": {
+                    "type": "string"
+                },
+                "rating_average": {
+                    "description": "RatingAverage is the mean of guests' 1-5 ratings over RatingCount\nreviews, 0 until the first review.",
+                    "type": "number"
+                },
+                "rating_count": {
+                    "type": "integer"
+                },
+                "stay_rules": {
+                    "$ref": "#/definitions/models.StayRules"
+                },
+                "unlisted_at": {
+                    "description": "UnlistedAt is set when an admin has hidden the property from guests.",
+                    "type": "string"
+                },
+                "weekly_discount": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.GetPropertyOwner": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "owner_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.GetReview": {
+            "type": "object",
+            "properties": {
+                "author_name": {
+                    "type": "string"
+                },
+                "author_role": {
+                    "type": "string"
+                },
+                "booking_id": {
+                    "type": "string"
+                },
+                "comment": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "property_id": {
+                    "type": "string"
+                },
+                "rating": {
+                    "type": "integer"
+                },
+                "replied_at": {
+                    "type": "string"
+                },
+                "reply": {
+                    "type": "string"
+                },
+                "review_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.GetReviews": {
+            "type": "object",
+            "properties": {
+                "next_cursor": {
+                    "description": "NextCursor fetches the next page when passed back as cursor. It is\nempty on the last page.",
+                    "type": "string"
+                },
+                "reviews": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.GetReview"
+                    }
+                }
+            }
+        },
+        "models.GetWishlist": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "properties": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.WishlistProperty"
+                    }
+                },
+                "shared": {
+                    "type": "boolean"
+                },
+                "wishlist_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.HouseRules": {
+            "type": "object",
+            "properties": {
+                "events_allowed": {
+                    "type": "boolean"
+                },
+                "pets_allowed": {
+                    "type": "boolean"
+                },
+                "quiet_hours_end": {
+                    "type": "string",
+                    "example": "07:00"
+                },
+                "quiet_hours_start": {
+                    "type": "string",
+                    "example": "22:00"
+                },
+                "smoking_allowed": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.Inbox": {
+            "type": "object",
+            "properties": {
+                "conversations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.GetConversation"
+                    }
+                },
+                "next_cursor": {
+                    "description": "NextCursor fetches the next page when passed back as cursor. It is\nempty on the last page.",
+                    "type": "string"
+                },
+                "unread_count": {
+                    "description": "UnreadCount totals unread messages across every conversation, not\nonly this page.",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.JWK": {
+            "type": "object",
+            "properties": {
+                "alg": {
+                    "type": "string"
+                },
+                "crv": {
+                    "type": "string"
+                },
+                "e": {
+                    "type": "string"
+                },
+                "kid": {
+                    "type": "string"
+                },
+                "kty": {
+                    "type": "string"
+                },
+                "n": {
+                    "type": "string"
+                },
+                "use": {
+                    "type": "string"
+                },
+                "x": {
+                    "type": "string"
                 }
             }
         },
-        "/user/signup": {
-            "post": {
-                "description": "A User signups",
-                "tags": [
-                    "User"
-                ],
-                "summary": "SignUp user",
-                "parameters": [
-                    {
-                        "description": "Create User Request",
-                        "name": "CreateUser",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.CreateUser"
-                        }
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "user created successfully"
+        "models.JWKS": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.JWK"
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "models.CreateProperty": {
+        },
+        "models.LoginAccount": {
             "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
             "properties": {
-                "description": {
+                "email": {
                     "type": "string"
                 },
-                "price": {
-                    "type": "integer"
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.NightAvailability": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string"
                 },
-                "property_name": {
+                "reason": {
+                    "description": "Reason is set on unbookable nights: past, advance_notice,\nbooking_window, min_nights or check_in_day.",
+                    "type": "string"
+                },
+                "status": {
                     "type": "string"
                 }
             }
         },
-        "models.CreatePropertyOwner": {
+        "models.Participant": {
             "type": "object",
             "properties": {
-                "email": {
+                "account_id": {
                     "type": "string"
                 },
                 "name": {
                     "type": "string"
                 },
-                "password": {
+                "read_at": {
                     "type": "string"
                 }
             }
         },
-        "models.CreateUser": {
+        "models.Problem": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "property_not_found"
+                },
+                "detail": {
+                    "type": "string",
+                    "example": "property not found"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FieldError"
+                    }
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 404
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Not Found"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "about:blank"
+                }
+            }
+        },
+        "models.Profile": {
             "type": "object",
             "properties": {
+                "account_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
                 "email": {
                     "type": "string"
                 },
+                "email_verified": {
+                    "type": "boolean"
+                },
                 "name": {
                     "type": "string"
                 },
-                "password": {
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.PropertyAvailability": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "string"
+                },
+                "nights": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.NightAvailability"
+                    }
+                },
+                "property_id": {
+                    "type": "string"
+                },
+                "to": {
                     "type": "string"
                 }
             }
         },
-        "models.GetProperty": {
+        "models.PropertyBooking": {
             "type": "object",
             "properties": {
-                "description": {
+                "booking_id": {
                     "type": "string"
                 },
-                "price": {
+                "check_in": {
+                    "type": "string"
+                },
+                "check_out": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "guests": {
                     "type": "integer"
                 },
                 "property_id": {
                     "type": "string"
                 },
-                "property_name": {
+                "status": {
                     "type": "string"
                 },
-                "property_owner": {
-                    "$ref": "#/definitions/models.GetPropertyOwner"
+                "total_price": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "string"
                 }
             }
         },
-        "models.GetPropertyOwner": {
+        "models.Quote": {
             "type": "object",
             "properties": {
-                "email": {
+                "check_in": {
                     "type": "string"
                 },
-                "name": {
+                "check_out": {
                     "type": "string"
                 },
-                "owner_id": {
+                "currency": {
+                    "type": "string"
+                },
+                "guests": {
+                    "type": "integer"
+                },
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.QuoteLine"
+                    }
+                },
+                "nightly_price": {
+                    "description": "NightlyPrice is the property's base price; NightlyPrices has each\nnight's price after the owner's price rules.",
+                    "type": "integer"
+                },
+                "nightly_prices": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.QuoteNight"
+                    }
+                },
+                "nights": {
+                    "type": "integer"
+                },
+                "property_id": {
                     "type": "string"
+                },
+                "total": {
+                    "type": "integer"
                 }
             }
         },
-        "models.LoginPropertyOwner": {
+        "models.QuoteLine": {
             "type": "object",
             "properties": {
-                "email": {
+                "amount": {
+                    "type": "integer"
+                },
+                "code": {
                     "type": "string"
                 },
-                "password": {
+                "description": {
                     "type": "string"
                 }
             }
         },
-        "models.LoginUser": {
+        "models.QuoteNight": {
             "type": "object",
             "properties": {
-                "email": {
+                "date": {
                     "type": "string"
                 },
+                "price": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.RefreshTokenRequest": {
+            "type": "object",
+            "required": [
+                "refresh_token"
+            ],
+            "properties": {
+                "refresh_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ReorderPhotos": {
+            "type": "object",
+            "required": [
+                "photo_ids"
+            ],
+            "properties": {
+                "photo_ids": {
+                    "type": "array",
+                    "maxItems": 30,
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.ReplyToReview": {
+            "type": "object",
+            "required": [
+                "reply"
+            ],
+            "properties": {
+                "reply": {
+                    "type": "string",
+                    "maxLength": 2000,
+                    "example": "Thank you, come back soon!"
+                }
+            }
+        },
+        "models.ResetPasswordRequest": {
+            "type": "object",
+            "required": [
+                "password",
+                "token"
+            ],
+            "properties": {
                 "password": {
+                    "type": "string",
+                    "maxLength": 72
+                },
+                "token": {
                     "type": "string"
                 }
             }
         },
-        "models.PropertyBooking": {
+        "models.SendMessage": {
+            "type": "object",
+            "required": [
+                "body"
+            ],
+            "properties": {
+                "body": {
+                    "type": "string",
+                    "maxLength": 2000,
+                    "example": "We will arrive around 6pm"
+                }
+            }
+        },
+        "models.StartConversation": {
             "type": "object",
+            "required": [
+                "body",
+                "property_id"
+            ],
             "properties": {
+                "body": {
+                    "type": "string",
+                    "maxLength": 2000,
+                    "example": "Is the cabin suitable for a toddler?"
+                },
                 "booking_id": {
                     "type": "string"
                 },
                 "property_id": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "3fa85f64-5717-4562-b3fc-2c963f66afa6"
+                }
+            }
+        },
+        "models.StayRules": {
+            "type": "object",
+            "properties": {
+                "advance_notice_days": {
+                    "type": "integer",
+                    "maximum": 365,
+                    "minimum": 0,
+                    "example": 1
                 },
-                "status": {
-                    "type": "string"
+                "booking_window_days": {
+                    "type": "integer",
+                    "maximum": 730,
+                    "minimum": 0,
+                    "example": 180
                 },
-                "user_id": {
-                    "type": "string"
+                "check_in_days": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "fri",
+                        "sat"
+                    ]
+                },
+                "max_nights": {
+                    "type": "integer",
+                    "maximum": 365,
+                    "minimum": 0,
+                    "example": 28
+                },
+                "min_nights": {
+                    "type": "integer",
+                    "maximum": 365,
+                    "minimum": 0,
+                    "example": 2
+                }
+            }
+        },
+        "models.UpdateAmenity": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "Hot tub"
+                }
+            }
+        },
+        "models.UpdateProfile": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 1
+                }
+            }
+        },
+        "models.UpdateProperty": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "description": "Address replaces the whole address when present.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Address"
+                        }
+                    ]
+                },
+                "amenities": {
+                    "description": "Amenities replaces the whole list when present; [] removes them all.",
+                    "type": "array",
+                    "maxItems": 50,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "wifi",
+                        "pool"
+                    ]
+                },
+                "bathrooms": {
+                    "type": "number",
+                    "maximum": 50,
+                    "minimum": 0,
+                    "example": 1.5
+                },
+                "bedrooms": {
+                    "type": "integer",
+                    "maximum": 50,
+                    "minimum": 0,
+                    "example": 2
+                },
+                "beds": {
+                    "type": "integer",
+                    "maximum": 100,
+                    "minimum": 1,
+                    "example": 3
+                },
+                "cancellation_policy": {
+                    "type": "string",
+                    "enum": [
+                        "flexible",
+                        "moderate",
+                        "strict"
+                    ],
+                    "example": "moderate"
+                },
+                "check_in_time": {
+                    "type": "string",
+                    "example": "14:00"
+                },
+                "check_out_time": {
+                    "type": "string",
+                    "example": "10:00"
+                },
+                "cleaning_fee": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 3000
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "description": {
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "house_rules": {
+                    "description": "HouseRules replaces all the rules when present.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.HouseRules"
+                        }
+                    ]
+                },
+                "latitude": {
+                    "type": "number",
+                    "maximum": 90,
+                    "minimum": -90,
+                    "example": 6.4281
+                },
+                "longitude": {
+                    "type": "number",
+                    "maximum": 180,
+                    "minimum": -180,
+                    "example": 3.4219
+                },
+                "max_guests": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 4
+                },
+                "monthly_discount": {
+                    "type": "integer",
+                    "maximum": 9000,
+                    "minimum": 0,
+                    "example": 2000
+                },
+                "price": {
+                    "type": "integer",
+                    "example": 12550
+                },
+                "property_name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 1
+                },
+                "property_type": {
+                    "description": "Listing details.",
+                    "type": "string",
+                    "enum": [
+                        "entire_home",
+                        "private_room",
+                        "shared_room"
+                    ],
+                    "example": "private_room"
+                },
+                "stay_rules": {
+                    "description": "StayRules replaces all the rules when present. Bookings already made\nare kept.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.StayRules"
+                        }
+                    ]
+                },
+                "weekly_discount": {
+                    "type": "integer",
+                    "maximum": 9000,
+                    "minimum": 0,
+                    "example": 1000
                 }
             }
         },
@@ -559,6 +4880,18 @@ const docTemplate = `{
                 "booking_id": {
                     "type": "string"
                 },
+                "check_in": {
+                    "type": "string"
+                },
+                "check_out": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "guests": {
+                    "type": "integer"
+                },
                 "property_id": {
                     "type": "string"
                 },
@@ -567,6 +4900,58 @@ const docTemplate = `{
                 },
                 "status": {
                     "type": "string"
+                },
+                "total_price": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.WishlistProperty": {
+            "type": "object",
+            "properties": {
+                "available": {
+                    "description": "Available and Quote are only set when the request gives dates.\nAvailable is false when the stay overlaps a booking or blocked dates,\nbreaks the property's stay rules, or there are more guests than the\nproperty fits.",
+                    "type": "boolean"
+                },
+                "property": {
+                    "$ref": "#/definitions/models.GetProperty"
+                },
+                "quote": {
+                    "$ref": "#/definitions/models.Quote"
+                },
+                "saved_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.WishlistShare": {
+            "type": "object",
+            "properties": {
+                "share_url": {
+                    "type": "string"
+                },
+                "wishlist_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.WishlistSummary": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "property_count": {
+                    "type": "integer"
+                },
+                "shared": {
+                    "type": "boolean"
+                },
+                "wishlist_id": {
+                    "type": "string"
                 }
             }
         }
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"airbnb/mailer"
+	"airbnb/middleware"
+	"airbnb/models"
+	"airbnb/repository"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// AccountHandlers handles sign up, sign in and the account's tokens. BaseURL
+// is the public address of the API, used to build links in emails.
+type AccountHandlers struct {
+	DbRepo  *repository.AccountRepo
+	Tokens  *repository.TokenRepo
+	Auth    *middleware.Authenticator
+	Mailer  mailer.Mailer
+	BaseURL string
+}
+
+func NewAccountHandlers(repo *repository.AccountRepo, tokens *repository.TokenRepo, auth *middleware.Authenticator, mail mailer.Mailer, baseURL string) *AccountHandlers {
+	return &AccountHandlers{
+		DbRepo:  repo,
+		Tokens:  tokens,
+		Auth:    auth,
+		Mailer:  mail,
+		BaseURL: baseURL,
+	}
+}
+
+// @Tags		   Account
+// @Summary		   SignUp
+// @Description    Creates an account with the guest role, the host role or both. Roles defaults to guest. A verification link is emailed to the account, which must be opened before it can book
+// @Success        200 {object} models.AuthTokens
+// @Failure        400 {object} models.Problem
+// @Failure        409 "an account with this email already exists"
+// @Param          CreateAccount body models.CreateAccount true "Create Account Request"
+// @Router         /account/signup [post]
+func (h *AccountHandlers) CreateAccount(ctx *gin.Context) {
+	var req models.CreateAccount
+	if !bindJSON(ctx, &req) {
+		return
+	}
+	if len(req.Roles) == 0 {
+		req.Roles = []string{models.GuestRole}
+	}
+	account := models.Account{Name: req.Name, Email: req.Email}
+	for _, role := range req.Roles {
+		if !account.HasRole(role) {
+			account.Roles = append(account.Roles, models.Role{Name: role})
+		}
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	account.Password = string(hashedPassword)
+
+	if err := h.DbRepo.CreateAccount(ctx, &account); err != nil {
+		ctx.Error(err)
+		return
+	}
+	// The account exists either way; a failed email can be sent again from
+	// /auth/verify-email/resend.
+	if err := h.sendVerificationEmail(ctx, &account); err != nil {
+		log.Println("unable to send verification email", err)
+	}
+
+	h.signIn(ctx, &account, "account created successfully, check your email to verify it")
+}
+
+// @Tags		   Account
+// @Summary		   Signin
+// @Description    Guests, hosts and admins all sign in here. The access token carries the account's roles and expires after 15 minutes; use the refresh token to get a new one
+// @Success        200 {object} models.AuthTokens
+// @Failure        401 "invalid email or password"
+// @Failure        403 "account is suspended"
+// @Param          LoginAccount body models.LoginAccount true "Login Request"
+// @Router         /account/login [post]
+func (h *AccountHandlers) LoginAccount(ctx *gin.Context) {
+	var req models.LoginAccount
+	if !bindJSON(ctx, &req) {
+		return
+	}
+	account, err := h.DbRepo.GetAccountByEmail(ctx, req.Email)
+	if err != nil {
+		if errors.Is(err, repository.ErrAccountNotFound) {
+			err = errInvalidCredentials
+		}
+		ctx.Error(err)
+		return
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(account.Password), []byte(req.Password)); err != nil {
+		ctx.Error(errInvalidCredentials)
+		return
+	}
+	if account.IsSuspended() {
+		ctx.Error(repository.ErrAccountSuspended)
+		return
+	}
+
+	h.signIn(ctx, account, "login successful")
+}
+
+// @Tags		   Account
+// @Summary		   Add Role
+// @Description    Gives the signed-in account the guest or host role, e.g. a guest who starts hosting. Refresh the access token to use the new role
+// @Success        200 "role added"
+// @Failure        400 {object} models.Problem
+// @Param          AddRole body models.AddRole true "Add Role Request"
+// @Router         /account/roles [post]
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+func (h *AccountHandlers) AddRole(ctx *gin.Context) {
+	var req models.AddRole
+	if !bindJSON(ctx, &req) {
+		return
+	}
+	account, err := middleware.GetAccount(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	if err := h.DbRepo.AddRole(ctx, account, req.Role); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "role added",
+		"roles":   account.RoleNames(),
+	})
+}
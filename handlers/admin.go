@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"airbnb/middleware"
+	"airbnb/models"
+	"airbnb/repository"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// AdminHandlers lets admins moderate accounts, bookings and properties. Every
+// action is written to the audit log with the reason given for it.
+type AdminHandlers struct {
+	Accounts   *repository.AccountRepo
+	Bookings   *repository.BookingRepo
+	Properties *repository.PropertyRepo
+	Audit      *repository.AuditRepo
+	Denylist   *middleware.Denylist
+}
+
+func NewAdminHandlers(accounts *repository.AccountRepo, bookings *repository.BookingRepo, properties *repository.PropertyRepo, audit *repository.AuditRepo, denylist *middleware.Denylist) *AdminHandlers {
+	return &AdminHandlers{
+		Accounts:   accounts,
+		Bookings:   bookings,
+		Properties: properties,
+		Audit:      audit,
+		Denylist:   denylist,
+	}
+}
+
+// @Tags		   Admin
+// @Summary		   List Accounts
+// @Description    Lists guests, hosts and admins, newest first. q matches name or email; filter by role and by status (active or suspended). Pass next_cursor back as cursor for the next page
+// @Success        200 {object} models.AdminAccounts
+// @Failure        400 {object} models.Problem
+// @Param          q query string false "Name or email contains"
+// @Param          role query string false "guest, host or admin"
+// @Param          status query string false "active or suspended"
+// @Param          cursor query string false "Cursor from the previous page"
+// @Param          limit query int false "Page size, 1-100" default(20)
+// @Router         /admin/accounts [get]
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+func (h *AdminHandlers) ListAccounts(ctx *gin.Context) {
+	var search models.AccountSearch
+	if !bindQuery(ctx, &search) {
+		return
+	}
+	if search.Limit == 0 {
+		search.Limit = models.DefaultPageSize
+	}
+	accounts, next, err := h.Accounts.SearchAccounts(ctx, search)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	response := models.AdminAccounts{Accounts: []models.AdminAccount{}, NextCursor: next}
+	for _, account := range accounts {
+		response.Accounts = append(response.Accounts, models.NewAdminAccount(&account))
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+// @Tags		   Admin
+// @Summary		   Get Account
+// @Description    Gets an account, including whether and why it is suspended
+// @Success        200 {object} models.AdminAccount
+// @Failure        404 "account not found"
+// @Param          accountid path string true "ID"
+// @Router         /admin/accounts/{accountid} [get]
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+func (h *AdminHandlers) GetAccount(ctx *gin.Context) {
+	accountID, err := uuid.Parse(ctx.Param("accountid"))
+	if err != nil {
+		ctx.Error(errInvalidAccountID)
+		return
+	}
+	account, err := h.Accounts.GetAccountByID(ctx, accountID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, models.NewAdminAccount(account))
+}
+
+// @Tags		   Admin
+// @Summary		   Suspend Account
+// @Description    Suspends an account and signs it out of every session. A suspended account cannot sign in or use its tokens until it is reinstated
+// @Success        200 "account suspended"
+// @Failure        403 "admins cannot suspend their own account"
+// @Failure        404 "account not found"
+// @Failure        409 "account is already suspended"
+// @Param          accountid path string true "ID"
+// @Param          Action body models.AdminAction true "Reason"
+// @Router         /admin/accounts/{accountid}/suspend [post]
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+func (h *AdminHandlers) SuspendAccount(ctx *gin.Context) {
+	h.act(ctx, "accountid", errInvalidAccountID, "account suspended", func(accountID, adminID uuid.UUID, reason string) error {
+		revoked, err := h.Accounts.SuspendAccount(ctx, accountID, adminID, reason, time.Now())
+		h.Denylist.Add(revoked...)
+		return err
+	})
+}
+
+// @Tags		   Admin
+// @Summary		   Reinstate Account
+// @Description    Lifts an account's suspension. Its holder signs in again to get new tokens
+// @Success        200 "account reinstated"
+// @Failure        404 "account not found"
+// @Failure        409 "account is not suspended"
+// @Param          accountid path string true "ID"
+// @Param          Action body models.AdminAction true "Reason"
+// @Router         /admin/accounts/{accountid}/reinstate [post]
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+func (h *AdminHandlers) ReinstateAccount(ctx *gin.Context) {
+	h.act(ctx, "accountid", errInvalidAccountID, "account reinstated", func(accountID, adminID uuid.UUID, reason string) error {
+		return h.Accounts.ReinstateAccount(ctx, accountID, adminID, reason)
+	})
+}
+
+// @Tags		   Admin
+// @Summary		   Cancel Booking
+// @Description    Cancels any pending or confirmed booking with a full refund to the guest, whatever the property's cancellation policy
+// @Success        200 {object} models.CancelledBooking
+// @Failure        404 "booking not found"
+// @Failure        409 "booking can no longer be cancelled"
+// @Param          bookingid path string true "ID"
+// @Param          Action body models.AdminAction true "Reason"
+// @Router         /admin/bookings/{bookingid}/cancel [post]
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+func (h *AdminHandlers) CancelBooking(ctx *gin.Context) {
+	bookingID, err := uuid.Parse(ctx.Param("bookingid"))
+	if err != nil {
+		ctx.Error(errInvalidBookingID)
+		return
+	}
+	var req models.AdminAction
+	if !bindJSON(ctx, &req) {
+		return
+	}
+	admin, err := middleware.GetAccount(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	booking, err := h.Bookings.ForceCancelBooking(ctx, bookingID, admin.ID, req.Reason, time.Now())
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.CancelledBooking{
+		Message:            "booking cancelled",
+		BookingID:          booking.ID,
+		Currency:           booking.Currency,
+		TotalPrice:         booking.TotalPrice,
+		RefundAmount:       booking.RefundAmount,
+		CancellationReason: booking.CancellationReason,
+		CancelledByID:      *booking.CancelledByID,
+		CancelledByRole:    booking.CancelledByRole,
+	})
+}
+
+// @Tags		   Admin
+// @Summary		   Unlist Property
+// @Description    Hides a property from search, quotes and new bookings. Its owner still sees it; existing bookings are not cancelled
+// @Success        200 "property unlisted"
+// @Failure        404 "property not found"
+// @Failure        409 "property is already unlisted"
+// @Param          propertyid path string true "ID"
+// @Param          Action body models.AdminAction true "Reason"
+// @Router         /admin/properties/{propertyid}/unlist [post]
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+func (h *AdminHandlers) UnlistProperty(ctx *gin.Context) {
+	h.act(ctx, "propertyid", middleware.ErrInvalidPropertyID, "property unlisted", func(propertyID, adminID uuid.UUID, reason string) error {
+		return h.Properties.UnlistProperty(ctx, propertyID, adminID, reason, time.Now())
+	})
+}
+
+// @Tags		   Admin
+// @Summary		   Relist Property
+// @Description    Makes an unlisted property visible and bookable again
+// @Success        200 "property relisted"
+// @Failure        404 "property not found"
+// @Failure        409 "property is not unlisted"
+// @Param          propertyid path string true "ID"
+// @Param          Action body models.AdminAction true "Reason"
+// @Router         /admin/properties/{propertyid}/relist [post]
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+func (h *AdminHandlers) RelistProperty(ctx *gin.Context) {
+	h.act(ctx, "propertyid", middleware.ErrInvalidPropertyID, "property relisted", func(propertyID, adminID uuid.UUID, reason string) error {
+		return h.Properties.RelistProperty(ctx, propertyID, adminID, reason)
+	})
+}
+
+// act parses the ID in the param route parameter, reporting invalidID if it is
+// malformed, and the action's body, then applies the action as the signed-in
+// admin and responds with message.
+func (h *AdminHandlers) act(ctx *gin.Context, param string, invalidID error, message string, apply func(id, adminID uuid.UUID, reason string) error) {
+	id, err := uuid.Parse(ctx.Param(param))
+	if err != nil {
+		ctx.Error(invalidID)
+		return
+	}
+	var req models.AdminAction
+	if !bindJSON(ctx, &req) {
+		return
+	}
+	admin, err := middleware.GetAccount(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	if err := apply(id, admin.ID, req.Reason); err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": message, "id": id})
+}
+
+// @Tags		   Admin
+// @Summary		   Audit Log
+// @Description    Lists admin actions, newest first, optionally filtered by the admin who took them, what they acted on or the action, e.g. account.suspend
+// @Success        200 {object} models.AuditLog
+// @Failure        400 {object} models.Problem
+// @Param          actor_id query string false "Admin account ID"
+// @Param          target_id query string false "Account, booking or property ID"
+// @Param          action query string false "Action"
+// @Param          cursor query string false "Cursor from the previous page"
+// @Param          limit query int false "Page size, 1-100" default(20)
+// @Router         /admin/audit [get]
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+func (h *AdminHandlers) GetAuditLog(ctx *gin.Context) {
+	var search models.AuditSearch
+	if !bindQuery(ctx, &search) {
+		return
+	}
+	if search.Limit == 0 {
+		search.Limit = models.DefaultPageSize
+	}
+	entries, next, err := h.Audit.ListAuditEntries(ctx, search)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	response := models.AuditLog{Entries: []models.GetAuditEntry{}, NextCursor: next}
+	for _, entry := range entries {
+		response.Entries = append(response.Entries, models.GetAuditEntry{
+			EntryID:    entry.ID,
+			ActorID:    entry.ActorID,
+			Action:     entry.Action,
+			TargetType: entry.TargetType,
+			TargetID:   entry.TargetID,
+			Reason:     entry.Reason,
+			CreatedAt:  entry.CreatedAt,
+		})
+	}
+	ctx.JSON(http.StatusOK, response)
+}
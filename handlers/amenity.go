@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"airbnb/models"
+	"airbnb/repository"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AmenityHandlers serves the amenities catalogue, which admins manage and
+// property owners pick from.
+type AmenityHandlers struct {
+	DbRepo *repository.AmenityRepo
+}
+
+func NewAmenityHandlers(repo *repository.AmenityRepo) *AmenityHandlers {
+	return &AmenityHandlers{DbRepo: repo}
+}
+
+// @Tags		   Property
+// @Summary		   List Amenities
+// @Description    Lists the amenities a property can have, sorted by name. Their keys are used in a property's amenities and to filter the listing
+// @Success        200 {array} models.GetAmenity
+// @Router         /amenities [get]
+func (h *AmenityHandlers) ListAmenities(ctx *gin.Context) {
+	amenities, err := h.DbRepo.ListAmenities(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, models.NewGetAmenities(amenities))
+}
+
+// @Tags		   Admin
+// @Summary		   Create Amenity
+// @Description    Adds an amenity to the catalogue. Its key, made of lowercase letters, digits and underscores, cannot be changed later
+// @Success        200 {object} models.GetAmenity
+// @Failure        400 {object} models.Problem
+// @Failure        409 "an amenity with this key already exists"
+// @Param          Amenity body models.CreateAmenity true "Create Amenity Request"
+// @Router         /admin/amenities [post]
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+func (h *AmenityHandlers) CreateAmenity(ctx *gin.Context) {
+	var req models.CreateAmenity
+	if !bindJSON(ctx, &req) {
+		return
+	}
+	if err := models.ValidateAmenityKey(req.Key); err != nil {
+		ctx.Error(invalidInput("invalid_amenity_key", err))
+		return
+	}
+	amenity := models.Amenity{Key: req.Key, Name: req.Name}
+	if err := h.DbRepo.CreateAmenity(ctx, &amenity); err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, models.GetAmenity{Key: amenity.Key, Name: amenity.Name})
+}
+
+// @Tags		   Admin
+// @Summary		   Rename Amenity
+// @Description    Changes the name shown for an amenity
+// @Success        200 {object} models.GetAmenity
+// @Failure        400 {object} models.Problem
+// @Failure        404 "amenity not found"
+// @Param          key path string true "Key"
+// @Param          Amenity body models.UpdateAmenity true "Update Amenity Request"
+// @Router         /admin/amenities/{key} [patch]
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+func (h *AmenityHandlers) UpdateAmenity(ctx *gin.Context) {
+	var req models.UpdateAmenity
+	if !bindJSON(ctx, &req) {
+		return
+	}
+	amenity, err := h.DbRepo.RenameAmenity(ctx, ctx.Param("key"), req.Name)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, models.GetAmenity{Key: amenity.Key, Name: amenity.Name})
+}
+
+// @Tags		   Admin
+// @Summary		   Delete Amenity
+// @Description    Removes an amenity from the catalogue and from every property that has it
+// @Success        200 "amenity deleted"
+// @Failure        404 "amenity not found"
+// @Param          key path string true "Key"
+// @Router         /admin/amenities/{key} [delete]
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+func (h *AmenityHandlers) DeleteAmenity(ctx *gin.Context) {
+	if err := h.DbRepo.DeleteAmenity(ctx, ctx.Param("key")); err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "amenity deleted"})
+}
@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"airbnb/middleware"
+	"airbnb/models"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// newTokens signs an access token for account and pairs it with a new refresh
+// token. The returned row still needs its family before it is stored.
+func (h *AccountHandlers) newTokens(account *models.Account, message string) (*models.AuthTokens, *models.RefreshToken, error) {
+	accessToken, claims, err := h.Auth.GenerateAccessToken(account)
+	if err != nil {
+		return nil, nil, err
+	}
+	refreshToken, hash, err := middleware.GenerateToken()
+	if err != nil {
+		return nil, nil, err
+	}
+	accessExpiresAt := time.Unix(claims.ExpiresAt, 0).UTC()
+	refreshExpiresAt := time.Now().Add(models.RefreshTokenTTL).UTC()
+	row := &models.RefreshToken{
+		AccountID:       account.ID,
+		TokenHash:       hash,
+		AccessJTI:       claims.Id,
+		AccessExpiresAt: accessExpiresAt,
+		ExpiresAt:       refreshExpiresAt,
+	}
+	return &models.AuthTokens{
+		Message:          message,
+		AccountID:        account.ID,
+		Roles:            account.RoleNames(),
+		Token:            accessToken,
+		ExpiresAt:        accessExpiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: refreshExpiresAt,
+	}, row, nil
+}
+
+// signIn starts a new refresh token family for account and writes the tokens.
+func (h *AccountHandlers) signIn(ctx *gin.Context, account *models.Account, message string) {
+	tokens, row, err := h.newTokens(account, message)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	row.FamilyID = uuid.New()
+	if err := h.Tokens.CreateRefreshToken(ctx, row); err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, tokens)
+}
+
+// @Tags		   Auth
+// @Summary		   Refresh Tokens
+// @Description    Exchanges a refresh token for a new access token and refresh token. Each refresh token works once; presenting a used one signs out every session that descends from it
+// @Success        200 {object} models.AuthTokens
+// @Failure        401 "refresh token is invalid or expired"
+// @Failure        403 "account is suspended"
+// @Param          Refresh body models.RefreshTokenRequest true "Refresh Request"
+// @Router         /auth/refresh [post]
+func (h *AccountHandlers) RefreshTokens(ctx *gin.Context) {
+	var req models.RefreshTokenRequest
+	if !bindJSON(ctx, &req) {
+		return
+	}
+	var tokens *models.AuthTokens
+	revoked, err := h.Tokens.RotateRefreshToken(ctx, middleware.HashToken(req.RefreshToken), time.Now(),
+		func(account *models.Account) (*models.RefreshToken, error) {
+			var row *models.RefreshToken
+			var err error
+			tokens, row, err = h.newTokens(account, "token refreshed")
+			return row, err
+		})
+	h.Auth.Denylist.Add(revoked...)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, tokens)
+}
+
+// @Tags		   Auth
+// @Summary		   Logout
+// @Description    Signs out the session the refresh token belongs to and revokes the access token used for the request
+// @Success        200 "logged out"
+// @Failure        400 "refresh token is invalid or expired"
+// @Param          Logout body models.RefreshTokenRequest true "Logout Request"
+// @Router         /auth/logout [post]
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+func (h *AccountHandlers) Logout(ctx *gin.Context) {
+	var req models.RefreshTokenRequest
+	if !bindJSON(ctx, &req) {
+		return
+	}
+	account, err := middleware.GetAccount(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	if !h.revokeCurrentToken(ctx) {
+		return
+	}
+	revoked, err := h.Tokens.RevokeRefreshToken(ctx, account.ID, middleware.HashToken(req.RefreshToken), time.Now())
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	h.Auth.Denylist.Add(revoked...)
+	ctx.JSON(http.StatusOK, gin.H{"message": "logged out"})
+}
+
+// @Tags		   Auth
+// @Summary		   Logout Everywhere
+// @Description    Signs the account out of every session by revoking all of its refresh tokens and the access tokens issued with them
+// @Success        200 "logged out of all sessions"
+// @Router         /auth/logout-all [post]
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+func (h *AccountHandlers) LogoutAll(ctx *gin.Context) {
+	account, err := middleware.GetAccount(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	if !h.revokeCurrentToken(ctx) {
+		return
+	}
+	revoked, err := h.Tokens.RevokeAccountTokens(ctx, account.ID, time.Now())
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	h.Auth.Denylist.Add(revoked...)
+	ctx.JSON(http.StatusOK, gin.H{"message": "logged out of all sessions"})
+}
+
+// revokeCurrentToken denylists the access token the request was made with. On
+// failure it writes the error response and returns false.
+func (h *AccountHandlers) revokeCurrentToken(ctx *gin.Context) bool {
+	claims, err := middleware.GetClaims(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return false
+	}
+	token := models.RevokedToken{JTI: claims.Id, ExpiresAt: time.Unix(claims.ExpiresAt, 0).UTC()}
+	if err := h.Tokens.DenyAccessToken(ctx, token); err != nil {
+		ctx.Error(err)
+		return false
+	}
+	h.Auth.Denylist.Add(token)
+	return true
+}
+
+// @Tags		   Auth
+// @Summary		   Signing Keys
+// @Description    Publishes the public keys access tokens are signed with, as a JSON Web Key Set. Match a token's kid header to a key to verify it
+// @Success        200 {object} models.JWKS
+// @Router         /.well-known/jwks.json [get]
+func (h *AccountHandlers) JWKS(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "public, max-age=60")
+	ctx.JSON(http.StatusOK, h.Auth.Keys.JWKS())
+}
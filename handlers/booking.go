@@ -4,7 +4,10 @@ import (
 	"airbnb/middleware"
 	"airbnb/models"
 	"airbnb/repository"
+	"errors"
+	"io"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -22,33 +25,56 @@ func NewBookingHandlers(repo *repository.BookingRepo) *BookingHandlers {
 
 // @Tags		   Bookings
 // @Summary		   Book Property
-// @Description    A User Books a property or apartment
+// @Description    A User Books a property or apartment for a date range. Dates use the YYYY-MM-DD format and stays can be at most 365 nights. The quoted total is frozen on the booking in minor currency units. A stay the property's stay rules do not allow is refused with stay_not_allowed, listing each broken rule in errors
 // @Success        200   "successfully booked"
+// @Failure        400   {object} models.Problem "invalid dates, or the stay breaks the property's stay rules"
+// @Failure        404   "property not found"
+// @Failure        409   "property is already booked for the selected dates"
 // @Param           propertyid path string true "ID"
+// @Param          Booking body models.CreateBooking true "Create Booking Request"
 // @Router         /user/booking/{propertyid} [post]
 // @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
 func (h *BookingHandlers) CreateBooking(ctx *gin.Context) {
 	idParam := ctx.Param("propertyid")
 	propertyID, err := uuid.Parse(idParam)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid property ID"})
+		ctx.Error(middleware.ErrInvalidPropertyID)
 		return
 	}
-	user, err := middleware.GetUser(ctx)
+	var req models.CreateBooking
+	if !bindJSON(ctx, &req) {
+		return
+	}
+	now := time.Now()
+	checkIn, checkOut, err := models.ParseDateRange(req.CheckIn, req.CheckOut, now)
+	if err != nil {
+		ctx.Error(invalidInput("invalid_dates", err))
+		return
+	}
+	user, err := middleware.GetAccount(ctx)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
-	err = h.DbRepo.CreateBooking(ctx, &models.Booking{
+	booking := models.Booking{
 		UserID:     user.ID,
 		PropertyID: propertyID,
+		CheckIn:    checkIn,
+		CheckOut:   checkOut,
+		Guests:     req.Guests,
 		Status:     models.Pending,
-	})
+	}
+	err = h.DbRepo.CreateBooking(ctx, &booking, now)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
-	ctx.JSON(http.StatusOK, gin.H{"message": "successfully booked"})
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":     "successfully booked",
+		"booking_id":  booking.ID,
+		"currency":    booking.Currency,
+		"total_price": booking.TotalPrice,
+	})
 }
 
 // @Tags		   Bookings
@@ -58,15 +84,15 @@ func (h *BookingHandlers) CreateBooking(ctx *gin.Context) {
 // @Router         /user/booking [get]
 // @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
 func (h *BookingHandlers) GetUserBookings(ctx *gin.Context) {
-	user, err := middleware.GetUser(ctx)
+	user, err := middleware.GetAccount(ctx)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
 	bookings, err := h.DbRepo.GetUserBookings(ctx, user.ID)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -84,17 +110,17 @@ func (h *BookingHandlers) GetUserBookingByID(ctx *gin.Context) {
 	bookingIDParam := ctx.Param("bookingid")
 	bookingID, err := uuid.Parse(bookingIDParam)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid booking ID"})
+		ctx.Error(errInvalidBookingID)
 		return
 	}
-	_, err = middleware.GetUser(ctx)
+	user, err := middleware.GetAccount(ctx)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
-	booking, err := h.DbRepo.GetUserBookingByID(ctx, bookingID)
+	booking, err := h.DbRepo.GetUserBookingByID(ctx, user.ID, bookingID)
 	if err != nil {
-		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -102,25 +128,62 @@ func (h *BookingHandlers) GetUserBookingByID(ctx *gin.Context) {
 }
 
 // @Tags		   Bookings
-// @Summary		   Confirm Bookings
-// @Description    A Property owner or a user can cancel a booking
-// @Success        200 "booking confirmed"
+// @Summary		   Cancel Bookings
+// @Description    The guest or the owning host cancels a pending or confirmed booking. The refund follows the property's cancellation policy when the guest cancels a confirmed booking, and is the full total otherwise
+// @Success        200 {object} models.CancelledBooking
+// @Failure        404 "booking not found"
+// @Failure        409 "booking cannot be cancelled from its current status"
 // @Param          bookingid path string true "ID"
+// @Param          Cancel body models.CancelBooking false "Cancel Booking Request"
 // @Router         /cancel/booking/{bookingid} [delete]
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
 func (h *BookingHandlers) CancelBooking(ctx *gin.Context) {
 	idParam := ctx.Param("bookingid")
 	bookingID, err := uuid.Parse(idParam)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid booking ID"})
+		ctx.Error(errInvalidBookingID)
+		return
+	}
+	var req models.CancelBooking
+	// The body is optional.
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeBindError(ctx, err)
+		return
+	}
+	account, err := middleware.GetAccount(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	// An account can be both a guest and a host, so whether it cancels as one or
+	// the other depends on its part in this booking.
+	role, err := h.DbRepo.BookingRole(ctx, bookingID, account.ID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	actor, err := middleware.GetActor(ctx, role)
+	if err != nil {
+		ctx.Error(repository.ErrBookingNotFound)
 		return
 	}
 
-	if err := h.DbRepo.CancelBooking(ctx, bookingID); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	booking, err := h.DbRepo.CancelBooking(ctx, bookingID, actor, req.Reason, time.Now())
+	if err != nil {
+		ctx.Error(err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"message": "booking cancelled"})
+	ctx.JSON(http.StatusOK, models.CancelledBooking{
+		Message:            "booking cancelled",
+		BookingID:          booking.ID,
+		Currency:           booking.Currency,
+		TotalPrice:         booking.TotalPrice,
+		RefundAmount:       booking.RefundAmount,
+		CancellationReason: booking.CancellationReason,
+		CancelledByID:      *booking.CancelledByID,
+		CancelledByRole:    booking.CancelledByRole,
+	})
 }
 
 // @Tags		   Bookings
@@ -130,15 +193,15 @@ func (h *BookingHandlers) CancelBooking(ctx *gin.Context) {
 // @Router         /owner/booking/all [get]
 // @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
 func (h *BookingHandlers) GetPropertyBookings(ctx *gin.Context) {
-	owner, err := middleware.GetPropertyOwner(ctx)
+	owner, err := middleware.GetAccount(ctx)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
 	bookings, err := h.DbRepo.GetPropertyBookings(ctx, owner.ID)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -156,13 +219,18 @@ func (h *BookingHandlers) GetPropertyBookingByID(ctx *gin.Context) {
 	bookingIDParam := ctx.Param("bookingid")
 	bookingID, err := uuid.Parse(bookingIDParam)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid property ID"})
+		ctx.Error(errInvalidBookingID)
+		return
+	}
+	owner, err := middleware.GetAccount(ctx)
+	if err != nil {
+		ctx.Error(err)
 		return
 	}
 
-	booking, err := h.DbRepo.GetPropertyBookingByID(ctx, bookingID)
+	booking, err := h.DbRepo.GetPropertyBookingByID(ctx, owner.ID, bookingID)
 	if err != nil {
-		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -171,28 +239,249 @@ func (h *BookingHandlers) GetPropertyBookingByID(ctx *gin.Context) {
 
 // @Tags		   Bookings
 // @Summary		   Confirm Bookings
-// @Description    A Property owner confirms a booking
+// @Description    A Property owner confirms a pending booking
 // @Success        200 "booking confirmed"
+// @Failure        404 "booking not found"
+// @Failure        409 "booking cannot be confirmed from its current status"
 // @Param          bookingid path string true "ID"
 // @Router         /owner/booking/{bookingid} [put]
 // @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
 func (h *BookingHandlers) ConfirmBooking(ctx *gin.Context) {
+	h.ownerTransition(ctx, models.Confirmed, "booking confirmed")
+}
+
+// @Tags		   Bookings
+// @Summary		   Decline Bookings
+// @Description    A Property owner declines a pending booking
+// @Success        200 "booking declined"
+// @Failure        404 "booking not found"
+// @Failure        409 "booking cannot be declined from its current status"
+// @Param          bookingid path string true "ID"
+// @Router         /owner/booking/{bookingid}/decline [put]
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+func (h *BookingHandlers) DeclineBooking(ctx *gin.Context) {
+	h.ownerTransition(ctx, models.Declined, "booking declined")
+}
+
+// @Tags		   Bookings
+// @Summary		   Check In Bookings
+// @Description    A Property owner marks a confirmed booking's guest as checked in
+// @Success        200 "guest checked in"
+// @Failure        404 "booking not found"
+// @Failure        409 "booking cannot be checked in from its current status"
+// @Param          bookingid path string true "ID"
+// @Router         /owner/booking/{bookingid}/checkin [put]
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+func (h *BookingHandlers) CheckInBooking(ctx *gin.Context) {
+	h.ownerTransition(ctx, models.CheckedIn, "guest checked in")
+}
+
+// @Tags		   Bookings
+// @Summary		   Complete Bookings
+// @Description    A Property owner marks a checked-in booking as completed
+// @Success        200 "booking completed"
+// @Failure        404 "booking not found"
+// @Failure        409 "booking cannot be completed from its current status"
+// @Param          bookingid path string true "ID"
+// @Router         /owner/booking/{bookingid}/complete [put]
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+func (h *BookingHandlers) CompleteBooking(ctx *gin.Context) {
+	h.ownerTransition(ctx, models.Completed, "booking completed")
+}
+
+func (h *BookingHandlers) ownerTransition(ctx *gin.Context, to, message string) {
 	idParam := ctx.Param("bookingid")
 	bookingID, err := uuid.Parse(idParam)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid booking ID"})
+		ctx.Error(errInvalidBookingID)
+		return
+	}
+	owner, err := middleware.GetAccount(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	_, err = h.DbRepo.TransitionBooking(ctx, bookingID, to, models.NewActor(owner.ID, models.HostRole))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": message})
+}
+
+// @Tags		   Bookings
+// @Summary		   Property Availability
+// @Description    Returns a per-night calendar for a property. Nights run from the from date up to, but not including, the to date. Dates use the YYYY-MM-DD format. Free nights no stay allowed by the property's stay rules can cover are unbookable, with the reason: past, advance_notice, booking_window, min_nights or check_in_day
+// @Success        200 {object} models.PropertyAvailability
+// @Failure        400 "invalid dates"
+// @Failure        404 "property not found"
+// @Param          propertyid path string true "ID"
+// @Param          from query string true "First night" example(2025-01-01)
+// @Param          to query string true "Night after the last night" example(2025-02-01)
+// @Router         /property/{propertyid}/availability [get]
+func (h *BookingHandlers) GetAvailability(ctx *gin.Context) {
+	idParam := ctx.Param("propertyid")
+	propertyID, err := uuid.Parse(idParam)
+	if err != nil {
+		ctx.Error(middleware.ErrInvalidPropertyID)
+		return
+	}
+	from, to, err := models.ParseDates(ctx.Query("from"), ctx.Query("to"))
+	if err != nil {
+		ctx.Error(invalidInput("invalid_dates", err))
+		return
+	}
+	if to.Sub(from) > models.MaxCalendarNights*24*time.Hour {
+		ctx.Error(invalidInput("invalid_dates", models.ErrCalendarRangeLimit))
+		return
+	}
+
+	rules, err := h.DbRepo.GetStayRules(ctx, propertyID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	// Whether a night can be booked depends on the free nights around it.
+	padding := rules.CalendarPadding()
+	first, last := from.AddDate(0, 0, -padding), to.AddDate(0, 0, padding)
+	bookings, blocks, err := h.DbRepo.GetAvailability(ctx, propertyID, first, last)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	calendar := buildCalendar(first, last, bookings, blocks)
+	markUnbookable(calendar, first, rules, time.Now())
+	ctx.JSON(http.StatusOK, models.PropertyAvailability{
+		PropertyID: propertyID,
+		From:       from.Format(models.DateLayout),
+		To:         to.Format(models.DateLayout),
+		Nights:     calendar[padding : len(calendar)-padding],
+	})
+}
+
+// markUnbookable marks the available nights of calendar, which starts on
+// first, that rules put out of reach of stays booked at now.
+func markUnbookable(calendar []models.NightAvailability, first time.Time, rules models.StayRules, now time.Time) {
+	taken := make([]bool, len(calendar))
+	for i, night := range calendar {
+		taken[i] = night.Status != models.NightAvailable
+	}
+	for i, reason := range rules.Unbookable(first, taken, now) {
+		if reason != "" {
+			calendar[i].Status, calendar[i].Reason = models.NightUnbookable, reason
+		}
+	}
+}
+
+// buildCalendar marks every night in [from, to) as available, booked or
+// blocked. Owner blocks take precedence over bookings.
+func buildCalendar(from, to time.Time, bookings []models.Booking, blocks []models.BlockedDate) []models.NightAvailability {
+	nights := []models.NightAvailability{}
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		status := models.NightAvailable
+		for _, booking := range bookings {
+			if !day.Before(booking.CheckIn) && day.Before(booking.CheckOut) {
+				status = models.NightBooked
+				break
+			}
+		}
+		for _, block := range blocks {
+			if !day.Before(block.StartDate) && day.Before(block.EndDate) {
+				status = models.NightBlocked
+				break
+			}
+		}
+		nights = append(nights, models.NightAvailability{
+			Date:   day.Format(models.DateLayout),
+			Status: status,
+		})
+	}
+	return nights
+}
+
+// @Tags		   Bookings
+// @Summary		   Block Dates
+// @Description    A Property owner blocks a range of nights, e.g. for maintenance. The end date is the first night that is available again
+// @Success        200 "dates blocked"
+// @Failure        400 "invalid dates"
+// @Failure        404 "property not found"
+// @Failure        409 "property is already booked for the selected dates"
+// @Param          propertyid path string true "ID"
+// @Param          Block body models.BlockDates true "Block Dates Request"
+// @Router         /property/{propertyid}/block [post]
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+func (h *BookingHandlers) BlockDates(ctx *gin.Context) {
+	idParam := ctx.Param("propertyid")
+	propertyID, err := uuid.Parse(idParam)
+	if err != nil {
+		ctx.Error(middleware.ErrInvalidPropertyID)
+		return
+	}
+	var req models.BlockDates
+	if !bindJSON(ctx, &req) {
+		return
+	}
+	start, end, err := models.ParseDates(req.StartDate, req.EndDate)
+	if err != nil {
+		ctx.Error(invalidInput("invalid_dates", err))
+		return
+	}
+	owner, err := middleware.GetAccount(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	block := models.BlockedDate{
+		PropertyID: propertyID,
+		StartDate:  start,
+		EndDate:    end,
+		Reason:     req.Reason,
+	}
+	if err := h.DbRepo.BlockDates(ctx, owner.ID, &block); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":  "dates blocked",
+		"block_id": block.ID,
+	})
+}
+
+// @Tags		   Bookings
+// @Summary		   Unblock Dates
+// @Description    A Property owner removes a blocked date range
+// @Success        200 "dates unblocked"
+// @Failure        404 "blocked date range not found"
+// @Param          propertyid path string true "ID"
+// @Param          blockid path string true "Block ID"
+// @Router         /property/{propertyid}/block/{blockid} [delete]
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+func (h *BookingHandlers) UnblockDates(ctx *gin.Context) {
+	propertyID, err := uuid.Parse(ctx.Param("propertyid"))
+	if err != nil {
+		ctx.Error(middleware.ErrInvalidPropertyID)
+		return
+	}
+	blockID, err := uuid.Parse(ctx.Param("blockid"))
+	if err != nil {
+		ctx.Error(errInvalidBlockID)
 		return
 	}
-	_, err = middleware.GetPropertyOwner(ctx)
+	owner, err := middleware.GetAccount(ctx)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
-	if err := h.DbRepo.ConfirmBooking(ctx, bookingID); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.DbRepo.UnblockDates(ctx, owner.ID, propertyID, blockID); err != nil {
+		ctx.Error(err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"message": "booking confirmed"})
+	ctx.JSON(http.StatusOK, gin.H{"message": "dates unblocked"})
 }
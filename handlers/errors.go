@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"airbnb/middleware"
+	"net/http"
+)
+
+var (
+	errInvalidBookingID      = middleware.NewError(http.StatusBadRequest, "invalid_booking_id", "invalid booking ID")
+	errInvalidBlockID        = middleware.NewError(http.StatusBadRequest, "invalid_block_id", "invalid block ID")
+	errInvalidAccountID      = middleware.NewError(http.StatusBadRequest, "invalid_account_id", "invalid account ID")
+	errInvalidReviewID       = middleware.NewError(http.StatusBadRequest, "invalid_review_id", "invalid review ID")
+	errInvalidConversationID = middleware.NewError(http.StatusBadRequest, "invalid_conversation_id", "invalid conversation ID")
+	errInvalidWishlistID     = middleware.NewError(http.StatusBadRequest, "invalid_wishlist_id", "invalid wishlist ID")
+	errInvalidPhotoID        = middleware.NewError(http.StatusBadRequest, "invalid_photo_id", "invalid photo ID")
+	errInvalidPriceRuleID    = middleware.NewError(http.StatusBadRequest, "invalid_price_rule_id", "invalid price rule ID")
+
+	errInvalidCredentials = middleware.NewError(http.StatusUnauthorized, "invalid_credentials", "invalid email or password")
+	errWrongPassword      = middleware.NewError(http.StatusUnauthorized, "wrong_password", "current password is incorrect")
+	errAlreadyVerified    = middleware.NewError(http.StatusConflict, "email_already_verified", "email is already verified")
+	errMissingToken       = middleware.NewError(http.StatusBadRequest, "missing_token", "token is required")
+
+	errMissingPhoto     = middleware.NewError(http.StatusBadRequest, "missing_photo", "send the photo as a multipart form file named photo")
+	errPhotoTooLarge    = middleware.NewError(http.StatusRequestEntityTooLarge, "photo_too_large", "photo must be at most 10 MiB")
+	errUnsupportedPhoto = middleware.NewError(http.StatusUnsupportedMediaType, "unsupported_photo_type", "photo must be a JPEG or PNG image")
+	errMediaNotFound    = middleware.NewError(http.StatusNotFound, "media_not_found", "file not found")
+)
+
+// invalidInput reports err, a problem with the request that binding tags
+// cannot express, as a 400 with the given code.
+func invalidInput(code string, err error) error {
+	return middleware.NewError(http.StatusBadRequest, code, err.Error())
+}
@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"airbnb/middleware"
+	"airbnb/models"
+	"airbnb/repository"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type MessageHandlers struct {
+	DbRepo *repository.MessageRepo
+}
+
+func NewMessageHandlers(repo *repository.MessageRepo) *MessageHandlers {
+	return &MessageHandlers{
+		DbRepo: repo,
+	}
+}
+
+// @Tags		   Messages
+// @Summary		   Message Host
+// @Description    Sends a message to a property's host, opening a conversation the first time. Pass booking_id to talk about one of your bookings of the property; otherwise the property must be listed
+// @Success        200 {object} models.GetConversation
+// @Failure        400 {object} models.Problem
+// @Failure        403 "hosts cannot start a conversation about their own property"
+// @Failure        404 "property or booking not found"
+// @Param          Message body models.StartConversation true "Start Conversation Request"
+// @Router         /user/conversations [post]
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+func (h *MessageHandlers) StartConversation(ctx *gin.Context) {
+	var req models.StartConversation
+	if !bindJSON(ctx, &req) {
+		return
+	}
+	guest, err := middleware.GetAccount(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	propertyID, err := uuid.Parse(req.PropertyID)
+	if err != nil {
+		ctx.Error(middleware.ErrInvalidPropertyID)
+		return
+	}
+	var bookingID *uuid.UUID
+	if req.BookingID != "" {
+		id, err := uuid.Parse(req.BookingID)
+		if err != nil {
+			ctx.Error(errInvalidBookingID)
+			return
+		}
+		bookingID = &id
+	}
+
+	conversation, err := h.DbRepo.StartConversation(ctx, guest.ID, propertyID, bookingID, req.Body, time.Now())
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, models.NewGetConversation(conversation, 0))
+}
+
+// @Tags		   Messages
+// @Summary		   Inbox
+// @Description    Lists the conversations the account takes part in as a guest or a host, most recently active first, with unread counts. Pass next_cursor back as cursor for the next page
+// @Success        200 {object} models.Inbox
+// @Failure        400 {object} models.Problem
+// @Param          cursor query string false "Cursor from the previous page"
+// @Param          limit query int false "Page size, 1-100" default(20)
+// @Router         /inbox [get]
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+func (h *MessageHandlers) GetInbox(ctx *gin.Context) {
+	var search models.InboxSearch
+	if !bindQuery(ctx, &search) {
+		return
+	}
+	if search.Limit == 0 {
+		search.Limit = models.DefaultPageSize
+	}
+	account, err := middleware.GetAccount(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	conversations, unread, next, err := h.DbRepo.GetInbox(ctx, account.ID, search)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	total, err := h.DbRepo.CountUnread(ctx, account.ID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	response := models.Inbox{Conversations: []models.GetConversation{}, UnreadCount: total, NextCursor: next}
+	for _, conversation := range conversations {
+		response.Conversations = append(response.Conversations, models.NewGetConversation(&conversation, unread[conversation.ID]))
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+// @Tags		   Messages
+// @Summary		   Get Messages
+// @Description    Lists a conversation's messages, newest first, each marked read once the recipient has read it. Listing does not mark messages read; see Mark Read
+// @Success        200 {object} models.GetMessages
+// @Failure        400 {object} models.Problem
+// @Failure        404 "conversation not found"
+// @Param          conversationid path string true "ID"
+// @Param          cursor query string false "Cursor from the previous page"
+// @Param          limit query int false "Page size, 1-100" default(20)
+// @Router         /conversations/{conversationid}/messages [get]
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+func (h *MessageHandlers) GetMessages(ctx *gin.Context) {
+	conversationID, err := uuid.Parse(ctx.Param("conversationid"))
+	if err != nil {
+		ctx.Error(errInvalidConversationID)
+		return
+	}
+	var search models.MessageSearch
+	if !bindQuery(ctx, &search) {
+		return
+	}
+	if search.Limit == 0 {
+		search.Limit = models.DefaultPageSize
+	}
+	account, err := middleware.GetAccount(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	messages, conversation, next, err := h.DbRepo.GetMessages(ctx, account.ID, conversationID, search)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	response := models.GetMessages{Messages: []models.GetMessage{}, NextCursor: next}
+	for _, message := range messages {
+		response.Messages = append(response.Messages, models.NewGetMessage(&message, conversation))
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+// @Tags		   Messages
+// @Summary		   Send Message
+// @Description    Replies in a conversation. Replying marks the conversation read for the sender
+// @Success        200 {object} models.GetMessage
+// @Failure        400 {object} models.Problem
+// @Failure        404 "conversation not found"
+// @Param          conversationid path string true "ID"
+// @Param          Message body models.SendMessage true "Send Message Request"
+// @Router         /conversations/{conversationid}/messages [post]
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+func (h *MessageHandlers) SendMessage(ctx *gin.Context) {
+	conversationID, err := uuid.Parse(ctx.Param("conversationid"))
+	if err != nil {
+		ctx.Error(errInvalidConversationID)
+		return
+	}
+	var req models.SendMessage
+	if !bindJSON(ctx, &req) {
+		return
+	}
+	account, err := middleware.GetAccount(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	message, conversation, err := h.DbRepo.SendMessage(ctx, account.ID, conversationID, req.Body, time.Now())
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, models.NewGetMessage(message, conversation))
+}
+
+// @Tags		   Messages
+// @Summary		   Mark Read
+// @Description    Marks every message in the conversation read for the signed-in participant
+// @Success        200 {object} models.GetConversation
+// @Failure        404 "conversation not found"
+// @Param          conversationid path string true "ID"
+// @Router         /conversations/{conversationid}/read [put]
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+func (h *MessageHandlers) MarkRead(ctx *gin.Context) {
+	conversationID, err := uuid.Parse(ctx.Param("conversationid"))
+	if err != nil {
+		ctx.Error(errInvalidConversationID)
+		return
+	}
+	account, err := middleware.GetAccount(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	conversation, err := h.DbRepo.MarkRead(ctx, account.ID, conversationID, time.Now())
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, models.NewGetConversation(conversation, 0))
+}
@@ -0,0 +1,271 @@
+package handlers
+
+import (
+	"airbnb/imaging"
+	"airbnb/middleware"
+	"airbnb/models"
+	"airbnb/repository"
+	"airbnb/storage"
+	"context"
+	"errors"
+	"image"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// multipartOverhead is room in an upload's body for the multipart framing
+// around a photo of MaxPhotoBytes.
+const multipartOverhead = 64 << 10
+
+// PhotoHandlers manages property photos. Their files are kept in Blobs.
+type PhotoHandlers struct {
+	DbRepo *repository.PhotoRepo
+	Blobs  storage.BlobStore
+}
+
+func NewPhotoHandlers(repo *repository.PhotoRepo, blobs storage.BlobStore) *PhotoHandlers {
+	return &PhotoHandlers{
+		DbRepo: repo,
+		Blobs:  blobs,
+	}
+}
+
+// @Tags		   Property Owner
+// @Summary		   Upload Photo
+// @Description    A Property Owner adds a JPEG or PNG photo, up to 10 MiB, after the property's other photos. The type is taken from the file's content. The stored copies are re-encoded at several sizes without the original's metadata, turned upright first if the EXIF orientation says so
+// @Accept         multipart/form-data
+// @Success        200 {object} models.GetPhoto
+// @Failure        400 {object} models.Problem
+// @Failure        404 "property not found"
+// @Failure        409 "a property can have up to 30 photos"
+// @Failure        413 "photo must be at most 10 MiB"
+// @Failure        415 "photo must be a JPEG or PNG image"
+// @Param          propertyid path string true "ID"
+// @Param          photo formData file true "Photo"
+// @Router         /property/{propertyid}/photos [post]
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+func (h *PhotoHandlers) UploadPhoto(ctx *gin.Context) {
+	property, err := middleware.GetOwnedProperty(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	// Checked again when the photo is added; this saves decoding it first.
+	if len(property.Photos) >= models.MaxPropertyPhotos {
+		ctx.Error(repository.ErrTooManyPhotos)
+		return
+	}
+	data, err := readPhoto(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	img, err := imaging.Decode(data)
+	if errors.Is(err, imaging.ErrUnsupportedFormat) {
+		ctx.Error(errUnsupportedPhoto)
+		return
+	}
+	if err != nil {
+		ctx.Error(invalidInput("invalid_photo", err))
+		return
+	}
+
+	photo := models.Photo{BaseModel: models.BaseModel{ID: uuid.New()}, PropertyID: property.ID}
+	if err := h.storePhoto(ctx, &photo, img); err != nil {
+		h.deleteFiles(ctx, &photo)
+		ctx.Error(err)
+		return
+	}
+	if err := h.DbRepo.AddPhoto(ctx, property.OwnerID, &photo); err != nil {
+		h.deleteFiles(ctx, &photo)
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, models.NewGetPhoto(&photo, h.Blobs.URL))
+}
+
+// readPhoto returns the file uploaded as the photo form field, refusing
+// bodies too large to hold one of MaxPhotoBytes.
+func readPhoto(ctx *gin.Context) ([]byte, error) {
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, models.MaxPhotoBytes+multipartOverhead)
+	header, err := ctx.FormFile("photo")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, errPhotoTooLarge
+		}
+		return nil, errMissingPhoto
+	}
+	if header.Size > models.MaxPhotoBytes {
+		return nil, errPhotoTooLarge
+	}
+	file, err := header.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(file)
+}
+
+// storePhoto puts a copy of img in the BlobStore for each of PhotoSizes,
+// scaling each from the one before, and records the largest's dimensions.
+func (h *PhotoHandlers) storePhoto(ctx context.Context, photo *models.Photo, img *image.RGBA) error {
+	for i, size := range models.PhotoSizes {
+		img = imaging.Fit(img, size.MaxEdge)
+		if i == 0 {
+			photo.Width, photo.Height = img.Bounds().Dx(), img.Bounds().Dy()
+		}
+		data, err := imaging.EncodeJPEG(img)
+		if err != nil {
+			return err
+		}
+		if err := h.Blobs.Put(ctx, models.PhotoKey(photo.PropertyID, photo.ID, size.Name), "image/jpeg", data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteFiles removes a photo's copies from the BlobStore. Failures leave
+// orphaned files rather than failing the request, so they are only logged.
+func (h *PhotoHandlers) deleteFiles(ctx context.Context, photo *models.Photo) {
+	for _, size := range models.PhotoSizes {
+		if err := h.Blobs.Delete(context.WithoutCancel(ctx), models.PhotoKey(photo.PropertyID, photo.ID, size.Name)); err != nil {
+			log.Println("unable to delete photo file", err)
+		}
+	}
+}
+
+// @Tags		   Property Owner
+// @Summary		   Reorder Photos
+// @Description    A Property Owner sets the order of the property's photos by listing each of them once. The first is the cover
+// @Success        200 {object} models.GetPhotos
+// @Failure        400 {object} models.Problem
+// @Failure        404 "property not found"
+// @Param          propertyid path string true "ID"
+// @Param          Photos body models.ReorderPhotos true "Reorder Photos Request"
+// @Router         /property/{propertyid}/photos [put]
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+func (h *PhotoHandlers) ReorderPhotos(ctx *gin.Context) {
+	var req models.ReorderPhotos
+	if !bindJSON(ctx, &req) {
+		return
+	}
+	property, err := middleware.GetOwnedProperty(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	photoIDs := make([]uuid.UUID, 0, len(req.PhotoIDs))
+	for _, id := range req.PhotoIDs {
+		photoID, err := uuid.Parse(id)
+		if err != nil {
+			ctx.Error(errInvalidPhotoID)
+			return
+		}
+		photoIDs = append(photoIDs, photoID)
+	}
+	photos, err := h.DbRepo.ReorderPhotos(ctx, property.OwnerID, property.ID, photoIDs)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, models.NewGetPhotos(photos, h.Blobs.URL))
+}
+
+// @Tags		   Property Owner
+// @Summary		   Set Cover Photo
+// @Description    A Property Owner makes a photo the cover by moving it to the front; the others keep their order
+// @Success        200 {object} models.GetPhotos
+// @Failure        400 {object} models.Problem
+// @Failure        404 "property or photo not found"
+// @Param          propertyid path string true "ID"
+// @Param          photoid path string true "ID"
+// @Router         /property/{propertyid}/photos/{photoid}/cover [put]
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+func (h *PhotoHandlers) SetCoverPhoto(ctx *gin.Context) {
+	photoID, err := uuid.Parse(ctx.Param("photoid"))
+	if err != nil {
+		ctx.Error(errInvalidPhotoID)
+		return
+	}
+	property, err := middleware.GetOwnedProperty(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	photos, err := h.DbRepo.SetCoverPhoto(ctx, property.OwnerID, property.ID, photoID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, models.NewGetPhotos(photos, h.Blobs.URL))
+}
+
+// @Tags		   Property Owner
+// @Summary		   Delete Photo
+// @Description    A Property Owner deletes a photo and its files. The photos after it move up; deleting the cover makes the next photo the cover
+// @Success        200 "photo deleted"
+// @Failure        400 {object} models.Problem
+// @Failure        404 "property or photo not found"
+// @Param          propertyid path string true "ID"
+// @Param          photoid path string true "ID"
+// @Router         /property/{propertyid}/photos/{photoid} [delete]
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+func (h *PhotoHandlers) DeletePhoto(ctx *gin.Context) {
+	photoID, err := uuid.Parse(ctx.Param("photoid"))
+	if err != nil {
+		ctx.Error(errInvalidPhotoID)
+		return
+	}
+	property, err := middleware.GetOwnedProperty(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	photo, err := h.DbRepo.DeletePhoto(ctx, property.OwnerID, property.ID, photoID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	h.deleteFiles(ctx, photo)
+	ctx.JSON(http.StatusOK, gin.H{"message": "photo deleted"})
+}
+
+// @Tags		   Media
+// @Summary		   Get File
+// @Description    Downloads a stored file, such as a photo from a property's photo URLs
+// @Success        200 "the file"
+// @Failure        404 "file not found"
+// @Param          key path string true "Key of the file"
+// @Router         /media/{key} [get]
+func (h *PhotoHandlers) GetMedia(ctx *gin.Context) {
+	key := strings.TrimPrefix(ctx.Param("key"), "/")
+	blob, err := h.Blobs.Open(ctx, key)
+	if errors.Is(err, storage.ErrNotFound) {
+		ctx.Error(errMediaNotFound)
+		return
+	}
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	defer blob.Close()
+
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	// Keys are never reused, so a file can be cached for good.
+	ctx.DataFromReader(http.StatusOK, -1, contentType, blob, map[string]string{
+		"Cache-Control":          "public, max-age=31536000, immutable",
+		"X-Content-Type-Options": "nosniff",
+	})
+}
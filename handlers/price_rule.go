@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"airbnb/middleware"
+	"airbnb/models"
+	"airbnb/repository"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// PriceRuleHandlers lets owners vary a property's nightly price by date and
+// weekday. Quotes and new bookings are priced with the rules; bookings
+// already made keep their price.
+type PriceRuleHandlers struct {
+	DbRepo *repository.PriceRuleRepo
+}
+
+func NewPriceRuleHandlers(repo *repository.PriceRuleRepo) *PriceRuleHandlers {
+	return &PriceRuleHandlers{DbRepo: repo}
+}
+
+// @Tags		   Property Owner
+// @Summary		   Get Pricing
+// @Description    A Property Owner gets the property's base price, length-of-stay discounts and price rules, in the order they were added
+// @Success        200 {object} models.GetPricing
+// @Failure        404 "property not found"
+// @Param          propertyid path string true "ID"
+// @Router         /property/{propertyid}/pricing [get]
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+func (h *PriceRuleHandlers) GetPricing(ctx *gin.Context) {
+	property, err := middleware.GetOwnedProperty(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	rules, err := h.DbRepo.ListPriceRules(ctx, property.ID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, models.NewGetPricing(property, rules))
+}
+
+// @Tags		   Property Owner
+// @Summary		   Add Price Rule
+// @Description    A Property Owner adds a date_range rule, which sets the price of the nights from start_date up to end_date, or a weekday rule, which raises or lowers the base price on some weekdays by adjustment basis points. Where date ranges overlap the shortest one wins, then the one added last; weekday rules do not apply to nights a date range covers, and weekday rules for the same day add up
+// @Success        200 {object} models.GetPriceRule
+// @Failure        400 {object} models.Problem
+// @Failure        404 "property not found"
+// @Failure        409 "a property can have up to 100 price rules"
+// @Param          propertyid path string true "ID"
+// @Param          Rule body models.CreatePriceRule true "Create Price Rule Request"
+// @Router         /property/{propertyid}/pricing/rules [post]
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+func (h *PriceRuleHandlers) AddPriceRule(ctx *gin.Context) {
+	var req models.CreatePriceRule
+	if !bindJSON(ctx, &req) {
+		return
+	}
+	property, err := middleware.GetOwnedProperty(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	rule, err := req.NewPriceRule(property.ID)
+	if err != nil {
+		ctx.Error(invalidInput("invalid_price_rule", err))
+		return
+	}
+	if err := h.DbRepo.AddPriceRule(ctx, property.OwnerID, rule); err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, models.NewGetPriceRule(rule))
+}
+
+// @Tags		   Property Owner
+// @Summary		   Delete Price Rule
+// @Description    A Property Owner deletes a price rule
+// @Success        200 "price rule deleted"
+// @Failure        400 {object} models.Problem
+// @Failure        404 "property or price rule not found"
+// @Param          propertyid path string true "ID"
+// @Param          ruleid path string true "ID"
+// @Router         /property/{propertyid}/pricing/rules/{ruleid} [delete]
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+func (h *PriceRuleHandlers) DeletePriceRule(ctx *gin.Context) {
+	ruleID, err := uuid.Parse(ctx.Param("ruleid"))
+	if err != nil {
+		ctx.Error(errInvalidPriceRuleID)
+		return
+	}
+	property, err := middleware.GetOwnedProperty(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	if err := h.DbRepo.DeletePriceRule(ctx, property.OwnerID, property.ID, ruleID); err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "price rule deleted"})
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"airbnb/middleware"
+	"airbnb/models"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// @Tags		   Profile
+// @Summary		   Get Profile
+// @Description    Gets the signed-in account's profile
+// @Success        200 {object} models.Profile
+// @Router         /me [get]
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+func (h *AccountHandlers) GetProfile(ctx *gin.Context) {
+	account, err := middleware.GetAccount(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, models.NewProfile(account))
+}
+
+// @Tags		   Profile
+// @Summary		   Update Profile
+// @Description    Changes the account's name and email. Fields left out of the body are unchanged. A new email is sent a verification link and must be verified again before the account can book
+// @Success        200 {object} models.Profile
+// @Failure        400 {object} models.Problem
+// @Failure        409 "an account with this email already exists"
+// @Param          Profile body models.UpdateProfile true "Update Profile Request"
+// @Router         /me [patch]
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+func (h *AccountHandlers) UpdateProfile(ctx *gin.Context) {
+	var req models.UpdateProfile
+	if !bindJSON(ctx, &req) {
+		return
+	}
+	account, err := middleware.GetAccount(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	if req.Name != nil {
+		account.Name = *req.Name
+	}
+	emailChanged := req.Email != nil && *req.Email != account.Email
+	if emailChanged {
+		account.Email = *req.Email
+		account.EmailVerifiedAt = nil
+	}
+	if err := h.DbRepo.UpdateAccount(ctx, account); err != nil {
+		ctx.Error(err)
+		return
+	}
+	if emailChanged {
+		if err := h.sendVerificationEmail(ctx, account); err != nil {
+			log.Println("unable to send verification email", err)
+		}
+	}
+
+	ctx.JSON(http.StatusOK, models.NewProfile(account))
+}
+
+// @Tags		   Profile
+// @Summary		   Change Password
+// @Description    Changes the password after checking the current one. Every session is signed out and a new pair of tokens is returned for this one
+// @Success        200 {object} models.AuthTokens
+// @Failure        400 {object} models.Problem
+// @Failure        401 "current password is incorrect"
+// @Param          Password body models.ChangePassword true "Change Password Request"
+// @Router         /me/password [put]
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+func (h *AccountHandlers) ChangePassword(ctx *gin.Context) {
+	var req models.ChangePassword
+	if !bindJSON(ctx, &req) {
+		return
+	}
+	account, err := middleware.GetAccount(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(account.Password), []byte(req.CurrentPassword)); err != nil {
+		ctx.Error(errWrongPassword)
+		return
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	account.Password = string(hashedPassword)
+	if err := h.DbRepo.UpdateAccount(ctx, account); err != nil {
+		ctx.Error(err)
+		return
+	}
+	if !h.revokeCurrentToken(ctx) {
+		return
+	}
+	revoked, err := h.Tokens.RevokeAccountTokens(ctx, account.ID, time.Now())
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	h.Auth.Denylist.Add(revoked...)
+
+	h.signIn(ctx, account, "password changed")
+}
+
+// @Tags		   Profile
+// @Summary		   Delete Account
+// @Description    Deletes the account and its properties after checking the password. Upcoming bookings, as a guest or on the account's properties, must be cancelled first. Past bookings are kept for the other party's records without the account's name or email
+// @Success        200 "account deleted"
+// @Failure        401 "current password is incorrect"
+// @Failure        409 "account has upcoming bookings"
+// @Param          Confirm body models.DeleteAccount true "Delete Account Request"
+// @Router         /me [delete]
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+func (h *AccountHandlers) DeleteAccount(ctx *gin.Context) {
+	var req models.DeleteAccount
+	if !bindJSON(ctx, &req) {
+		return
+	}
+	account, err := middleware.GetAccount(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(account.Password), []byte(req.Password)); err != nil {
+		ctx.Error(errWrongPassword)
+		return
+	}
+	revoked, err := h.DbRepo.DeleteAccount(ctx, account.ID, time.Now())
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	h.Auth.Denylist.Add(revoked...)
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "account deleted"})
+}
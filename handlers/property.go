@@ -4,125 +4,128 @@ import (
 	"airbnb/middleware"
 	"airbnb/models"
 	"airbnb/repository"
+	"airbnb/storage"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	"golang.org/x/crypto/bcrypt"
 )
 
+// PropertyHandlers manages properties. Blobs holds their photos.
 type PropertyHandlers struct {
 	DbRepo *repository.PropertyRepo
+	Blobs  storage.BlobStore
 }
 
-func NewPropertyHandlers(repo *repository.PropertyRepo) *PropertyHandlers {
+func NewPropertyHandlers(repo *repository.PropertyRepo, blobs storage.BlobStore) *PropertyHandlers {
 	return &PropertyHandlers{
 		DbRepo: repo,
+		Blobs:  blobs,
 	}
 }
 
 // @Tags		   Property Owner
-// @Summary		   SignUp Property Owner
-// @Description    A Property Owner signups
-// @Success        200 "property owner successfully"
-// @Param          Owner body models.CreatePropertyOwner true "Create Property Owner Request"
-// @Router         /property/owner/signup [post]
-func (h *PropertyHandlers) CreatePropertyOwner(ctx *gin.Context) {
-	var req models.CreatePropertyOwner
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
-		return
-	}
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to process password"})
+// @Summary		   Create Property
+// @Description    A Property Owner creates a property
+// @Success        200 "property created  successfully"
+// @Param          Owner body models.CreateProperty true "Create Property Request"
+// @Router         /property/create [post]
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+func (h *PropertyHandlers) CreateProperty(ctx *gin.Context) {
+	var req models.CreateProperty
+	if !bindJSON(ctx, &req) {
 		return
 	}
-	owner := models.PropertyOwner{
-		Name:     req.Name,
-		Email:    req.Email,
-		Password: string(hashedPassword),
-		Role:     models.PropertyRole,
-	}
-	if err := h.DbRepo.CreatePropertyOwner(ctx, &owner); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	owner, err := middleware.GetAccount(ctx)
+	if err != nil {
+		ctx.Error(err)
 		return
 	}
-	tokenString, err := middleware.GeneratePropertyOwnerToken(owner.ID)
+
+	currency, err := models.NormalizeCurrency(req.Currency)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(invalidInput("invalid_currency", err))
 		return
 	}
-	ctx.JSON(http.StatusOK, gin.H{
-		"message":  "property owner created successfully",
-		"owner_id": owner.ID,
-		"role":     owner.Role,
-		"token":    tokenString,
-	})
-}
-
-// @Tags		   Property Owner
-// @Summary		   SignIn Property Owner
-// @Description    A Property Owner signs-in
-// @Success        200 "property owner successfully"
-// @Param          Owner body models.LoginPropertyOwner true "Create Property Owner Request"
-// @Router         /property/owner/login [post]
-func (h *PropertyHandlers) LoginPropertyOwner(ctx *gin.Context) {
-	var req models.LoginPropertyOwner
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
-		return
-	}
-	owner, err := h.DbRepo.GetPropertyOwnerByEmail(ctx, req.Email)
+	policy, err := models.NormalizeCancellationPolicy(req.CancellationPolicy)
 	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "invalid email or password"})
+		ctx.Error(invalidInput("invalid_cancellation_policy", err))
 		return
 	}
-	if err := bcrypt.CompareHashAndPassword([]byte(owner.Password), []byte(req.Password)); err != nil {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "invalid email or password"})
+	if req.MaxGuests == 0 {
+		req.MaxGuests = 1
+	}
+	if req.PropertyType == "" {
+		req.PropertyType = models.EntireHome
+	}
+	if req.Beds == 0 {
+		req.Beds = 1
+	}
+	bathrooms := 1.0
+	if req.Bathrooms != nil {
+		bathrooms = *req.Bathrooms
+	}
+	if err := models.ValidateBathrooms(bathrooms); err != nil {
+		ctx.Error(invalidInput("invalid_bathrooms", err))
 		return
 	}
-
-	tokenString, err := middleware.GeneratePropertyOwnerToken(owner.ID)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := req.HouseRules.Normalize(); err != nil {
+		ctx.Error(invalidInput("invalid_house_rules", err))
 		return
 	}
-	ctx.JSON(http.StatusOK, gin.H{
-		"message":  "login successful",
-		"token":    tokenString,
-		"owner_id": owner.ID,
-		"role":     owner.Role,
-	})
-}
-
-// @Tags		   Property Owner
-// @Summary		   Create Property
-// @Description    A Property Owner creates a property
-// @Success        200 "property created  successfully"
-// @Param          Owner body models.CreateProperty true "Create Property Request"
-// @Router         /property/create [post]
-// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
-func (h *PropertyHandlers) CreateProperty(ctx *gin.Context) {
-	var req models.CreateProperty
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+	if err := req.StayRules.Normalize(); err != nil {
+		ctx.Error(invalidInput("invalid_stay_rules", err))
 		return
 	}
-	owner, err := middleware.GetPropertyOwner(ctx)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	checkInTime, checkOutTime := models.DefaultCheckInTime, models.DefaultCheckOutTime
+	if req.CheckInTime != "" {
+		if checkInTime, err = models.NormalizeTimeOfDay(req.CheckInTime); err != nil {
+			ctx.Error(invalidInput("invalid_check_in_time", err))
+			return
+		}
+	}
+	if req.CheckOutTime != "" {
+		if checkOutTime, err = models.NormalizeTimeOfDay(req.CheckOutTime); err != nil {
+			ctx.Error(invalidInput("invalid_check_out_time", err))
+			return
+		}
+	}
+	if err := req.Address.Validate(); err != nil {
+		ctx.Error(invalidInput("invalid_address", err))
+		return
+	}
+	if err := models.ValidateCoordinates(req.Latitude, req.Longitude); err != nil {
+		ctx.Error(invalidInput("invalid_coordinates", err))
 		return
 	}
 
 	property := models.Property{
-		Name:        req.PropertyName,
-		Description: req.Description,
-		Price:       req.Price,
-		OwnerID:     owner.ID,
+		Name:               req.PropertyName,
+		Description:        req.Description,
+		Price:              req.Price,
+		CleaningFee:        req.CleaningFee,
+		WeeklyDiscount:     req.WeeklyDiscount,
+		MonthlyDiscount:    req.MonthlyDiscount,
+		Currency:           currency,
+		CancellationPolicy: policy,
+		PropertyType:       req.PropertyType,
+		MaxGuests:          req.MaxGuests,
+		Bedrooms:           req.Bedrooms,
+		Beds:               req.Beds,
+		Bathrooms:          bathrooms,
+		HouseRules:         req.HouseRules,
+		StayRules:          req.StayRules,
+		CheckInTime:        checkInTime,
+		CheckOutTime:       checkOutTime,
+		Location:           req.Address.String(),
+		Address:            req.Address,
+		Latitude:           req.Latitude,
+		Longitude:          req.Longitude,
+		OwnerID:            owner.ID,
 	}
-	if err := h.DbRepo.CreateProperty(ctx, &property); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.DbRepo.CreateProperty(ctx, &property, req.Amenities); err != nil {
+		ctx.Error(err)
 		return
 	}
 
@@ -134,39 +137,19 @@ func (h *PropertyHandlers) CreateProperty(ctx *gin.Context) {
 
 // @Tags		   Property Owner
 // @Summary		   Get a  Property
-// @Description    A Property Owner gets a property details
+// @Description    A Property Owner gets one of his property details
 // @Success        200 {object} models.GetProperty "property created  successfully"
+// @Failure        404 "property not found"
+// @Param          propertyid path string true "ID"
 // @Router         /property/{propertyid} [get]
 // @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
 func (h *PropertyHandlers) GetPropertyByID(ctx *gin.Context) {
-	idParam := ctx.Param("propertyid")
-	propertyID, err := uuid.Parse(idParam)
+	property, err := middleware.GetOwnedProperty(ctx)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid property ID"})
+		ctx.Error(err)
 		return
 	}
-	_, err = middleware.GetPropertyOwner(ctx)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-	property, err := h.DbRepo.GetPropertyByID(ctx, propertyID)
-	if err != nil {
-		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
-		return
-	}
-	response := models.GetProperty{
-		PropertyID:   property.ID,
-		PropertyName: property.Name,
-		Description:  property.Description,
-		Price:        property.Price,
-		PropertyOwner: models.GetPropertyOwner{
-			OwnerID: property.Owner.ID,
-			Name:    property.Owner.Name,
-			Email:   property.Owner.Email,
-		},
-	}
-	ctx.JSON(http.StatusOK, response)
+	ctx.JSON(http.StatusOK, newGetProperty(property, h.Blobs))
 }
 
 // @Tags		   Property Owner
@@ -176,30 +159,20 @@ func (h *PropertyHandlers) GetPropertyByID(ctx *gin.Context) {
 // @Router         /property/owner [get]
 // @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
 func (h *PropertyHandlers) GetAllProperties(ctx *gin.Context) {
-	owner, err := middleware.GetPropertyOwner(ctx)
+	owner, err := middleware.GetAccount(ctx)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 	properties, err := h.DbRepo.GetAllProperties(ctx, owner.ID)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
 	var response models.GetAllProperties
 	for _, prop := range properties {
-		response.Properties = append(response.Properties, models.GetProperty{
-			PropertyID:   prop.ID,
-			PropertyName: prop.Name,
-			Description:  prop.Description,
-			Price:        prop.Price,
-			PropertyOwner: models.GetPropertyOwner{
-				OwnerID: prop.Owner.ID,
-				Name:    prop.Owner.Name,
-				Email:   prop.Owner.Email,
-			},
-		})
+		response.Properties = append(response.Properties, newGetProperty(&prop, h.Blobs))
 	}
 
 	ctx.JSON(http.StatusOK, response)
@@ -207,30 +180,231 @@ func (h *PropertyHandlers) GetAllProperties(ctx *gin.Context) {
 
 // @Tags		   Property Owner
 // @Summary		   Get all Property
-// @Description    A User Owner gets all  properties  available
-// @Success        200 {object} []models.GetProperty
+// @Description    A User searches the properties available. Results are paginated; pass next_cursor back as cursor to fetch the next page
+// @Success        200 {object} models.GetAllProperties
+// @Failure        400 "invalid search"
+// @Param          q query string false "Text to find in the name or description"
+// @Param          location query string false "Text to find in the address"
+// @Param          min_price query int false "Minimum nightly price in minor units"
+// @Param          max_price query int false "Maximum nightly price in minor units"
+// @Param          guests query int false "Number of guests the property must fit"
+// @Param          check_in query string false "Only properties free from this date" example(2025-01-10)
+// @Param          check_out query string false "Only properties free until this date" example(2025-01-15)
+// @Param          property_type query string false "entire_home, private_room or shared_room"
+// @Param          min_bedrooms query int false "Minimum number of bedrooms"
+// @Param          min_beds query int false "Minimum number of beds"
+// @Param          min_bathrooms query number false "Minimum number of bathrooms"
+// @Param          amenities query string false "Comma-separated amenity keys the property must all have" example(wifi,kitchen)
+// @Param          pets_allowed query bool false "Only properties that allow pets"
+// @Param          smoking_allowed query bool false "Only properties that allow smoking"
+// @Param          events_allowed query bool false "Only properties that allow events"
+// @Param          sort query string false "newest (default), price_asc, price_desc or rating"
+// @Param          cursor query string false "next_cursor from the previous page"
+// @Param          limit query int false "Page size, 1-100 (default 20)"
+// @Param          min_lat query number false "Map viewport south edge"
+// @Param          min_lng query number false "Map viewport west edge"
+// @Param          max_lat query number false "Map viewport north edge"
+// @Param          max_lng query number false "Map viewport east edge; below min_lng when crossing the antimeridian"
 // @Router         /property/all [get]
 func (h *PropertyHandlers) GetProperties(ctx *gin.Context) {
-	properties, err := h.DbRepo.GetProperties(ctx)
+	var search models.PropertySearch
+	if !bindQuery(ctx, &search) {
+		return
+	}
+	if err := search.Normalize(); err != nil {
+		ctx.Error(invalidInput("invalid_search", err))
+		return
+	}
+
+	properties, next, err := h.DbRepo.SearchProperties(ctx, search)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
-	var response models.GetAllProperties
+	response := models.GetAllProperties{
+		Properties: []models.GetProperty{},
+		NextCursor: next,
+	}
 	for _, prop := range properties {
-		response.Properties = append(response.Properties, models.GetProperty{
-			PropertyID:   prop.ID,
-			PropertyName: prop.Name,
-			Description:  prop.Description,
-			Price:        prop.Price,
-			PropertyOwner: models.GetPropertyOwner{
-				OwnerID: prop.Owner.ID,
-				Name:    prop.Owner.Name,
-				Email:   prop.Owner.Email,
-			},
+		response.Properties = append(response.Properties, newGetProperty(&prop, h.Blobs))
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// @Tags		   Property Owner
+// @Summary		   Nearby Properties
+// @Description    A User finds properties within a radius of a point, closest first
+// @Success        200 {object} models.GetNearbyProperties
+// @Failure        400 "invalid coordinates or radius"
+// @Param          lat query number true "Latitude"
+// @Param          lng query number true "Longitude"
+// @Param          radius_km query number false "Search radius in kilometres, up to 500 (default 10)"
+// @Param          limit query int false "Maximum results, 1-100 (default 20)"
+// @Router         /property/nearby [get]
+func (h *PropertyHandlers) GetNearbyProperties(ctx *gin.Context) {
+	var search models.NearbySearch
+	if !bindQuery(ctx, &search) {
+		return
+	}
+	if err := search.Normalize(); err != nil {
+		ctx.Error(invalidInput("invalid_search", err))
+		return
+	}
+
+	results, err := h.DbRepo.NearbyProperties(ctx, search)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	response := models.GetNearbyProperties{Properties: []models.GetNearbyProperty{}}
+	for _, result := range results {
+		response.Properties = append(response.Properties, models.GetNearbyProperty{
+			GetProperty: newGetProperty(&result.Property, h.Blobs),
+			DistanceKm:  result.DistanceKm,
 		})
 	}
 
 	ctx.JSON(http.StatusOK, response)
 }
+
+// @Tags		   Property Owner
+// @Summary		   Quote a stay
+// @Description    Returns an itemised price quote for a stay. Amounts are integers in minor units of the returned currency, e.g. cents for USD. Stays can be at most 365 nights. A stay the property's stay rules do not allow is refused with stay_not_allowed, listing each broken rule in errors, as when booking it
+// @Success        200 {object} models.Quote
+// @Failure        400 "invalid dates, more guests than the property sleeps, or a stay the stay rules do not allow"
+// @Failure        404 "property not found"
+// @Param          propertyid path string true "ID"
+// @Param          Quote body models.CreateQuote true "Quote Request"
+// @Router         /property/{propertyid}/quote [post]
+func (h *PropertyHandlers) GetQuote(ctx *gin.Context) {
+	idParam := ctx.Param("propertyid")
+	propertyID, err := uuid.Parse(idParam)
+	if err != nil {
+		ctx.Error(middleware.ErrInvalidPropertyID)
+		return
+	}
+	var req models.CreateQuote
+	if !bindJSON(ctx, &req) {
+		return
+	}
+	checkIn, checkOut, err := models.ParseDateRange(req.CheckIn, req.CheckOut, time.Now())
+	if err != nil {
+		ctx.Error(invalidInput("invalid_dates", err))
+		return
+	}
+
+	property, err := h.DbRepo.GetPropertyByID(ctx, propertyID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	if req.Guests > property.MaxGuests {
+		ctx.Error(repository.ErrTooManyGuests)
+		return
+	}
+	if violations := property.StayRules.Check(checkIn, checkOut, time.Now()); len(violations) > 0 {
+		ctx.Error(&models.StayError{Violations: violations})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.NewQuote(property, checkIn, checkOut, req.Guests))
+}
+
+// @Tags		   Property Owner
+// @Summary		   Update a Property
+// @Description    A Property Owner updates some of his property's fields. Fields left out of the body are unchanged
+// @Success        200 {object} models.GetProperty
+// @Failure        400 "invalid field"
+// @Failure        404 "property not found"
+// @Param          propertyid path string true "ID"
+// @Param          Property body models.UpdateProperty true "Update Property Request"
+// @Router         /property/{propertyid} [patch]
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+func (h *PropertyHandlers) UpdateProperty(ctx *gin.Context) {
+	var req models.UpdateProperty
+	if !bindJSON(ctx, &req) {
+		return
+	}
+	property, err := middleware.GetOwnedProperty(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	if err := req.Apply(property); err != nil {
+		ctx.Error(invalidInput("invalid_property", err))
+		return
+	}
+	if err := h.DbRepo.UpdateProperty(ctx, property, req.Amenities); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newGetProperty(property, h.Blobs))
+}
+
+// @Tags		   Property Owner
+// @Summary		   Delete a Property
+// @Description    A Property Owner delists a property. Pending bookings are declined. Upcoming confirmed bookings block deletion unless force=true, which cancels them with a full refund
+// @Success        200 "property deleted"
+// @Failure        404 "property not found"
+// @Failure        409 "property has upcoming confirmed bookings or guests checked in"
+// @Param          propertyid path string true "ID"
+// @Param          force query bool false "Cancel upcoming confirmed bookings"
+// @Router         /property/{propertyid} [delete]
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+func (h *PropertyHandlers) DeleteProperty(ctx *gin.Context) {
+	property, err := middleware.GetOwnedProperty(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	force := ctx.Query("force") == "true"
+
+	if err := h.DbRepo.DeleteProperty(ctx, property.OwnerID, property.ID, force, time.Now()); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "property deleted"})
+}
+
+// newGetProperty describes property with the URLs of its photos in blobs.
+func newGetProperty(property *models.Property, blobs storage.BlobStore) models.GetProperty {
+	return models.GetProperty{
+		PropertyID:         property.ID,
+		PropertyName:       property.Name,
+		Description:        property.Description,
+		Price:              property.Price,
+		CleaningFee:        property.CleaningFee,
+		WeeklyDiscount:     property.WeeklyDiscount,
+		MonthlyDiscount:    property.MonthlyDiscount,
+		Currency:           property.Currency,
+		CancellationPolicy: property.CancellationPolicy,
+		PropertyType:       property.PropertyType,
+		MaxGuests:          property.MaxGuests,
+		Bedrooms:           property.Bedrooms,
+		Beds:               property.Beds,
+		Bathrooms:          property.Bathrooms,
+		RatingAverage:      property.RatingAverage,
+		RatingCount:        property.RatingCount,
+		Address:            property.Address,
+		Latitude:           property.Latitude,
+		Longitude:          property.Longitude,
+		Amenities:          models.NewGetAmenities(property.Amenities),
+		HouseRules:         property.HouseRules,
+		StayRules:          property.StayRules,
+		CheckInTime:        property.CheckInTime,
+		CheckOutTime:       property.CheckOutTime,
+		UnlistedAt:         property.UnlistedAt,
+		PropertyOwner: models.GetPropertyOwner{
+			OwnerID: property.Owner.ID,
+			Name:    property.Owner.Name,
+			Email:   property.Owner.Email,
+		},
+		Photos: models.NewGetPhotos(property.Photos, blobs.URL).Photos,
+	}
+}
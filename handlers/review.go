@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"airbnb/middleware"
+	"airbnb/models"
+	"airbnb/repository"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ReviewHandlers struct {
+	DbRepo *repository.ReviewRepo
+}
+
+func NewReviewHandlers(repo *repository.ReviewRepo) *ReviewHandlers {
+	return &ReviewHandlers{
+		DbRepo: repo,
+	}
+}
+
+// @Tags		   Reviews
+// @Summary		   Review Property
+// @Description    A guest rates a stay from 1 to 5 and describes it. Allowed once per booking, after a confirmed stay's check-out date
+// @Success        200 {object} models.GetReview
+// @Failure        400 {object} models.Problem
+// @Failure        404 "booking not found"
+// @Failure        409 "reviews can be left once a confirmed stay has checked out"
+// @Param          bookingid path string true "ID"
+// @Param          Review body models.CreateReview true "Create Review Request"
+// @Router         /user/review/{bookingid} [post]
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+func (h *ReviewHandlers) ReviewProperty(ctx *gin.Context) {
+	h.createReview(ctx, models.GuestRole)
+}
+
+// @Tags		   Reviews
+// @Summary		   Review Guest
+// @Description    A host rates a guest from 1 to 5 after their stay. Allowed once per booking, after a confirmed stay's check-out date
+// @Success        200 {object} models.GetReview
+// @Failure        400 {object} models.Problem
+// @Failure        404 "booking not found"
+// @Failure        409 "reviews can be left once a confirmed stay has checked out"
+// @Param          bookingid path string true "ID"
+// @Param          Review body models.CreateReview true "Create Review Request"
+// @Router         /owner/booking/{bookingid}/review [post]
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+func (h *ReviewHandlers) ReviewGuest(ctx *gin.Context) {
+	h.createReview(ctx, models.HostRole)
+}
+
+func (h *ReviewHandlers) createReview(ctx *gin.Context, role string) {
+	bookingID, err := uuid.Parse(ctx.Param("bookingid"))
+	if err != nil {
+		ctx.Error(errInvalidBookingID)
+		return
+	}
+	var req models.CreateReview
+	if !bindJSON(ctx, &req) {
+		return
+	}
+	account, err := middleware.GetAccount(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	actor, err := middleware.GetActor(ctx, role)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	review := models.Review{Rating: req.Rating, Comment: req.Comment}
+	if err := h.DbRepo.CreateReview(ctx, bookingID, actor, &review, time.Now()); err != nil {
+		ctx.Error(err)
+		return
+	}
+	review.Author = *account
+	ctx.JSON(http.StatusOK, models.NewGetReview(&review))
+}
+
+// @Tags		   Reviews
+// @Summary		   Reply to Review
+// @Description    A host replies once to a guest's review of one of their properties
+// @Success        200 {object} models.GetReview
+// @Failure        404 "review not found"
+// @Failure        409 "this review already has a reply"
+// @Param          reviewid path string true "ID"
+// @Param          Reply body models.ReplyToReview true "Reply Request"
+// @Router         /owner/review/{reviewid}/reply [put]
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+func (h *ReviewHandlers) ReplyToReview(ctx *gin.Context) {
+	reviewID, err := uuid.Parse(ctx.Param("reviewid"))
+	if err != nil {
+		ctx.Error(errInvalidReviewID)
+		return
+	}
+	var req models.ReplyToReview
+	if !bindJSON(ctx, &req) {
+		return
+	}
+	owner, err := middleware.GetAccount(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	review, err := h.DbRepo.ReplyToReview(ctx, owner.ID, reviewID, req.Reply, time.Now())
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, models.NewGetReview(review))
+}
+
+// @Tags		   Reviews
+// @Summary		   Property Reviews
+// @Description    Lists guests' reviews of a property with the host's replies, newest first. Pass next_cursor back as cursor for the next page
+// @Success        200 {object} models.GetReviews
+// @Failure        404 "property not found"
+// @Param          propertyid path string true "ID"
+// @Param          cursor query string false "Cursor from the previous page"
+// @Param          limit query int false "Page size, 1-100" default(20)
+// @Router         /property/{propertyid}/reviews [get]
+func (h *ReviewHandlers) GetPropertyReviews(ctx *gin.Context) {
+	propertyID, err := uuid.Parse(ctx.Param("propertyid"))
+	if err != nil {
+		ctx.Error(middleware.ErrInvalidPropertyID)
+		return
+	}
+	h.listReviews(ctx, func(search models.ReviewSearch) ([]models.Review, string, error) {
+		return h.DbRepo.GetPropertyReviews(ctx, propertyID, search)
+	})
+}
+
+// @Tags		   Reviews
+// @Summary		   My Reviews
+// @Description    A guest lists what hosts have said about them, newest first
+// @Success        200 {object} models.GetReviews
+// @Param          cursor query string false "Cursor from the previous page"
+// @Param          limit query int false "Page size, 1-100" default(20)
+// @Router         /user/reviews [get]
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+func (h *ReviewHandlers) GetGuestReviews(ctx *gin.Context) {
+	guest, err := middleware.GetAccount(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	h.listReviews(ctx, func(search models.ReviewSearch) ([]models.Review, string, error) {
+		return h.DbRepo.GetGuestReviews(ctx, guest.ID, search)
+	})
+}
+
+func (h *ReviewHandlers) listReviews(ctx *gin.Context, list func(search models.ReviewSearch) ([]models.Review, string, error)) {
+	var search models.ReviewSearch
+	if !bindQuery(ctx, &search) {
+		return
+	}
+	if search.Limit == 0 {
+		search.Limit = models.DefaultPageSize
+	}
+	reviews, next, err := list(search)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	response := models.GetReviews{Reviews: []models.GetReview{}, NextCursor: next}
+	for _, review := range reviews {
+		response.Reviews = append(response.Reviews, models.NewGetReview(&review))
+	}
+	ctx.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"airbnb/middleware"
+	"airbnb/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// Request models declare their rules in binding tags, which gin checks while
+// binding. The validator reports fields by their json or form name so errors
+// match what the client sent.
+func init() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		for _, tag := range []string{"json", "form"} {
+			name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+			if name == "-" {
+				return ""
+			}
+			if name != "" {
+				return name
+			}
+		}
+		return field.Name
+	})
+	_ = v.RegisterValidation("password", func(fl validator.FieldLevel) bool {
+		return isStrongPassword(fl.Field().String())
+	})
+}
+
+// isStrongPassword requires MinPasswordLength characters including at least
+// one letter and one digit.
+func isStrongPassword(password string) bool {
+	if len(password) < models.MinPasswordLength {
+		return false
+	}
+	var letter, digit bool
+	for _, r := range password {
+		letter = letter || unicode.IsLetter(r)
+		digit = digit || unicode.IsDigit(r)
+	}
+	return letter && digit
+}
+
+// bindJSON binds and validates the request body into req. On failure it
+// records a validation error listing the invalid fields and returns false.
+func bindJSON(ctx *gin.Context, req any) bool {
+	if err := ctx.ShouldBindJSON(req); err != nil {
+		writeBindError(ctx, err)
+		return false
+	}
+	return true
+}
+
+// bindQuery is bindJSON for query parameters.
+func bindQuery(ctx *gin.Context, req any) bool {
+	if err := ctx.ShouldBindQuery(req); err != nil {
+		writeBindError(ctx, err)
+		return false
+	}
+	return true
+}
+
+func writeBindError(ctx *gin.Context, err error) {
+	ctx.Error(&middleware.ValidationError{Fields: fieldErrors(err)})
+}
+
+// fieldErrors turns a binding error into the fields it concerns.
+func fieldErrors(err error) []models.FieldError {
+	var validationErrs validator.ValidationErrors
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.As(err, &validationErrs):
+		fields := make([]models.FieldError, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			fields = append(fields, newFieldError(fe))
+		}
+		return fields
+	case errors.As(err, &typeErr):
+		return []models.FieldError{{
+			Field:   typeErr.Field,
+			Code:    "type",
+			Message: fmt.Sprintf("%s must be a %s", typeErr.Field, jsonType(typeErr.Type)),
+		}}
+	case errors.Is(err, io.EOF):
+		return []models.FieldError{{Code: "required", Message: "request body is required"}}
+	case errors.As(err, &syntaxErr):
+		return []models.FieldError{{Code: "malformed", Message: "request body is not valid JSON"}}
+	}
+	return []models.FieldError{{Code: "invalid", Message: err.Error()}}
+}
+
+func newFieldError(fe validator.FieldError) models.FieldError {
+	// The namespace starts with the request type, e.g. CreateProperty.address.city.
+	_, field, _ := strings.Cut(fe.Namespace(), ".")
+	return models.FieldError{Field: field, Code: fe.Tag(), Message: fieldMessage(field, fe)}
+}
+
+func fieldMessage(field string, fe validator.FieldError) string {
+	isString := fe.Kind() == reflect.String
+	switch fe.Tag() {
+	case "required":
+		return field + " is required"
+	case "email":
+		return field + " must be a valid email address"
+	case "password":
+		return fmt.Sprintf("%s must be at least %d characters and contain a letter and a number", field, models.MinPasswordLength)
+	case "min":
+		if isString {
+			return fmt.Sprintf("%s must be at least %s characters", field, fe.Param())
+		}
+		return fmt.Sprintf("%s must be at least %s", field, fe.Param())
+	case "max":
+		if isString {
+			return fmt.Sprintf("%s must be at most %s characters", field, fe.Param())
+		}
+		return fmt.Sprintf("%s must be at most %s", field, fe.Param())
+	case "len":
+		return fmt.Sprintf("%s must be exactly %s characters", field, fe.Param())
+	case "gt":
+		return fmt.Sprintf("%s must be greater than %s", field, fe.Param())
+	case "gte":
+		return fmt.Sprintf("%s cannot be less than %s", field, fe.Param())
+	case "lte":
+		return fmt.Sprintf("%s cannot be greater than %s", field, fe.Param())
+	case "oneof":
+		return fmt.Sprintf("%s must be one of %s", field, strings.ReplaceAll(fe.Param(), " ", ", "))
+	case "alpha":
+		return field + " must contain only letters"
+	case "datetime":
+		if format, ok := datetimeFormats[fe.Param()]; ok {
+			return field + " must be " + format
+		}
+		return field + " must match the layout " + fe.Param()
+	}
+	return field + " is invalid"
+}
+
+// datetimeFormats describes, by their Go layout, the formats datetime
+// binding tags ask for.
+var datetimeFormats = map[string]string{
+	models.DateLayout: "a date in YYYY-MM-DD format",
+	"15:04":           "a time in HH:MM format",
+}
+
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "whole number"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Slice, reflect.Array:
+		return "list"
+	case reflect.Struct, reflect.Map:
+		return "object"
+	}
+	return "string"
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"airbnb/mailer"
+	"airbnb/middleware"
+	"airbnb/models"
+	"airbnb/repository"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// issueAccountToken stores a new single-use token for account and returns the
+// token to email to it.
+func (h *AccountHandlers) issueAccountToken(ctx context.Context, account *models.Account, purpose string, ttl time.Duration) (string, error) {
+	token, hash, err := middleware.GenerateToken()
+	if err != nil {
+		return "", err
+	}
+	row := &models.AccountToken{
+		AccountID: account.ID,
+		Purpose:   purpose,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(ttl).UTC(),
+	}
+	if err := h.DbRepo.CreateAccountToken(ctx, row); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func (h *AccountHandlers) sendVerificationEmail(ctx context.Context, account *models.Account) error {
+	token, err := h.issueAccountToken(ctx, account, models.VerifyEmailPurpose, models.VerifyEmailTokenTTL)
+	if err != nil {
+		return err
+	}
+	link := h.BaseURL + "/auth/verify-email?token=" + url.QueryEscape(token)
+	return h.Mailer.Send(ctx, mailer.Message{
+		To:      account.Email,
+		Subject: "Confirm your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nConfirm your email address by opening this link within %d hours:\n\n%s\n",
+			account.Name, int(models.VerifyEmailTokenTTL.Hours()), link),
+	})
+}
+
+func (h *AccountHandlers) sendPasswordResetEmail(ctx context.Context, account *models.Account) error {
+	token, err := h.issueAccountToken(ctx, account, models.ResetPasswordPurpose, models.ResetPasswordTokenTTL)
+	if err != nil {
+		return err
+	}
+	return h.Mailer.Send(ctx, mailer.Message{
+		To:      account.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nUse this code to reset your password within the next hour:\n\n%s\n\n"+
+			"If you did not ask to reset your password you can ignore this email.\n",
+			account.Name, token),
+	})
+}
+
+// @Tags		   Auth
+// @Summary		   Verify Email
+// @Description    Confirms the account's email address with the token from the verification link. Each link works once
+// @Success        200 "email verified"
+// @Failure        400 "token is invalid, expired or already used"
+// @Param          token query string true "Verification token"
+// @Router         /auth/verify-email [get]
+func (h *AccountHandlers) VerifyEmail(ctx *gin.Context) {
+	token := ctx.Query("token")
+	if token == "" {
+		ctx.Error(errMissingToken)
+		return
+	}
+	if _, err := h.DbRepo.VerifyEmail(ctx, middleware.HashToken(token), time.Now()); err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "email verified"})
+}
+
+// @Tags		   Auth
+// @Summary		   Resend Verification Email
+// @Description    Emails a new verification link to the signed-in account. Earlier links stop working
+// @Success        200 "verification email sent"
+// @Failure        409 "email is already verified"
+// @Router         /auth/verify-email/resend [post]
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+func (h *AccountHandlers) ResendVerification(ctx *gin.Context) {
+	account, err := middleware.GetAccount(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	if account.IsVerified() {
+		ctx.Error(errAlreadyVerified)
+		return
+	}
+	if err := h.sendVerificationEmail(ctx, account); err != nil {
+		ctx.Error(fmt.Errorf("unable to send verification email: %w", err))
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "verification email sent"})
+}
+
+// @Tags		   Auth
+// @Summary		   Forgot Password
+// @Description    Emails a password reset code, valid for an hour, if an account uses the email. The response is the same either way so it does not reveal which emails are registered
+// @Success        200 "if an account uses this email, a reset code has been sent"
+// @Param          ForgotPassword body models.ForgotPasswordRequest true "Forgot Password Request"
+// @Router         /auth/password/forgot [post]
+func (h *AccountHandlers) ForgotPassword(ctx *gin.Context) {
+	var req models.ForgotPasswordRequest
+	if !bindJSON(ctx, &req) {
+		return
+	}
+	// The lookup and email happen after the response so that how long it
+	// takes does not reveal whether the email is registered.
+	go h.forgotPassword(context.WithoutCancel(ctx.Request.Context()), req.Email)
+	ctx.JSON(http.StatusOK, gin.H{"message": "if an account uses this email, a reset code has been sent"})
+}
+
+// forgotPassword emails a password reset code to the account using email,
+// if there is one. Errors are only logged as there is no one to report them to.
+func (h *AccountHandlers) forgotPassword(ctx context.Context, email string) {
+	account, err := h.DbRepo.GetAccountByEmail(ctx, email)
+	switch {
+	case err == nil:
+		if err := h.sendPasswordResetEmail(ctx, account); err != nil {
+			log.Println("unable to send password reset email", err)
+		}
+	case !errors.Is(err, repository.ErrAccountNotFound):
+		log.Println("unable to look up account for password reset", err)
+	}
+}
+
+// @Tags		   Auth
+// @Summary		   Reset Password
+// @Description    Sets a new password with the emailed reset code. Each code works once, and every session of the account is signed out
+// @Success        200 "password reset"
+// @Failure        400 "token is invalid, expired or already used"
+// @Param          ResetPassword body models.ResetPasswordRequest true "Reset Password Request"
+// @Router         /auth/password/reset [post]
+func (h *AccountHandlers) ResetPassword(ctx *gin.Context) {
+	var req models.ResetPasswordRequest
+	if !bindJSON(ctx, &req) {
+		return
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	now := time.Now()
+	account, err := h.DbRepo.ResetPassword(ctx, middleware.HashToken(req.Token), string(hashedPassword), now)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	revoked, err := h.Tokens.RevokeAccountTokens(ctx, account.ID, now)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	h.Auth.Denylist.Add(revoked...)
+	ctx.JSON(http.StatusOK, gin.H{"message": "password reset, sign in with the new password"})
+}
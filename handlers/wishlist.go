@@ -0,0 +1,309 @@
+package handlers
+
+import (
+	"airbnb/middleware"
+	"airbnb/models"
+	"airbnb/repository"
+	"airbnb/storage"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// WishlistHandlers manages guests' wishlists. Blobs holds the saved
+// properties' photos and BaseURL is the public URL of the API, used to build
+// share links.
+type WishlistHandlers struct {
+	DbRepo  *repository.WishlistRepo
+	Blobs   storage.BlobStore
+	BaseURL string
+}
+
+func NewWishlistHandlers(repo *repository.WishlistRepo, blobs storage.BlobStore, baseURL string) *WishlistHandlers {
+	return &WishlistHandlers{
+		DbRepo:  repo,
+		Blobs:   blobs,
+		BaseURL: baseURL,
+	}
+}
+
+// @Tags		   Wishlists
+// @Summary		   Create Wishlist
+// @Description    A guest creates a named list to save properties to
+// @Success        200 {object} models.WishlistSummary
+// @Failure        400 {object} models.Problem
+// @Param          Wishlist body models.CreateWishlist true "Create Wishlist Request"
+// @Router         /user/wishlists [post]
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+func (h *WishlistHandlers) CreateWishlist(ctx *gin.Context) {
+	var req models.CreateWishlist
+	if !bindJSON(ctx, &req) {
+		return
+	}
+	guest, err := middleware.GetAccount(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	wishlist := models.Wishlist{GuestID: guest.ID, Name: req.Name}
+	if err := h.DbRepo.CreateWishlist(ctx, &wishlist); err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, models.WishlistSummary{
+		WishlistID: wishlist.ID,
+		Name:       wishlist.Name,
+		CreatedAt:  wishlist.CreatedAt,
+	})
+}
+
+// @Tags		   Wishlists
+// @Summary		   List Wishlists
+// @Description    A guest lists their wishlists, newest first
+// @Success        200 {object} []models.WishlistSummary
+// @Router         /user/wishlists [get]
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+func (h *WishlistHandlers) ListWishlists(ctx *gin.Context) {
+	guest, err := middleware.GetAccount(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	wishlists, err := h.DbRepo.ListWishlists(ctx, guest.ID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, wishlists)
+}
+
+// @Tags		   Wishlists
+// @Summary		   Get Wishlist
+// @Description    A guest gets one of their wishlists. Given check_in and check_out, each property shows its current price for the stay and whether it is free
+// @Success        200 {object} models.GetWishlist
+// @Failure        400 {object} models.Problem
+// @Failure        404 "wishlist not found"
+// @Param          wishlistid path string true "ID"
+// @Param          check_in query string false "Check-in date" example(2025-01-10)
+// @Param          check_out query string false "Check-out date" example(2025-01-15)
+// @Param          guests query int false "Number of guests (default 1)"
+// @Router         /user/wishlists/{wishlistid} [get]
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+func (h *WishlistHandlers) GetWishlist(ctx *gin.Context) {
+	wishlistID, err := uuid.Parse(ctx.Param("wishlistid"))
+	if err != nil {
+		ctx.Error(errInvalidWishlistID)
+		return
+	}
+	dates, ok := bindWishlistDates(ctx)
+	if !ok {
+		return
+	}
+	guest, err := middleware.GetAccount(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	wishlist, err := h.DbRepo.GetWishlist(ctx, guest.ID, wishlistID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	h.respond(ctx, wishlist, dates)
+}
+
+// @Tags		   Wishlists
+// @Summary		   Shared Wishlist
+// @Description    Anyone with a wishlist's share link can read it, with prices and availability as in Get Wishlist
+// @Success        200 {object} models.GetWishlist
+// @Failure        400 {object} models.Problem
+// @Failure        404 "wishlist not found"
+// @Param          token path string true "Token from the share link"
+// @Param          check_in query string false "Check-in date" example(2025-01-10)
+// @Param          check_out query string false "Check-out date" example(2025-01-15)
+// @Param          guests query int false "Number of guests (default 1)"
+// @Router         /wishlists/shared/{token} [get]
+func (h *WishlistHandlers) GetSharedWishlist(ctx *gin.Context) {
+	dates, ok := bindWishlistDates(ctx)
+	if !ok {
+		return
+	}
+	wishlist, err := h.DbRepo.GetSharedWishlist(ctx, middleware.HashToken(ctx.Param("token")))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	h.respond(ctx, wishlist, dates)
+}
+
+func bindWishlistDates(ctx *gin.Context) (models.WishlistDates, bool) {
+	var dates models.WishlistDates
+	if !bindQuery(ctx, &dates) {
+		return dates, false
+	}
+	if err := dates.Normalize(time.Now()); err != nil {
+		ctx.Error(invalidInput("invalid_dates", err))
+		return dates, false
+	}
+	return dates, true
+}
+
+// respond writes a wishlist's properties, pricing and checking each for the
+// stay when dates has one.
+func (h *WishlistHandlers) respond(ctx *gin.Context, wishlist *models.Wishlist, dates models.WishlistDates) {
+	now := time.Now()
+	unavailable := map[uuid.UUID]bool{}
+	if dates.HasDates() {
+		ids := make([]uuid.UUID, 0, len(wishlist.Items))
+		for _, item := range wishlist.Items {
+			ids = append(ids, item.PropertyID)
+		}
+		var err error
+		if unavailable, err = h.DbRepo.UnavailableProperties(ctx, ids, dates.From, dates.To); err != nil {
+			ctx.Error(err)
+			return
+		}
+	}
+
+	response := models.GetWishlist{
+		WishlistID: wishlist.ID,
+		Name:       wishlist.Name,
+		Shared:     wishlist.ShareTokenHash != nil,
+		Properties: []models.WishlistProperty{},
+	}
+	for _, item := range wishlist.Items {
+		saved := models.WishlistProperty{Property: newGetProperty(&item.Property, h.Blobs), SavedAt: item.CreatedAt}
+		if dates.HasDates() {
+			available := !unavailable[item.PropertyID] && dates.Fits(&item.Property, now)
+			quote := models.NewQuote(&item.Property, dates.From, dates.To, dates.Guests)
+			saved.Available, saved.Quote = &available, &quote
+		}
+		response.Properties = append(response.Properties, saved)
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+// @Tags		   Wishlists
+// @Summary		   Delete Wishlist
+// @Description    A guest deletes one of their wishlists. Its share link stops working
+// @Success        200 "wishlist deleted"
+// @Failure        404 "wishlist not found"
+// @Param          wishlistid path string true "ID"
+// @Router         /user/wishlists/{wishlistid} [delete]
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+func (h *WishlistHandlers) DeleteWishlist(ctx *gin.Context) {
+	h.update(ctx, "wishlist deleted", func(guestID, wishlistID uuid.UUID) error {
+		return h.DbRepo.DeleteWishlist(ctx, guestID, wishlistID)
+	})
+}
+
+// @Tags		   Wishlists
+// @Summary		   Save Property
+// @Description    A guest saves a property to one of their wishlists. Saving it twice has no effect
+// @Success        200 "property saved"
+// @Failure        404 "wishlist or property not found"
+// @Failure        409 "a wishlist can hold up to 100 properties"
+// @Param          wishlistid path string true "ID"
+// @Param          propertyid path string true "ID"
+// @Router         /user/wishlists/{wishlistid}/properties/{propertyid} [put]
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+func (h *WishlistHandlers) SaveProperty(ctx *gin.Context) {
+	propertyID, err := uuid.Parse(ctx.Param("propertyid"))
+	if err != nil {
+		ctx.Error(middleware.ErrInvalidPropertyID)
+		return
+	}
+	h.update(ctx, "property saved", func(guestID, wishlistID uuid.UUID) error {
+		return h.DbRepo.SaveProperty(ctx, guestID, wishlistID, propertyID)
+	})
+}
+
+// @Tags		   Wishlists
+// @Summary		   Remove Property
+// @Description    A guest takes a property off one of their wishlists
+// @Success        200 "property removed"
+// @Failure        404 "wishlist not found"
+// @Param          wishlistid path string true "ID"
+// @Param          propertyid path string true "ID"
+// @Router         /user/wishlists/{wishlistid}/properties/{propertyid} [delete]
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+func (h *WishlistHandlers) RemoveProperty(ctx *gin.Context) {
+	propertyID, err := uuid.Parse(ctx.Param("propertyid"))
+	if err != nil {
+		ctx.Error(middleware.ErrInvalidPropertyID)
+		return
+	}
+	h.update(ctx, "property removed", func(guestID, wishlistID uuid.UUID) error {
+		return h.DbRepo.RemoveProperty(ctx, guestID, wishlistID, propertyID)
+	})
+}
+
+// @Tags		   Wishlists
+// @Summary		   Stop Sharing Wishlist
+// @Description    A guest turns off a wishlist's share link
+// @Success        200 "wishlist no longer shared"
+// @Failure        404 "wishlist not found"
+// @Param          wishlistid path string true "ID"
+// @Router         /user/wishlists/{wishlistid}/share [delete]
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+func (h *WishlistHandlers) UnshareWishlist(ctx *gin.Context) {
+	h.update(ctx, "wishlist no longer shared", func(guestID, wishlistID uuid.UUID) error {
+		return h.DbRepo.ShareWishlist(ctx, guestID, wishlistID, nil)
+	})
+}
+
+// update parses the wishlist ID, applies a change to the signed-in guest's
+// wishlist and responds with message.
+func (h *WishlistHandlers) update(ctx *gin.Context, message string, apply func(guestID, wishlistID uuid.UUID) error) {
+	wishlistID, err := uuid.Parse(ctx.Param("wishlistid"))
+	if err != nil {
+		ctx.Error(errInvalidWishlistID)
+		return
+	}
+	guest, err := middleware.GetAccount(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	if err := apply(guest.ID, wishlistID); err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": message})
+}
+
+// @Tags		   Wishlists
+// @Summary		   Share Wishlist
+// @Description    A guest gets a read-only link to one of their wishlists. The link is only shown once; sharing again replaces it, and the old link stops working
+// @Success        200 {object} models.WishlistShare
+// @Failure        404 "wishlist not found"
+// @Param          wishlistid path string true "ID"
+// @Router         /user/wishlists/{wishlistid}/share [post]
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+func (h *WishlistHandlers) ShareWishlist(ctx *gin.Context) {
+	wishlistID, err := uuid.Parse(ctx.Param("wishlistid"))
+	if err != nil {
+		ctx.Error(errInvalidWishlistID)
+		return
+	}
+	guest, err := middleware.GetAccount(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	token, hash, err := middleware.GenerateToken()
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	if err := h.DbRepo.ShareWishlist(ctx, guest.ID, wishlistID, &hash); err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, models.WishlistShare{
+		WishlistID: wishlistID,
+		ShareURL:   h.BaseURL + "/wishlists/shared/" + token,
+	})
+}
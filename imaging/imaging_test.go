@@ -0,0 +1,228 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+// labelled returns a w by h image whose pixel at (x, y) has red value
+// y*w+x, so a test can tell where each pixel ended up.
+func labelled(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		for x := range w {
+			img.Set(x, y, color.RGBA{R: uint8(y*w + x), A: 255})
+		}
+	}
+	return img
+}
+
+// labels reads back the red values of img row by row.
+func labels(img *image.RGBA) [][]uint8 {
+	var rows [][]uint8
+	for y := range img.Bounds().Dy() {
+		var row []uint8
+		for x := range img.Bounds().Dx() {
+			row = append(row, img.RGBAAt(x, y).R)
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// withExif inserts an APP1 EXIF segment holding only the orientation tag
+// right after the JPEG's start-of-image marker.
+func withExif(t *testing.T, jpg []byte, order binary.ByteOrder, orientation uint16) []byte {
+	t.Helper()
+	tiff := make([]byte, 26)
+	if order == binary.LittleEndian {
+		copy(tiff, "II")
+	} else {
+		copy(tiff, "MM")
+	}
+	order.PutUint16(tiff[2:], 42)
+	order.PutUint32(tiff[4:], 8)
+	order.PutUint16(tiff[8:], 1)       // one entry
+	order.PutUint16(tiff[10:], 0x0112) // orientation
+	order.PutUint16(tiff[12:], 3)      // SHORT
+	order.PutUint32(tiff[14:], 1)
+	order.PutUint16(tiff[18:], orientation)
+
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	app1 := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(app1[2:], uint16(len(segment)+2))
+	app1 = append(app1, segment...)
+	if len(jpg) < 2 || jpg[0] != 0xFF || jpg[1] != 0xD8 {
+		t.Fatal("not a JPEG")
+	}
+	return append(append(append([]byte{}, jpg[:2]...), app1...), jpg[2:]...)
+}
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	data, err := EncodeJPEG(img)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	return data
+}
+
+func TestExifOrientation(t *testing.T) {
+	jpg := encodeJPEG(t, labelled(4, 2))
+	for _, tc := range []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{"no exif", jpg, 1},
+		{"little endian", withExif(t, jpg, binary.LittleEndian, 6), 6},
+		{"big endian", withExif(t, jpg, binary.BigEndian, 8), 8},
+		{"not a jpeg", []byte("\x89PNG\r\n\x1a\n"), 1},
+		{"truncated", withExif(t, jpg, binary.LittleEndian, 3)[:20], 1},
+		{"empty", nil, 1},
+	} {
+		if got := exifOrientation(tc.data); got != tc.want {
+			t.Errorf("%s: orientation = %d, want %d", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestOrient(t *testing.T) {
+	// The stored pixels are
+	//   0 1 2
+	//   3 4 5
+	for _, tc := range []struct {
+		orientation int
+		want        [][]uint8
+	}{
+		{1, [][]uint8{{0, 1, 2}, {3, 4, 5}}},
+		{2, [][]uint8{{2, 1, 0}, {5, 4, 3}}},
+		{3, [][]uint8{{5, 4, 3}, {2, 1, 0}}},
+		{4, [][]uint8{{3, 4, 5}, {0, 1, 2}}},
+		{5, [][]uint8{{0, 3}, {1, 4}, {2, 5}}},
+		{6, [][]uint8{{3, 0}, {4, 1}, {5, 2}}},
+		{7, [][]uint8{{5, 2}, {4, 1}, {3, 0}}},
+		{8, [][]uint8{{2, 5}, {1, 4}, {0, 3}}},
+		{9, [][]uint8{{0, 1, 2}, {3, 4, 5}}},
+	} {
+		got := labels(orient(labelled(3, 2), tc.orientation))
+		if !equalRows(got, tc.want) {
+			t.Errorf("orientation %d = %v, want %v", tc.orientation, got, tc.want)
+		}
+	}
+}
+
+func equalRows(a, b [][]uint8) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !bytes.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestFit(t *testing.T) {
+	for _, tc := range []struct {
+		w, h, maxEdge int
+		wantW, wantH  int
+	}{
+		{400, 200, 100, 100, 50},
+		{200, 400, 100, 50, 100},
+		{1000, 1, 100, 100, 1},
+		{80, 60, 100, 80, 60},
+		{100, 100, 100, 100, 100},
+	} {
+		got := Fit(image.NewRGBA(image.Rect(0, 0, tc.w, tc.h)), tc.maxEdge)
+		if got.Bounds().Dx() != tc.wantW || got.Bounds().Dy() != tc.wantH {
+			t.Errorf("Fit %dx%d to %d = %v, want %dx%d", tc.w, tc.h, tc.maxEdge, got.Bounds(), tc.wantW, tc.wantH)
+		}
+	}
+
+	small := labelled(3, 2)
+	if Fit(small, 10) != small {
+		t.Error("Fit copied an image already within bounds")
+	}
+
+	// Each output pixel averages a black and white checkerboard.
+	checker := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for y := range 4 {
+		for x := range 4 {
+			if (x+y)%2 == 0 {
+				checker.Set(x, y, color.White)
+			} else {
+				checker.Set(x, y, color.Black)
+			}
+		}
+	}
+	got := Fit(checker, 2)
+	for y := range 2 {
+		for x := range 2 {
+			if c := got.RGBAAt(x, y); c != (color.RGBA{128, 128, 128, 255}) {
+				t.Fatalf("pixel (%d, %d) = %v, want mid grey", x, y, c)
+			}
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	rotated, err := Decode(withExif(t, encodeJPEG(t, labelled(4, 2)), binary.BigEndian, 6))
+	if err != nil {
+		t.Fatalf("decode rotated JPEG: %v", err)
+	}
+	if rotated.Bounds().Dx() != 2 || rotated.Bounds().Dy() != 4 {
+		t.Fatalf("rotated JPEG is %v, want 2x4", rotated.Bounds())
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, 3, 2))); err != nil {
+		t.Fatalf("encode PNG: %v", err)
+	}
+	// A transparent PNG comes out on white.
+	flat, err := Decode(buf.Bytes())
+	if err != nil {
+		t.Fatalf("decode PNG: %v", err)
+	}
+	if c := flat.RGBAAt(0, 0); c != (color.RGBA{255, 255, 255, 255}) {
+		t.Fatalf("transparent pixel = %v, want white", c)
+	}
+
+	for _, tc := range []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"text", []byte("hello, world"), ErrUnsupportedFormat},
+		{"corrupt JPEG", []byte("\xFF\xD8\xFF\xE0 not really a jpeg"), ErrInvalidImage},
+		{"huge PNG", resizePNG(t, buf.Bytes(), 10_000, MaxPixels/10_000+1), ErrTooManyPixels},
+		{"zero width PNG", resizePNG(t, buf.Bytes(), 0, 2), ErrInvalidImage},
+	} {
+		if _, err := Decode(tc.data); !errors.Is(err, tc.want) {
+			t.Errorf("%s: err = %v, want %v", tc.name, err, tc.want)
+		}
+	}
+}
+
+// resizePNG rewrites the dimensions in a PNG's header, leaving the pixel
+// data alone, so the header claims a size the file does not hold.
+func resizePNG(t *testing.T, data []byte, w, h uint32) []byte {
+	t.Helper()
+	// The signature is 8 bytes; IHDR follows with its length, type, then
+	// width and height.
+	if len(data) < 33 || string(data[12:16]) != "IHDR" {
+		t.Fatal("not a PNG")
+	}
+	out := append([]byte{}, data...)
+	binary.BigEndian.PutUint32(out[16:], w)
+	binary.BigEndian.PutUint32(out[20:], h)
+	binary.BigEndian.PutUint32(out[29:], crc32.ChecksumIEEE(out[12:29]))
+	return out
+}
@@ -3,10 +3,14 @@ package middleware
 import (
 	"airbnb/models"
 	"airbnb/repository"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"fmt"
-	"log"
 	"net/http"
-	"os"
+	"slices"
 	"strings"
 	"time"
 
@@ -15,178 +19,203 @@ import (
 	"github.com/google/uuid"
 )
 
-type JwtUserClaims struct {
-	ID uuid.UUID `json:"id"`
+var (
+	ErrMissingToken     = NewError(http.StatusUnauthorized, "missing_token", "Authorization header is required")
+	ErrInvalidToken     = NewError(http.StatusUnauthorized, "invalid_token", "token is invalid or expired")
+	ErrTokenRevoked     = NewError(http.StatusUnauthorized, "token_revoked", "token has been revoked")
+	ErrEmailNotVerified = NewError(http.StatusForbidden, "email_not_verified", "verify your email address first")
+)
+
+// JwtClaims identifies the account and the roles it held when the token was
+// issued.
+type JwtClaims struct {
+	ID    uuid.UUID `json:"id"`
+	Roles []string  `json:"roles"`
 	jwt.StandardClaims
 }
 
-type JwtPropertyClaims struct {
-	ID uuid.UUID `json:"id"`
-	jwt.StandardClaims
+// Authenticator issues and verifies access tokens. Tokens are signed by Keys,
+// checked against Denylist and must belong to an account in Accounts.
+type Authenticator struct {
+	Accounts *repository.AccountRepo
+	Denylist *Denylist
+	Keys     *KeyManager
 }
 
-var SECRET_KEY = os.Getenv("SECRET_KEY")
+func NewAuthenticator(accounts *repository.AccountRepo, denylist *Denylist, keys *KeyManager) *Authenticator {
+	return &Authenticator{
+		Accounts: accounts,
+		Denylist: denylist,
+		Keys:     keys,
+	}
+}
 
-func GenerateUserToken(ID uuid.UUID) (string, error) {
-	claims := &JwtUserClaims{
-		ID: ID,
+// GenerateAccessToken signs a short-lived access token for the account. The
+// returned claims carry its jti and expiry for pairing with a refresh token.
+func (a *Authenticator) GenerateAccessToken(account *models.Account) (string, *JwtClaims, error) {
+	now := time.Now()
+	claims := &JwtClaims{
+		ID:    account.ID,
+		Roles: account.RoleNames(),
 		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: time.Now().Add(time.Hour * 24 * 30).Unix(),
-			IssuedAt:  time.Now().Unix(),
+			Id:        uuid.NewString(),
+			ExpiresAt: now.Add(models.AccessTokenTTL).Unix(),
+			IssuedAt:  now.Unix(),
 		},
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	t, err := token.SignedString([]byte(SECRET_KEY))
+	t, err := a.Keys.Sign(claims)
 	if err != nil {
-		return "", fmt.Errorf("unable to generate token %s", err)
+		return "", nil, fmt.Errorf("unable to generate token %s", err)
+	}
+	return t, claims, nil
+}
+
+// GenerateToken returns a random opaque token for the client, such as a
+// refresh token or an emailed link token, and the hash to store in its place.
+func GenerateToken() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("unable to generate token %s", err)
 	}
-	return t, nil
+	token := base64.RawURLEncoding.EncodeToString(b)
+	return token, HashToken(token), nil
+}
+
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
 
-func AuthUser(userRepo *repository.UserRepo) gin.HandlerFunc {
+// RequireRole authenticates the bearer token, rejecting revoked tokens and
+// suspended accounts, and aborts with 403 unless both the token and the
+// account still carry one of roles. With no roles any signed-in account is
+// let through. The account is stored under "account", the token's claims
+// under "claims" and the roles it may act with on this route under "roles",
+// in the order given.
+func (a *Authenticator) RequireRole(roles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			log.Println("Authorization header required")
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
-			c.Abort()
+		claims := &JwtClaims{}
+		if !parseToken(c, claims, a.Keys.Keyfunc) {
 			return
 		}
-		var tokenString string
-		if strings.HasPrefix(authHeader, "Bearer ") {
-			tokenString = strings.TrimSpace(strings.TrimPrefix(authHeader, "Bearer "))
-		} else {
-			tokenString = strings.TrimSpace(authHeader)
-		}
-
-		if tokenString == "" {
-			log.Println("Authorization header invalid")
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization header format"})
-			c.Abort()
+		if claims.Id == "" || a.Denylist.IsDenied(claims.Id) {
+			abort(c, ErrTokenRevoked)
 			return
 		}
-
-		token, err := jwt.ParseWithClaims(tokenString, &JwtUserClaims{}, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			return []byte(SECRET_KEY), nil
-		})
-
+		account, err := a.Accounts.GetAccountByID(c.Request.Context(), claims.ID)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
-			c.Abort()
+			if errors.Is(err, repository.ErrAccountNotFound) {
+				err = ErrInvalidToken
+			}
+			abort(c, err)
 			return
 		}
-
-		claims, ok := token.Claims.(*JwtUserClaims)
-		if !ok || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
-			c.Abort()
+		if account.IsSuspended() {
+			abort(c, repository.ErrAccountSuspended)
 			return
 		}
-		user, err := userRepo.GetUserByID(c.Request.Context(), claims.ID)
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Admin not found"})
-			c.Abort()
+
+		var granted []string
+		for _, role := range roles {
+			if slices.Contains(claims.Roles, role) && account.HasRole(role) {
+				granted = append(granted, role)
+			}
+		}
+		if len(roles) > 0 && len(granted) == 0 {
+			abort(c, NewError(http.StatusForbidden, "role_required", "this action requires the "+strings.Join(roles, " or ")+" role"))
 			return
 		}
-		c.Set("user", user)
+		c.Set("account", account)
+		c.Set("claims", claims)
+		c.Set("roles", granted)
 		c.Next()
 	}
 }
 
-func GetUser(ctx *gin.Context) (*models.User, error) {
-	user, exists := ctx.Get("user")
-	if !exists {
-		return nil, fmt.Errorf("user not found in context")
+// RequireVerifiedEmail aborts with 403 unless the authenticated account has
+// confirmed its email address. It must run after RequireRole.
+func RequireVerifiedEmail(c *gin.Context) {
+	account, err := GetAccount(c)
+	if err != nil {
+		abort(c, err)
+		return
 	}
-	User, ok := user.(*models.User)
-	if !ok {
-		return nil, fmt.Errorf("invalid user type in context")
+	if !account.IsVerified() {
+		abort(c, ErrEmailNotVerified)
+		return
 	}
-	return User, nil
+	c.Next()
 }
 
-func GeneratePropertyOwnerToken(ID uuid.UUID) (string, error) {
-	claims := &JwtPropertyClaims{
-		ID: ID,
-		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: time.Now().Add(time.Hour * 24 * 30).Unix(),
-			IssuedAt:  time.Now().Unix(),
-		},
+// parseToken validates the bearer token in the Authorization header into
+// claims, using keyfunc to find the verification key. On failure it records a
+// 401 error, aborts and returns false.
+func parseToken(c *gin.Context, claims jwt.Claims, keyfunc jwt.Keyfunc) bool {
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		abort(c, ErrMissingToken)
+		return false
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	t, err := token.SignedString([]byte(SECRET_KEY))
-	if err != nil {
-		return "", fmt.Errorf("unable to generate token %s", err)
+	var tokenString string
+	if strings.HasPrefix(authHeader, "Bearer ") {
+		tokenString = strings.TrimSpace(strings.TrimPrefix(authHeader, "Bearer "))
+	} else {
+		tokenString = strings.TrimSpace(authHeader)
 	}
-	return t, nil
-}
 
-func AuthPropertyOwner(propertyRepo *repository.PropertyRepo) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			log.Println("Authorization header required")
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
-			c.Abort()
-			return
-		}
-		var tokenString string
-		if strings.HasPrefix(authHeader, "Bearer ") {
-			tokenString = strings.TrimSpace(strings.TrimPrefix(authHeader, "Bearer "))
-		} else {
-			tokenString = strings.TrimSpace(authHeader)
-		}
-
-		if tokenString == "" {
-			log.Println("Authorization header invalid")
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization header format"})
-			c.Abort()
-			return
-		}
-
-		token, err := jwt.ParseWithClaims(tokenString, &JwtPropertyClaims{}, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			return []byte(SECRET_KEY), nil
-		})
+	if tokenString == "" {
+		abort(c, ErrMissingToken)
+		return false
+	}
 
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
-			c.Abort()
-			return
-		}
+	token, err := jwt.ParseWithClaims(tokenString, claims, keyfunc)
+	if err != nil || !token.Valid {
+		abort(c, ErrInvalidToken)
+		return false
+	}
+	return true
+}
 
-		claims, ok := token.Claims.(*JwtPropertyClaims)
-		if !ok || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
-			c.Abort()
-			return
-		}
-		property, err := propertyRepo.GetPropertyOwnerByID(c.Request.Context(), claims.ID)
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "property not found"})
-			c.Abort()
-			return
-		}
-		c.Set("owner", property)
-		c.Next()
+func GetAccount(ctx *gin.Context) (*models.Account, error) {
+	value, exists := ctx.Get("account")
+	if !exists {
+		return nil, fmt.Errorf("account not found in context")
+	}
+	account, ok := value.(*models.Account)
+	if !ok {
+		return nil, fmt.Errorf("invalid account type in context")
 	}
+	return account, nil
 }
 
-func GetPropertyOwner(ctx *gin.Context) (*models.PropertyOwner, error) {
-	propertyOwner, exists := ctx.Get("owner")
+func GetClaims(ctx *gin.Context) (*JwtClaims, error) {
+	value, exists := ctx.Get("claims")
 	if !exists {
-		return nil, fmt.Errorf("owner not found in context")
+		return nil, fmt.Errorf("claims not found in context")
 	}
-	owner, ok := propertyOwner.(*models.PropertyOwner)
+	claims, ok := value.(*JwtClaims)
 	if !ok {
-		return nil, fmt.Errorf("invalid owner type in context")
+		return nil, fmt.Errorf("invalid claims type in context")
+	}
+	return claims, nil
+}
+
+// GetRoles returns the roles RequireRole granted the account on this route.
+func GetRoles(ctx *gin.Context) []string {
+	roles, _ := ctx.Get("roles")
+	granted, _ := roles.([]string)
+	return granted
+}
+
+// GetActor returns the authenticated account as a booking actor acting with
+// role.
+func GetActor(ctx *gin.Context, role string) (models.Actor, error) {
+	account, err := GetAccount(ctx)
+	if err != nil {
+		return models.Actor{}, err
+	}
+	if !slices.Contains(GetRoles(ctx), role) {
+		return models.Actor{}, fmt.Errorf("account may not act as %s here", role)
 	}
-	return owner, nil
+	return models.NewActor(account.ID, role), nil
 }
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"airbnb/models"
+	"airbnb/repository"
+	"context"
+	"sync"
+	"time"
+)
+
+// Denylist holds the jtis of access tokens revoked before they expire.
+// RequireRole checks it on every request, so lookups are served from memory.
+// Revocations made by this instance are added straight away; Reload picks up
+// those made by other instances.
+type Denylist struct {
+	Repo *repository.TokenRepo
+	mu   sync.RWMutex
+	jtis map[string]time.Time
+}
+
+func NewDenylist(repo *repository.TokenRepo) *Denylist {
+	return &Denylist{Repo: repo, jtis: map[string]time.Time{}}
+}
+
+// Add denylists tokens in memory. They must already be stored through the
+// TokenRepo.
+func (d *Denylist) Add(tokens ...models.RevokedToken) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, token := range tokens {
+		d.jtis[token.JTI] = token.ExpiresAt
+	}
+}
+
+func (d *Denylist) IsDenied(jti string) bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	_, ok := d.jtis[jti]
+	return ok
+}
+
+// Reload adds the unexpired entries stored in the database and forgets the
+// ones that have expired, since the token itself is rejected by then.
+func (d *Denylist) Reload(ctx context.Context) error {
+	now := time.Now()
+	tokens, err := d.Repo.GetRevokedTokens(ctx, now)
+	if err != nil {
+		return err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, token := range tokens {
+		d.jtis[token.JTI] = token.ExpiresAt
+	}
+	for jti, expiresAt := range d.jtis {
+		if !expiresAt.After(now) {
+			delete(d.jtis, jti)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,274 @@
+package middleware
+
+import (
+	"airbnb/models"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// Algorithms a SigningKey may use.
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+	EdDSA = "EdDSA"
+)
+
+var (
+	ErrNoSigningKey = errors.New("no active signing key")
+	ErrUnknownKey   = errors.New("token signed with an unknown key")
+)
+
+// SigningKey is a key tokens are signed or verified with, identified by the
+// kid header. HS256 keys use Secret; RS256 and EdDSA keys use PrivateKey,
+// whose public half is published in the JWKS.
+type SigningKey struct {
+	ID         string
+	Algorithm  string
+	Secret     []byte
+	PrivateKey any // *rsa.PrivateKey or ed25519.PrivateKey
+}
+
+func (k SigningKey) method() jwt.SigningMethod {
+	switch k.Algorithm {
+	case HS256:
+		return jwt.SigningMethodHS256
+	case RS256:
+		return jwt.SigningMethodRS256
+	case EdDSA:
+		return jwt.SigningMethodEdDSA
+	}
+	return nil
+}
+
+func (k SigningKey) validate() error {
+	if k.ID == "" {
+		return errors.New("signing key needs a kid")
+	}
+	ok := false
+	switch k.Algorithm {
+	case HS256:
+		ok = len(k.Secret) > 0
+	case RS256:
+		_, ok = k.PrivateKey.(*rsa.PrivateKey)
+	case EdDSA:
+		_, ok = k.PrivateKey.(ed25519.PrivateKey)
+	default:
+		return fmt.Errorf("signing key %s: unsupported algorithm %q", k.ID, k.Algorithm)
+	}
+	if !ok {
+		return fmt.Errorf("signing key %s: missing or invalid %s key material", k.ID, k.Algorithm)
+	}
+	return nil
+}
+
+func (k SigningKey) signingKey() any {
+	if k.Algorithm == HS256 {
+		return k.Secret
+	}
+	return k.PrivateKey
+}
+
+func (k SigningKey) verificationKey() any {
+	switch key := k.PrivateKey.(type) {
+	case *rsa.PrivateKey:
+		return &key.PublicKey
+	case ed25519.PrivateKey:
+		return key.Public()
+	}
+	return k.Secret
+}
+
+// KeyManager signs tokens with the active key and verifies them with any key
+// it knows. A key that is dropped from the key set is still accepted for
+// verification for the retention period, so tokens signed with it stay valid
+// until they expire.
+type KeyManager struct {
+	retention time.Duration
+	mu        sync.RWMutex
+	activeID  string
+	keys      map[string]SigningKey
+	retired   map[string]time.Time // kid -> when it stops verifying
+}
+
+func NewKeyManager(retention time.Duration, active string, keys ...SigningKey) (*KeyManager, error) {
+	m := &KeyManager{retention: retention, keys: map[string]SigningKey{}, retired: map[string]time.Time{}}
+	if err := m.SetKeys(active, keys, time.Now()); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// SetKeys replaces the key set and the key new tokens are signed with. Keys
+// missing from the new set keep verifying for the retention period.
+func (m *KeyManager) SetKeys(active string, keys []SigningKey, now time.Time) error {
+	next := make(map[string]SigningKey, len(keys))
+	for _, key := range keys {
+		if err := key.validate(); err != nil {
+			return err
+		}
+		if _, dup := next[key.ID]; dup {
+			return fmt.Errorf("signing key %s is listed twice", key.ID)
+		}
+		next[key.ID] = key
+	}
+	if _, ok := next[active]; !ok {
+		return fmt.Errorf("active signing key %q is not in the key set", active)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, key := range m.keys {
+		if _, kept := next[id]; kept {
+			delete(m.retired, id)
+			continue
+		}
+		if _, ok := m.retired[id]; !ok {
+			m.retired[id] = now.Add(m.retention)
+		}
+		next[id] = key
+	}
+	for id, until := range m.retired {
+		if !until.After(now) {
+			delete(next, id)
+			delete(m.retired, id)
+		}
+	}
+	m.keys = next
+	m.activeID = active
+	return nil
+}
+
+// Sign signs claims with the active key and names it in the kid header.
+func (m *KeyManager) Sign(claims jwt.Claims) (string, error) {
+	m.mu.RLock()
+	key, ok := m.keys[m.activeID]
+	m.mu.RUnlock()
+	if !ok {
+		return "", ErrNoSigningKey
+	}
+	token := jwt.NewWithClaims(key.method(), claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.signingKey())
+}
+
+// Keyfunc returns the verification key named by the token's kid header. The
+// token must use that key's algorithm, so an RS256 public key can never be
+// used as an HS256 secret.
+func (m *KeyManager) Keyfunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	m.mu.RLock()
+	key, ok := m.keys[kid]
+	if ok {
+		if until, retired := m.retired[kid]; retired && !until.After(time.Now()) {
+			ok = false
+		}
+	}
+	m.mu.RUnlock()
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	if token.Method.Alg() != key.Algorithm {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return key.verificationKey(), nil
+}
+
+// JWKS returns the public keys tokens may currently be verified with. HS256
+// secrets are never published.
+func (m *KeyManager) JWKS() models.JWKS {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	jwks := models.JWKS{Keys: []models.JWK{}}
+	for _, key := range m.keys {
+		switch public := key.verificationKey().(type) {
+		case *rsa.PublicKey:
+			jwks.Keys = append(jwks.Keys, models.JWK{
+				Kty: "RSA",
+				Kid: key.ID,
+				Use: "sig",
+				Alg: RS256,
+				N:   base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			jwks.Keys = append(jwks.Keys, models.JWK{
+				Kty: "OKP",
+				Kid: key.ID,
+				Use: "sig",
+				Alg: EdDSA,
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(public),
+			})
+		}
+	}
+	sort.Slice(jwks.Keys, func(i, j int) bool {
+		return jwks.Keys[i].Kid < jwks.Keys[j].Kid
+	})
+	return jwks
+}
+
+// keyFile is the JSON key set named by JWT_KEYS_FILE, e.g.
+//
+//	{"active": "2025-06", "keys": [
+//	  {"kid": "2025-06", "alg": "EdDSA", "private_key_file": "/run/secrets/jwt-2025-06.pem"},
+//	  {"kid": "2025-01", "alg": "RS256", "private_key_file": "/run/secrets/jwt-2025-01.pem"}
+//	]}
+//
+// HS256 keys give a secret instead of a PEM private key.
+type keyFile struct {
+	Active string `json:"active"`
+	Keys   []struct {
+		ID             string `json:"kid"`
+		Algorithm      string `json:"alg"`
+		Secret         string `json:"secret"`
+		PrivateKey     string `json:"private_key"`
+		PrivateKeyFile string `json:"private_key_file"`
+	} `json:"keys"`
+}
+
+// LoadKeyFile reads a key set from the JSON file at path and returns the
+// active kid and the keys.
+func LoadKeyFile(path string) (string, []SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", nil, err
+	}
+	var file keyFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return "", nil, fmt.Errorf("%s: %w", path, err)
+	}
+	var keys []SigningKey
+	for _, entry := range file.Keys {
+		key := SigningKey{ID: entry.ID, Algorithm: entry.Algorithm}
+		pem := []byte(entry.PrivateKey)
+		if entry.PrivateKeyFile != "" {
+			if pem, err = os.ReadFile(entry.PrivateKeyFile); err != nil {
+				return "", nil, fmt.Errorf("signing key %s: %w", entry.ID, err)
+			}
+		}
+		switch entry.Algorithm {
+		case HS256:
+			key.Secret = []byte(entry.Secret)
+		case RS256:
+			key.PrivateKey, err = jwt.ParseRSAPrivateKeyFromPEM(pem)
+		case EdDSA:
+			key.PrivateKey, err = jwt.ParseEdPrivateKeyFromPEM(pem)
+		}
+		if err != nil {
+			return "", nil, fmt.Errorf("signing key %s: %w", entry.ID, err)
+		}
+		keys = append(keys, key)
+	}
+	return file.Active, keys, nil
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"airbnb/models"
+	"airbnb/repository"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+var ErrInvalidPropertyID = NewError(http.StatusBadRequest, "invalid_property_id", "invalid property ID")
+
+// OwnedProperty loads the property named by the :propertyid route parameter and
+// aborts with 404 unless it belongs to the authenticated host, so other hosts
+// cannot tell whether it exists. It must run after RequireRole.
+func OwnedProperty(propertyRepo *repository.PropertyRepo) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		propertyID, err := uuid.Parse(c.Param("propertyid"))
+		if err != nil {
+			abort(c, ErrInvalidPropertyID)
+			return
+		}
+		owner, err := GetAccount(c)
+		if err != nil {
+			abort(c, err)
+			return
+		}
+		property, err := propertyRepo.GetOwnedPropertyByID(c.Request.Context(), owner.ID, propertyID)
+		if err != nil {
+			abort(c, err)
+			return
+		}
+		c.Set("property", property)
+		c.Next()
+	}
+}
+
+func GetOwnedProperty(ctx *gin.Context) (*models.Property, error) {
+	value, exists := ctx.Get("property")
+	if !exists {
+		return nil, fmt.Errorf("property not found in context")
+	}
+	property, ok := value.(*models.Property)
+	if !ok {
+		return nil, fmt.Errorf("invalid property type in context")
+	}
+	return property, nil
+}
@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"airbnb/models"
+	"airbnb/repository"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const problemContentType = "application/problem+json"
+
+// ValidationError is a request whose fields failed validation.
+type ValidationError struct {
+	Fields []models.FieldError
+}
+
+func (e *ValidationError) Error() string {
+	return "request validation failed"
+}
+
+// Error is a problem with the request itself, such as a malformed ID or a
+// missing token, as opposed to the repository errors about the data it
+// refers to. It is reported with its own status and code.
+type Error struct {
+	Status  int
+	Code    string
+	Message string
+}
+
+// NewError returns an Error reported with the given status and code.
+func NewError(status int, code, message string) *Error {
+	return &Error{Status: status, Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// kinds maps each repository error kind to its status and the code used when
+// the error has no code of its own.
+var kinds = []struct {
+	kind   error
+	status int
+	code   string
+}{
+	{repository.ErrNotFound, http.StatusNotFound, "not_found"},
+	{repository.ErrConflict, http.StatusConflict, "conflict"},
+	{repository.ErrForbidden, http.StatusForbidden, "forbidden"},
+	{repository.ErrInvalid, http.StatusBadRequest, "invalid_request"},
+	{repository.ErrUnauthorized, http.StatusUnauthorized, "unauthorized"},
+	{repository.ErrTooLarge, http.StatusRequestEntityTooLarge, "too_large"},
+	{repository.ErrUnsupported, http.StatusUnsupportedMediaType, "unsupported_media_type"},
+}
+
+// Problems writes the error a handler or middleware recorded with c.Error as
+// an RFC 7807 problem details response, unless a response was already
+// written. Register it before every other middleware so it sees their errors.
+func Problems() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		problem := NewProblem(c.Errors.Last().Err)
+		c.Header("Content-Type", problemContentType)
+		c.JSON(problem.Status, problem)
+	}
+}
+
+var ErrRouteNotFound = NewError(http.StatusNotFound, "route_not_found", "no route matches the request")
+
+// RouteNotFound answers requests that match no route.
+func RouteNotFound(c *gin.Context) {
+	c.Error(ErrRouteNotFound)
+}
+
+// abort records err for Problems and stops the handler chain.
+func abort(c *gin.Context, err error) {
+	c.Error(err)
+	c.Abort()
+}
+
+// NewProblem describes err for a client. Errors of an unknown kind are
+// reported as an internal error without their message, which may contain
+// database details, and logged instead.
+func NewProblem(err error) models.Problem {
+	var requestErr *Error
+	if errors.As(err, &requestErr) {
+		return newProblem(requestErr.Status, requestErr.Code, requestErr.Message, nil)
+	}
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		return newProblem(http.StatusBadRequest, "validation_failed", err.Error(), validationErr.Fields)
+	}
+	var stayErr *models.StayError
+	if errors.As(err, &stayErr) {
+		return newProblem(http.StatusBadRequest, "stay_not_allowed", err.Error(), stayErr.Violations)
+	}
+	var transitionErr *models.TransitionError
+	if errors.As(err, &transitionErr) {
+		return newProblem(http.StatusConflict, "invalid_transition", err.Error(), nil)
+	}
+	for _, k := range kinds {
+		if !errors.Is(err, k.kind) {
+			continue
+		}
+		code := k.code
+		var repoErr *repository.Error
+		if errors.As(err, &repoErr) {
+			code = repoErr.Code
+		}
+		return newProblem(k.status, code, err.Error(), nil)
+	}
+	log.Println("internal error:", err)
+	return newProblem(http.StatusInternalServerError, "internal_error", "an unexpected error occurred", nil)
+}
+
+func newProblem(status int, code, detail string, fields []models.FieldError) models.Problem {
+	return models.Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+		Errors: fields,
+	}
+}
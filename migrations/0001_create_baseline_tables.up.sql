@@ -0,0 +1,53 @@
+-- The tables as the first release created them through GORM AutoMigrate.
+-- IF NOT EXISTS lets databases created that way adopt versioned migrations.
+CREATE TABLE IF NOT EXISTS users (
+    id uuid PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    name varchar(100) NOT NULL,
+    email varchar(100) NOT NULL,
+    password varchar(255) NOT NULL,
+    role varchar(100) NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+
+CREATE TABLE IF NOT EXISTS property_owners (
+    id uuid PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    name varchar(100) NOT NULL,
+    email varchar(100) NOT NULL,
+    password varchar(255) NOT NULL,
+    role varchar(100) NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_property_owners_email ON property_owners (email);
+CREATE INDEX IF NOT EXISTS idx_property_owners_deleted_at ON property_owners (deleted_at);
+
+CREATE TABLE IF NOT EXISTS properties (
+    id uuid PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    name varchar(100) NOT NULL,
+    description varchar(500),
+    price bigint NOT NULL,
+    location text NOT NULL,
+    owner_id uuid NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_properties_deleted_at ON properties (deleted_at);
+
+CREATE TABLE IF NOT EXISTS bookings (
+    id uuid PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    user_id uuid NOT NULL,
+    property_id uuid NOT NULL,
+    check_in text DEFAULT NULL,
+    check_out text DEFAULT NULL,
+    status varchar(50) NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_bookings_deleted_at ON bookings (deleted_at);
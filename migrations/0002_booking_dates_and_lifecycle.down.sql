@@ -0,0 +1,22 @@
+DROP TABLE IF EXISTS booking_transitions;
+DROP TABLE IF EXISTS blocked_dates;
+
+ALTER TABLE bookings
+    DROP COLUMN cancelled_at,
+    DROP COLUMN cancelled_by_role,
+    DROP COLUMN cancelled_by_id,
+    DROP COLUMN cancellation_reason,
+    DROP COLUMN refund_amount,
+    DROP COLUMN total_price,
+    DROP COLUMN tax,
+    DROP COLUMN service_fee,
+    DROP COLUMN cleaning_fee,
+    DROP COLUMN nightly_total,
+    DROP COLUMN currency,
+    DROP COLUMN guests,
+    ALTER COLUMN check_in DROP NOT NULL,
+    ALTER COLUMN check_in TYPE text USING to_char(check_in, 'YYYY-MM-DD'),
+    ALTER COLUMN check_in SET DEFAULT NULL,
+    ALTER COLUMN check_out DROP NOT NULL,
+    ALTER COLUMN check_out TYPE text USING to_char(check_out, 'YYYY-MM-DD'),
+    ALTER COLUMN check_out SET DEFAULT NULL;
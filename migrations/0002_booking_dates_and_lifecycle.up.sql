@@ -0,0 +1,58 @@
+-- Bookings made before dates were required have none. Give them a one-night
+-- stay on the day they were made so the columns can become NOT NULL dates.
+UPDATE bookings SET check_in = to_char(created_at, 'YYYY-MM-DD')
+WHERE check_in IS NULL OR check_in = '';
+UPDATE bookings SET check_out = to_char(check_in::date + 1, 'YYYY-MM-DD')
+WHERE check_out IS NULL OR check_out = '';
+
+ALTER TABLE bookings
+    ALTER COLUMN check_in DROP DEFAULT,
+    ALTER COLUMN check_in TYPE date USING check_in::date,
+    ALTER COLUMN check_in SET NOT NULL,
+    ALTER COLUMN check_out DROP DEFAULT,
+    ALTER COLUMN check_out TYPE date USING check_out::date,
+    ALTER COLUMN check_out SET NOT NULL,
+    ADD COLUMN guests bigint NOT NULL DEFAULT 1,
+    ADD COLUMN currency varchar(3) NOT NULL DEFAULT 'USD',
+    ADD COLUMN nightly_total bigint NOT NULL DEFAULT 0,
+    ADD COLUMN cleaning_fee bigint NOT NULL DEFAULT 0,
+    ADD COLUMN service_fee bigint NOT NULL DEFAULT 0,
+    ADD COLUMN tax bigint NOT NULL DEFAULT 0,
+    ADD COLUMN total_price bigint NOT NULL DEFAULT 0,
+    ADD COLUMN refund_amount bigint NOT NULL DEFAULT 0,
+    ADD COLUMN cancellation_reason varchar(500),
+    ADD COLUMN cancelled_by_id uuid,
+    ADD COLUMN cancelled_by_role varchar(100),
+    ADD COLUMN cancelled_at timestamptz;
+
+-- Cancelling used to soft delete the booking. Keep those rows visible as
+-- cancelled bookings instead.
+UPDATE bookings SET status = 'cancelled', cancelled_at = deleted_at, deleted_at = NULL
+WHERE deleted_at IS NOT NULL;
+
+CREATE TABLE blocked_dates (
+    id uuid PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    property_id uuid NOT NULL,
+    start_date date NOT NULL,
+    end_date date NOT NULL,
+    reason varchar(255)
+);
+CREATE INDEX idx_blocked_dates_deleted_at ON blocked_dates (deleted_at);
+CREATE INDEX idx_blocked_dates_property_id ON blocked_dates (property_id);
+
+CREATE TABLE booking_transitions (
+    id uuid PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    booking_id uuid NOT NULL,
+    from_status varchar(50),
+    to_status varchar(50) NOT NULL,
+    actor_id uuid,
+    actor_role varchar(100) NOT NULL
+);
+CREATE INDEX idx_booking_transitions_deleted_at ON booking_transitions (deleted_at);
+CREATE INDEX idx_booking_transitions_booking_id ON booking_transitions (booking_id);
@@ -0,0 +1,14 @@
+ALTER TABLE properties
+    DROP COLUMN longitude,
+    DROP COLUMN latitude,
+    DROP COLUMN postal_code,
+    DROP COLUMN country,
+    DROP COLUMN region,
+    DROP COLUMN city,
+    DROP COLUMN street,
+    DROP COLUMN rating_count,
+    DROP COLUMN rating_average,
+    DROP COLUMN max_guests,
+    DROP COLUMN cancellation_policy,
+    DROP COLUMN currency,
+    DROP COLUMN cleaning_fee;
@@ -0,0 +1,14 @@
+ALTER TABLE properties
+    ADD COLUMN cleaning_fee bigint NOT NULL DEFAULT 0,
+    ADD COLUMN currency varchar(3) NOT NULL DEFAULT 'USD',
+    ADD COLUMN cancellation_policy varchar(50) NOT NULL DEFAULT 'flexible',
+    ADD COLUMN max_guests bigint NOT NULL DEFAULT 1,
+    ADD COLUMN rating_average double precision NOT NULL DEFAULT 0,
+    ADD COLUMN rating_count bigint NOT NULL DEFAULT 0,
+    ADD COLUMN street varchar(255),
+    ADD COLUMN city varchar(100),
+    ADD COLUMN region varchar(100),
+    ADD COLUMN country varchar(100),
+    ADD COLUMN postal_code varchar(20),
+    ADD COLUMN latitude double precision,
+    ADD COLUMN longitude double precision;
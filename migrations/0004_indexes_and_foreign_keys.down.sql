@@ -0,0 +1,20 @@
+DROP INDEX IF EXISTS idx_blocked_dates_property_dates;
+DROP INDEX IF EXISTS idx_bookings_property_dates;
+DROP INDEX IF EXISTS idx_properties_lat_lng;
+DROP INDEX IF EXISTS idx_properties_max_guests;
+DROP INDEX IF EXISTS idx_properties_rating_id;
+DROP INDEX IF EXISTS idx_properties_price_id;
+DROP INDEX IF EXISTS idx_properties_created_at_id;
+DROP INDEX IF EXISTS idx_properties_location_trgm;
+DROP INDEX IF EXISTS idx_properties_description_trgm;
+DROP INDEX IF EXISTS idx_properties_name_trgm;
+
+DROP INDEX IF EXISTS idx_bookings_property_id;
+DROP INDEX IF EXISTS idx_bookings_user_id;
+DROP INDEX IF EXISTS idx_properties_owner_id;
+
+ALTER TABLE booking_transitions DROP CONSTRAINT IF EXISTS fk_booking_transitions_booking;
+ALTER TABLE blocked_dates DROP CONSTRAINT IF EXISTS fk_blocked_dates_property;
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS fk_bookings_property;
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS fk_bookings_user;
+ALTER TABLE properties DROP CONSTRAINT IF EXISTS fk_properties_owner;
@@ -0,0 +1,36 @@
+-- AutoMigrate may have created foreign keys under GORM's names; replace them
+-- with the ones below so every database ends up with the same constraints.
+ALTER TABLE properties DROP CONSTRAINT IF EXISTS fk_property_owners_properties;
+ALTER TABLE properties DROP CONSTRAINT IF EXISTS fk_properties_owner;
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS fk_bookings_user;
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS fk_bookings_property;
+
+ALTER TABLE properties ADD CONSTRAINT fk_properties_owner
+    FOREIGN KEY (owner_id) REFERENCES property_owners (id);
+ALTER TABLE bookings ADD CONSTRAINT fk_bookings_user
+    FOREIGN KEY (user_id) REFERENCES users (id);
+ALTER TABLE bookings ADD CONSTRAINT fk_bookings_property
+    FOREIGN KEY (property_id) REFERENCES properties (id);
+ALTER TABLE blocked_dates ADD CONSTRAINT fk_blocked_dates_property
+    FOREIGN KEY (property_id) REFERENCES properties (id) ON DELETE CASCADE;
+ALTER TABLE booking_transitions ADD CONSTRAINT fk_booking_transitions_booking
+    FOREIGN KEY (booking_id) REFERENCES bookings (id) ON DELETE CASCADE;
+
+CREATE INDEX idx_properties_owner_id ON properties (owner_id);
+CREATE INDEX idx_bookings_user_id ON bookings (user_id);
+CREATE INDEX idx_bookings_property_id ON bookings (property_id);
+
+-- Public property search: trigram indexes for the ILIKE text and location
+-- filters, keyset indexes for each sort order, a coordinate index for nearby
+-- and map searches, and date-range indexes for the availability filter.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX idx_properties_name_trgm ON properties USING gin (name gin_trgm_ops) WHERE deleted_at IS NULL;
+CREATE INDEX idx_properties_description_trgm ON properties USING gin (description gin_trgm_ops) WHERE deleted_at IS NULL;
+CREATE INDEX idx_properties_location_trgm ON properties USING gin (location gin_trgm_ops) WHERE deleted_at IS NULL;
+CREATE INDEX idx_properties_created_at_id ON properties (created_at DESC, id DESC) WHERE deleted_at IS NULL;
+CREATE INDEX idx_properties_price_id ON properties (price, id) WHERE deleted_at IS NULL;
+CREATE INDEX idx_properties_rating_id ON properties (rating_average DESC, id DESC) WHERE deleted_at IS NULL;
+CREATE INDEX idx_properties_max_guests ON properties (max_guests) WHERE deleted_at IS NULL;
+CREATE INDEX idx_properties_lat_lng ON properties (latitude, longitude) WHERE deleted_at IS NULL AND latitude IS NOT NULL;
+CREATE INDEX idx_bookings_property_dates ON bookings (property_id, check_in, check_out) WHERE deleted_at IS NULL;
+CREATE INDEX idx_blocked_dates_property_dates ON blocked_dates (property_id, start_date, end_date) WHERE deleted_at IS NULL;
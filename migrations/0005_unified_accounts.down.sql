@@ -0,0 +1,51 @@
+-- Splits accounts back into users and property owners. An account holding
+-- both roles appears in both tables under the same id; hosts that were merged
+-- into a guest account keep the guest's password.
+CREATE TABLE users (
+    id uuid PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    name varchar(100) NOT NULL,
+    email varchar(100) NOT NULL,
+    password varchar(255) NOT NULL,
+    role varchar(100) NOT NULL
+);
+CREATE UNIQUE INDEX idx_users_email ON users (email);
+CREATE INDEX idx_users_deleted_at ON users (deleted_at);
+
+CREATE TABLE property_owners (
+    id uuid PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    name varchar(100) NOT NULL,
+    email varchar(100) NOT NULL,
+    password varchar(255) NOT NULL,
+    role varchar(100) NOT NULL
+);
+CREATE UNIQUE INDEX idx_property_owners_email ON property_owners (email);
+CREATE INDEX idx_property_owners_deleted_at ON property_owners (deleted_at);
+
+INSERT INTO users (id, created_at, updated_at, deleted_at, name, email, password, role)
+SELECT a.id, a.created_at, a.updated_at, a.deleted_at, a.name, a.email, a.password, 'user'
+FROM accounts a JOIN account_roles r ON r.account_id = a.id AND r.role_name = 'guest';
+INSERT INTO property_owners (id, created_at, updated_at, deleted_at, name, email, password, role)
+SELECT a.id, a.created_at, a.updated_at, a.deleted_at, a.name, a.email, a.password, 'property_owner'
+FROM accounts a JOIN account_roles r ON r.account_id = a.id AND r.role_name = 'host';
+
+UPDATE booking_transitions SET actor_role = 'user' WHERE actor_role = 'guest';
+UPDATE booking_transitions SET actor_role = 'property_owner' WHERE actor_role = 'host';
+UPDATE bookings SET cancelled_by_role = 'user' WHERE cancelled_by_role = 'guest';
+UPDATE bookings SET cancelled_by_role = 'property_owner' WHERE cancelled_by_role = 'host';
+
+ALTER TABLE properties DROP CONSTRAINT fk_properties_owner;
+ALTER TABLE bookings DROP CONSTRAINT fk_bookings_user;
+ALTER TABLE properties ADD CONSTRAINT fk_properties_owner
+    FOREIGN KEY (owner_id) REFERENCES property_owners (id);
+ALTER TABLE bookings ADD CONSTRAINT fk_bookings_user
+    FOREIGN KEY (user_id) REFERENCES users (id);
+
+DROP TABLE account_roles;
+DROP TABLE roles;
+DROP TABLE accounts;
@@ -0,0 +1,67 @@
+-- Users and property owners become accounts holding the guest and host roles.
+CREATE TABLE accounts (
+    id uuid PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    name varchar(100) NOT NULL,
+    email varchar(100) NOT NULL,
+    password varchar(255) NOT NULL
+);
+CREATE UNIQUE INDEX idx_accounts_email ON accounts (email);
+CREATE INDEX idx_accounts_deleted_at ON accounts (deleted_at);
+
+CREATE TABLE roles (
+    name varchar(50) PRIMARY KEY
+);
+INSERT INTO roles (name) VALUES ('guest'), ('host'), ('admin');
+
+CREATE TABLE account_roles (
+    account_id uuid NOT NULL,
+    role_name varchar(50) NOT NULL,
+    PRIMARY KEY (account_id, role_name),
+    CONSTRAINT fk_account_roles_account FOREIGN KEY (account_id) REFERENCES accounts (id) ON DELETE CASCADE,
+    CONSTRAINT fk_account_roles_role FOREIGN KEY (role_name) REFERENCES roles (name)
+);
+
+INSERT INTO accounts (id, created_at, updated_at, deleted_at, name, email, password)
+SELECT id, created_at, updated_at, deleted_at, name, email, password FROM users;
+
+-- A host who also signed up as a guest with the same email is merged into the
+-- guest's account and keeps the guest's password.
+INSERT INTO accounts (id, created_at, updated_at, deleted_at, name, email, password)
+SELECT o.id, o.created_at, o.updated_at, o.deleted_at, o.name, o.email, o.password
+FROM property_owners o
+WHERE NOT EXISTS (SELECT 1 FROM accounts a WHERE a.email = o.email);
+
+INSERT INTO account_roles (account_id, role_name)
+SELECT id, 'guest' FROM users;
+INSERT INTO account_roles (account_id, role_name)
+SELECT a.id, 'host' FROM property_owners o JOIN accounts a ON a.email = o.email;
+
+ALTER TABLE properties DROP CONSTRAINT fk_properties_owner;
+ALTER TABLE bookings DROP CONSTRAINT fk_bookings_user;
+
+-- Point everything that named a merged host at the account it was merged into.
+UPDATE properties p SET owner_id = a.id
+FROM property_owners o JOIN accounts a ON a.email = o.email
+WHERE p.owner_id = o.id AND a.id <> o.id;
+UPDATE booking_transitions t SET actor_id = a.id
+FROM property_owners o JOIN accounts a ON a.email = o.email
+WHERE t.actor_id = o.id AND t.actor_role = 'property_owner' AND a.id <> o.id;
+UPDATE bookings b SET cancelled_by_id = a.id
+FROM property_owners o JOIN accounts a ON a.email = o.email
+WHERE b.cancelled_by_id = o.id AND b.cancelled_by_role = 'property_owner' AND a.id <> o.id;
+
+UPDATE booking_transitions SET actor_role = 'guest' WHERE actor_role = 'user';
+UPDATE booking_transitions SET actor_role = 'host' WHERE actor_role = 'property_owner';
+UPDATE bookings SET cancelled_by_role = 'guest' WHERE cancelled_by_role = 'user';
+UPDATE bookings SET cancelled_by_role = 'host' WHERE cancelled_by_role = 'property_owner';
+
+ALTER TABLE properties ADD CONSTRAINT fk_properties_owner
+    FOREIGN KEY (owner_id) REFERENCES accounts (id);
+ALTER TABLE bookings ADD CONSTRAINT fk_bookings_user
+    FOREIGN KEY (user_id) REFERENCES accounts (id);
+
+DROP TABLE property_owners;
+DROP TABLE users;
@@ -0,0 +1,26 @@
+CREATE TABLE refresh_tokens (
+    id uuid PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    account_id uuid NOT NULL,
+    family_id uuid NOT NULL,
+    token_hash varchar(64) NOT NULL,
+    access_jti varchar(64) NOT NULL,
+    access_expires_at timestamptz NOT NULL,
+    expires_at timestamptz NOT NULL,
+    revoked_at timestamptz,
+    replaced_by_id uuid,
+    CONSTRAINT fk_refresh_tokens_account FOREIGN KEY (account_id) REFERENCES accounts (id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX idx_refresh_tokens_token_hash ON refresh_tokens (token_hash);
+CREATE INDEX idx_refresh_tokens_account_id ON refresh_tokens (account_id);
+CREATE INDEX idx_refresh_tokens_family_id ON refresh_tokens (family_id);
+CREATE INDEX idx_refresh_tokens_expires_at ON refresh_tokens (expires_at);
+CREATE INDEX idx_refresh_tokens_deleted_at ON refresh_tokens (deleted_at);
+
+CREATE TABLE revoked_tokens (
+    jti varchar(64) PRIMARY KEY,
+    expires_at timestamptz NOT NULL
+);
+CREATE INDEX idx_revoked_tokens_expires_at ON revoked_tokens (expires_at);
@@ -0,0 +1,21 @@
+ALTER TABLE accounts ADD COLUMN email_verified_at timestamptz;
+-- Accounts created before verification existed are treated as verified, so
+-- existing guests can keep booking.
+UPDATE accounts SET email_verified_at = created_at;
+
+CREATE TABLE account_tokens (
+    id uuid PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    account_id uuid NOT NULL,
+    purpose varchar(50) NOT NULL,
+    token_hash varchar(64) NOT NULL,
+    expires_at timestamptz NOT NULL,
+    used_at timestamptz,
+    CONSTRAINT fk_account_tokens_account FOREIGN KEY (account_id) REFERENCES accounts (id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX idx_account_tokens_token_hash ON account_tokens (token_hash);
+CREATE INDEX idx_account_tokens_account_id ON account_tokens (account_id);
+CREATE INDEX idx_account_tokens_expires_at ON account_tokens (expires_at);
+CREATE INDEX idx_account_tokens_deleted_at ON account_tokens (deleted_at);
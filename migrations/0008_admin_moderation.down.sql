@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_accounts_created_at;
+DROP TABLE IF EXISTS audit_entries;
+ALTER TABLE properties DROP COLUMN IF EXISTS unlisted_at;
+ALTER TABLE accounts DROP COLUMN IF EXISTS suspension_reason;
+ALTER TABLE accounts DROP COLUMN IF EXISTS suspended_at;
@@ -0,0 +1,22 @@
+ALTER TABLE accounts ADD COLUMN suspended_at timestamptz;
+ALTER TABLE accounts ADD COLUMN suspension_reason varchar(500);
+ALTER TABLE properties ADD COLUMN unlisted_at timestamptz;
+
+-- Entries outlive the accounts they name, so there is no foreign key to
+-- cascade from; accounts are only ever soft-deleted anyway.
+CREATE TABLE audit_entries (
+    id uuid PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    actor_id uuid NOT NULL,
+    action varchar(50) NOT NULL,
+    target_type varchar(50) NOT NULL,
+    target_id uuid NOT NULL,
+    reason varchar(500) NOT NULL
+);
+CREATE INDEX idx_audit_entries_actor_id ON audit_entries (actor_id);
+CREATE INDEX idx_audit_entries_target_id ON audit_entries (target_id);
+CREATE INDEX idx_audit_entries_created_at ON audit_entries (created_at, id);
+CREATE INDEX idx_audit_entries_deleted_at ON audit_entries (deleted_at);
+CREATE INDEX idx_accounts_created_at ON accounts (created_at, id);
@@ -0,0 +1,22 @@
+CREATE TABLE reviews (
+    id uuid PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    booking_id uuid NOT NULL,
+    author_role varchar(50) NOT NULL,
+    property_id uuid NOT NULL,
+    author_id uuid NOT NULL,
+    subject_id uuid NOT NULL,
+    rating bigint NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    comment varchar(2000) NOT NULL,
+    reply varchar(2000),
+    replied_at timestamptz,
+    CONSTRAINT fk_reviews_booking FOREIGN KEY (booking_id) REFERENCES bookings (id),
+    CONSTRAINT fk_reviews_property FOREIGN KEY (property_id) REFERENCES properties (id)
+);
+-- One review per booking from each side.
+CREATE UNIQUE INDEX idx_reviews_booking_author_role ON reviews (booking_id, author_role);
+CREATE INDEX idx_reviews_property_id ON reviews (property_id, created_at DESC, id DESC);
+CREATE INDEX idx_reviews_subject_id ON reviews (subject_id, created_at DESC, id DESC);
+CREATE INDEX idx_reviews_deleted_at ON reviews (deleted_at);
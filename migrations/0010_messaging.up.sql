@@ -0,0 +1,37 @@
+CREATE TABLE conversations (
+    id uuid PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    property_id uuid NOT NULL,
+    booking_id uuid,
+    guest_id uuid NOT NULL,
+    host_id uuid NOT NULL,
+    last_message_at timestamptz NOT NULL,
+    guest_read_at timestamptz,
+    host_read_at timestamptz,
+    CONSTRAINT fk_conversations_property FOREIGN KEY (property_id) REFERENCES properties (id),
+    CONSTRAINT fk_conversations_booking FOREIGN KEY (booking_id) REFERENCES bookings (id),
+    CONSTRAINT fk_conversations_guest FOREIGN KEY (guest_id) REFERENCES accounts (id),
+    CONSTRAINT fk_conversations_host FOREIGN KEY (host_id) REFERENCES accounts (id)
+);
+-- A guest has one thread per booking and one per property outside bookings.
+CREATE UNIQUE INDEX idx_conversations_booking_id ON conversations (booking_id) WHERE booking_id IS NOT NULL;
+CREATE UNIQUE INDEX idx_conversations_inquiry ON conversations (property_id, guest_id) WHERE booking_id IS NULL;
+CREATE INDEX idx_conversations_guest_id ON conversations (guest_id, last_message_at DESC, id DESC);
+CREATE INDEX idx_conversations_host_id ON conversations (host_id, last_message_at DESC, id DESC);
+CREATE INDEX idx_conversations_property_id ON conversations (property_id);
+CREATE INDEX idx_conversations_deleted_at ON conversations (deleted_at);
+
+CREATE TABLE messages (
+    id uuid PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    conversation_id uuid NOT NULL,
+    sender_id uuid NOT NULL,
+    body varchar(2000) NOT NULL,
+    CONSTRAINT fk_messages_conversation FOREIGN KEY (conversation_id) REFERENCES conversations (id)
+);
+CREATE INDEX idx_messages_conversation_id ON messages (conversation_id, created_at DESC, id DESC);
+CREATE INDEX idx_messages_deleted_at ON messages (deleted_at);
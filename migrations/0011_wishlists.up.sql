@@ -0,0 +1,25 @@
+CREATE TABLE wishlists (
+    id uuid PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    guest_id uuid NOT NULL,
+    name varchar(100) NOT NULL,
+    share_token_hash varchar(64),
+    CONSTRAINT fk_wishlists_guest FOREIGN KEY (guest_id) REFERENCES accounts (id)
+);
+CREATE INDEX idx_wishlists_guest_id ON wishlists (guest_id);
+CREATE UNIQUE INDEX idx_wishlists_share_token_hash ON wishlists (share_token_hash);
+CREATE INDEX idx_wishlists_deleted_at ON wishlists (deleted_at);
+
+-- Items are hard-deleted with their wishlist or property, so the cascades
+-- only matter for rows removed outside the API.
+CREATE TABLE wishlist_items (
+    wishlist_id uuid NOT NULL,
+    property_id uuid NOT NULL,
+    created_at timestamptz,
+    PRIMARY KEY (wishlist_id, property_id),
+    CONSTRAINT fk_wishlist_items_wishlist FOREIGN KEY (wishlist_id) REFERENCES wishlists (id) ON DELETE CASCADE,
+    CONSTRAINT fk_wishlist_items_property FOREIGN KEY (property_id) REFERENCES properties (id) ON DELETE CASCADE
+);
+CREATE INDEX idx_wishlist_items_property_id ON wishlist_items (property_id);
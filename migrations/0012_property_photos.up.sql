@@ -0,0 +1,15 @@
+-- Photos are hard-deleted with their files. Position orders a property's
+-- photos from 0, the cover.
+CREATE TABLE photos (
+    id uuid PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    property_id uuid NOT NULL,
+    position integer NOT NULL,
+    width integer NOT NULL,
+    height integer NOT NULL,
+    CONSTRAINT fk_photos_property FOREIGN KEY (property_id) REFERENCES properties (id)
+);
+CREATE INDEX idx_photos_property_id ON photos (property_id);
+CREATE INDEX idx_photos_deleted_at ON photos (deleted_at);
@@ -0,0 +1,14 @@
+DROP TABLE IF EXISTS property_amenities;
+DROP TABLE IF EXISTS amenities;
+ALTER TABLE properties
+    DROP COLUMN check_out_time,
+    DROP COLUMN check_in_time,
+    DROP COLUMN quiet_hours_end,
+    DROP COLUMN quiet_hours_start,
+    DROP COLUMN events_allowed,
+    DROP COLUMN smoking_allowed,
+    DROP COLUMN pets_allowed,
+    DROP COLUMN bathrooms,
+    DROP COLUMN beds,
+    DROP COLUMN bedrooms,
+    DROP COLUMN property_type;
@@ -0,0 +1,45 @@
+ALTER TABLE properties
+    ADD COLUMN property_type varchar(20) NOT NULL DEFAULT 'entire_home',
+    ADD COLUMN bedrooms bigint NOT NULL DEFAULT 0,
+    ADD COLUMN beds bigint NOT NULL DEFAULT 1,
+    ADD COLUMN bathrooms double precision NOT NULL DEFAULT 1,
+    ADD COLUMN pets_allowed boolean NOT NULL DEFAULT false,
+    ADD COLUMN smoking_allowed boolean NOT NULL DEFAULT false,
+    ADD COLUMN events_allowed boolean NOT NULL DEFAULT false,
+    ADD COLUMN quiet_hours_start varchar(5),
+    ADD COLUMN quiet_hours_end varchar(5),
+    ADD COLUMN check_in_time varchar(5) NOT NULL DEFAULT '15:00',
+    ADD COLUMN check_out_time varchar(5) NOT NULL DEFAULT '11:00';
+
+-- Amenities are keyed by a slug that never changes, so it can be used in
+-- search filters; admins maintain the names.
+CREATE TABLE amenities (
+    key varchar(50) PRIMARY KEY,
+    name varchar(100) NOT NULL,
+    created_at timestamptz
+);
+INSERT INTO amenities (key, name, created_at) VALUES
+    ('wifi', 'Wifi', now()),
+    ('kitchen', 'Kitchen', now()),
+    ('washer', 'Washer', now()),
+    ('dryer', 'Dryer', now()),
+    ('air_conditioning', 'Air conditioning', now()),
+    ('heating', 'Heating', now()),
+    ('tv', 'TV', now()),
+    ('workspace', 'Dedicated workspace', now()),
+    ('free_parking', 'Free parking', now()),
+    ('pool', 'Pool', now()),
+    ('hot_tub', 'Hot tub', now()),
+    ('ev_charger', 'EV charger', now()),
+    ('crib', 'Crib', now()),
+    ('smoke_alarm', 'Smoke alarm', now()),
+    ('carbon_monoxide_alarm', 'Carbon monoxide alarm', now());
+
+CREATE TABLE property_amenities (
+    property_id uuid NOT NULL,
+    amenity_key varchar(50) NOT NULL,
+    PRIMARY KEY (property_id, amenity_key),
+    CONSTRAINT fk_property_amenities_property FOREIGN KEY (property_id) REFERENCES properties (id) ON DELETE CASCADE,
+    CONSTRAINT fk_property_amenities_amenity FOREIGN KEY (amenity_key) REFERENCES amenities (key) ON DELETE CASCADE
+);
+CREATE INDEX idx_property_amenities_amenity_key ON property_amenities (amenity_key);
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS price_rules;
+ALTER TABLE bookings
+    DROP COLUMN discount;
+ALTER TABLE properties
+    DROP COLUMN monthly_discount,
+    DROP COLUMN weekly_discount;
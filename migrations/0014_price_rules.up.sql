@@ -0,0 +1,25 @@
+ALTER TABLE properties
+    ADD COLUMN weekly_discount bigint NOT NULL DEFAULT 0,
+    ADD COLUMN monthly_discount bigint NOT NULL DEFAULT 0;
+ALTER TABLE bookings
+    ADD COLUMN discount bigint NOT NULL DEFAULT 0;
+
+-- A date_range rule uses start_date, end_date and price; a weekday rule uses
+-- weekdays, a bitmask with Sunday as bit 0, and adjustment_basis_points.
+-- Rules are hard-deleted; bookings keep the prices they were made at.
+CREATE TABLE price_rules (
+    id uuid PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    property_id uuid NOT NULL,
+    kind varchar(20) NOT NULL,
+    start_date date,
+    end_date date,
+    price bigint NOT NULL DEFAULT 0,
+    weekdays smallint NOT NULL DEFAULT 0,
+    adjustment_basis_points bigint NOT NULL DEFAULT 0,
+    CONSTRAINT fk_price_rules_property FOREIGN KEY (property_id) REFERENCES properties (id) ON DELETE CASCADE
+);
+CREATE INDEX idx_price_rules_property_id ON price_rules (property_id);
+CREATE INDEX idx_price_rules_deleted_at ON price_rules (deleted_at);
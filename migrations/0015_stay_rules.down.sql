@@ -0,0 +1,6 @@
+ALTER TABLE properties
+    DROP COLUMN check_in_days,
+    DROP COLUMN booking_window_days,
+    DROP COLUMN advance_notice_days,
+    DROP COLUMN max_nights,
+    DROP COLUMN min_nights;
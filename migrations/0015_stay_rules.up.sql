@@ -0,0 +1,9 @@
+-- check_in_days is a bitmask of the weekdays guests may arrive on, with
+-- Sunday as bit 0; 0 allows any day. A max_nights or booking_window_days of 0
+-- sets no limit.
+ALTER TABLE properties
+    ADD COLUMN min_nights bigint NOT NULL DEFAULT 1,
+    ADD COLUMN max_nights bigint NOT NULL DEFAULT 0,
+    ADD COLUMN advance_notice_days bigint NOT NULL DEFAULT 0,
+    ADD COLUMN booking_window_days bigint NOT NULL DEFAULT 0,
+    ADD COLUMN check_in_days smallint NOT NULL DEFAULT 0;
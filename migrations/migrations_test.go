@@ -0,0 +1,249 @@
+package migrations
+
+import (
+	"airbnb/models"
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestEmbeddedMigrations(t *testing.T) {
+	migrations, err := load(files)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("no migrations embedded")
+	}
+	for i, migration := range migrations {
+		if migration.Version != int64(i+1) {
+			t.Fatalf("migration %04d_%s follows version %d", migration.Version, migration.Name, i)
+		}
+		if migration.Up == "" || migration.Down == "" {
+			t.Fatalf("migration %04d_%s has an empty file", migration.Version, migration.Name)
+		}
+	}
+}
+
+// schemaModels are the models the migrations must create tables for.
+var schemaModels = []any{
+	&models.Account{}, &models.Role{}, &models.Property{}, &models.Booking{}, &models.BlockedDate{}, &models.BookingTransition{},
+	&models.RefreshToken{}, &models.RevokedToken{}, &models.AccountToken{}, &models.AuditEntry{}, &models.Review{},
+	&models.Conversation{}, &models.Message{}, &models.Wishlist{}, &models.WishlistItem{}, &models.Photo{}, &models.Amenity{},
+	&models.PriceRule{},
+}
+
+// TestMigrationsMatchModels replays the tables and columns the migrations
+// create, alter and drop, so a model field without a migration fails here
+// even where no Postgres is available to run them.
+func TestMigrationsMatchModels(t *testing.T) {
+	migrations, err := load(files)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	tables := map[string]map[string]bool{}
+	for _, migration := range migrations {
+		replay(t, tables, migration.Up)
+	}
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=unused"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	for _, model := range schemaModels {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			t.Fatalf("parse %T: %v", model, err)
+		}
+		columns, ok := tables[stmt.Schema.Table]
+		if !ok {
+			t.Errorf("no migration creates table %s for %T", stmt.Schema.Table, model)
+			continue
+		}
+		for _, column := range stmt.Schema.DBNames {
+			if !columns[column] {
+				t.Errorf("no migration adds column %s.%s for %T", stmt.Schema.Table, column, model)
+			}
+		}
+	}
+
+	for i := len(migrations) - 1; i >= 0; i-- {
+		replay(t, tables, migrations[i].Down)
+	}
+	if len(tables) != 0 {
+		t.Errorf("tables left after rolling back every migration: %v", tables)
+	}
+}
+
+var (
+	createTable = regexp.MustCompile(`(?is)^CREATE TABLE (?:IF NOT EXISTS )?(\w+)\s*\((.*)\)$`)
+	alterTable  = regexp.MustCompile(`(?is)^ALTER TABLE (?:IF EXISTS )?(?:ONLY )?(\w+)\s+(.*)$`)
+	dropTable   = regexp.MustCompile(`(?is)^DROP TABLE (?:IF EXISTS )?([\w,\s]+?)(?:\s+CASCADE)?$`)
+	addColumn   = regexp.MustCompile(`(?is)^ADD COLUMN (?:IF NOT EXISTS )?(\w+)`)
+	dropColumn  = regexp.MustCompile(`(?is)^DROP COLUMN (?:IF EXISTS )?(\w+)`)
+	renameCol   = regexp.MustCompile(`(?is)^RENAME (?:COLUMN )?(\w+) TO (\w+)$`)
+	renameTable = regexp.MustCompile(`(?is)^RENAME TO (\w+)$`)
+	comment     = regexp.MustCompile(`--[^\n]*`)
+)
+
+// replay applies the table and column changes in sql to tables. Other
+// statements, such as indexes and data fixes, leave the columns alone.
+func replay(t *testing.T, tables map[string]map[string]bool, sql string) {
+	t.Helper()
+	for _, statement := range strings.Split(comment.ReplaceAllString(sql, ""), ";") {
+		statement = strings.TrimSpace(statement)
+		if m := createTable.FindStringSubmatch(statement); m != nil {
+			columns := map[string]bool{}
+			for _, item := range splitTopLevel(m[2]) {
+				name := strings.ToLower(strings.Fields(item)[0])
+				switch name {
+				case "constraint", "primary", "unique", "foreign", "check":
+				default:
+					columns[name] = true
+				}
+			}
+			tables[strings.ToLower(m[1])] = columns
+		} else if m := dropTable.FindStringSubmatch(statement); m != nil {
+			for _, name := range strings.Split(m[1], ",") {
+				delete(tables, strings.ToLower(strings.TrimSpace(name)))
+			}
+		} else if m := alterTable.FindStringSubmatch(statement); m != nil {
+			table := strings.ToLower(m[1])
+			columns, ok := tables[table]
+			if !ok {
+				t.Fatalf("ALTER TABLE %s before it is created", table)
+			}
+			for _, clause := range splitTopLevel(m[2]) {
+				if c := addColumn.FindStringSubmatch(clause); c != nil {
+					columns[strings.ToLower(c[1])] = true
+				} else if c := dropColumn.FindStringSubmatch(clause); c != nil {
+					delete(columns, strings.ToLower(c[1]))
+				} else if c := renameTable.FindStringSubmatch(clause); c != nil {
+					delete(tables, table)
+					tables[strings.ToLower(c[1])] = columns
+				} else if c := renameCol.FindStringSubmatch(clause); c != nil {
+					delete(columns, strings.ToLower(c[1]))
+					columns[strings.ToLower(c[2])] = true
+				}
+			}
+		}
+	}
+}
+
+// splitTopLevel splits a column list or ALTER TABLE clauses on the commas
+// outside parentheses.
+func splitTopLevel(s string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	if last := strings.TrimSpace(s[start:]); last != "" {
+		parts = append(parts, last)
+	}
+	return parts
+}
+
+// TestMigrationsUpAndDown applies every migration and rolls them all back,
+// checking the schema they leave against the models. The SQL is written for
+// Postgres, so the test needs one: set TEST_DATABASE_URL to a database it may
+// create and drop a scratch schema in. It is skipped otherwise.
+func TestMigrationsUpAndDown(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	// One connection, so the search path below holds for every statement.
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	schema := fmt.Sprintf("migrations_test_%d", time.Now().UnixNano())
+	if err := db.Exec("CREATE SCHEMA " + schema).Error; err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+	t.Cleanup(func() { db.Exec("DROP SCHEMA " + schema + " CASCADE") })
+	if err := db.Exec("SET search_path TO " + schema).Error; err != nil {
+		t.Fatalf("set search path: %v", err)
+	}
+
+	ctx := context.Background()
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		t.Fatalf("migrator: %v", err)
+	}
+	applied, err := migrator.Up(ctx)
+	if err != nil {
+		t.Fatalf("up: %v", err)
+	}
+	if len(applied) != len(migrator.Migrations) {
+		t.Fatalf("applied %d of %d migrations", len(applied), len(migrator.Migrations))
+	}
+	var versions []int64
+	if err := db.Table("schema_migrations").Order("version").Pluck("version", &versions).Error; err != nil {
+		t.Fatalf("schema_migrations: %v", err)
+	}
+	for i, version := range versions {
+		if version != migrator.Migrations[i].Version {
+			t.Fatalf("schema_migrations has versions %v", versions)
+		}
+	}
+	if len(versions) != len(migrator.Migrations) {
+		t.Fatalf("schema_migrations has versions %v", versions)
+	}
+
+	for _, model := range schemaModels {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			t.Fatalf("parse %T: %v", model, err)
+		}
+		if !db.Migrator().HasTable(model) {
+			t.Fatalf("no table %s for %T", stmt.Schema.Table, model)
+		}
+		for _, column := range stmt.Schema.DBNames {
+			if !db.Migrator().HasColumn(model, column) {
+				t.Errorf("table %s has no column %s for %T", stmt.Schema.Table, column, model)
+			}
+		}
+	}
+
+	rolledBack, err := migrator.Down(ctx, len(migrator.Migrations))
+	if err != nil {
+		t.Fatalf("down: %v", err)
+	}
+	if len(rolledBack) != len(migrator.Migrations) {
+		t.Fatalf("rolled back %d of %d migrations", len(rolledBack), len(migrator.Migrations))
+	}
+	var count int64
+	if err := db.Table("schema_migrations").Count(&count).Error; err != nil || count != 0 {
+		t.Fatalf("schema_migrations has %d rows after down: %v", count, err)
+	}
+	for _, model := range schemaModels {
+		if db.Migrator().HasTable(model) {
+			t.Errorf("table for %T remains after down", model)
+		}
+	}
+}
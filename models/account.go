@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Accounts may give themselves the guest and host roles. Admin is granted by
+// another admin.
+
+type CreateAccount struct {
+	Name     string `json:"name" binding:"required,max=100"`
+	Email    string `json:"email" binding:"required,email,max=100"`
+	Password string `json:"password" binding:"required,password,max=72"`
+	// Roles defaults to guest. Sign up with host to list properties.
+	Roles []string `json:"roles" binding:"omitempty,dive,oneof=guest host" example:"guest,host"`
+}
+
+type LoginAccount struct {
+	Email    string `json:"email" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+type AddRole struct {
+	Role string `json:"role" binding:"required,oneof=guest host" example:"host"`
+}
+
+// Profile is the signed-in account as its holder sees it.
+type Profile struct {
+	AccountID     uuid.UUID `json:"account_id"`
+	Name          string    `json:"name"`
+	Email         string    `json:"email"`
+	EmailVerified bool      `json:"email_verified"`
+	Roles         []string  `json:"roles"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func NewProfile(account *Account) Profile {
+	return Profile{
+		AccountID:     account.ID,
+		Name:          account.Name,
+		Email:         account.Email,
+		EmailVerified: account.IsVerified(),
+		Roles:         account.RoleNames(),
+		CreatedAt:     account.CreatedAt,
+	}
+}
+
+// UpdateProfile changes the fields that are set. A new email must be verified
+// again before the account can book.
+type UpdateProfile struct {
+	Name  *string `json:"name" binding:"omitempty,min=1,max=100"`
+	Email *string `json:"email" binding:"omitempty,email,max=100"`
+}
+
+type ChangePassword struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,password,max=72"`
+}
+
+// DeleteAccount confirms the deletion with the account's password.
+type DeleteAccount struct {
+	Password string `json:"password" binding:"required"`
+}
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Actions recorded in the audit log, and the kinds of record they act on.
+const (
+	AuditSuspendAccount   = "account.suspend"
+	AuditReinstateAccount = "account.reinstate"
+	AuditCancelBooking    = "booking.cancel"
+	AuditUnlistProperty   = "property.unlist"
+	AuditRelistProperty   = "property.relist"
+
+	AuditTargetAccount  = "account"
+	AuditTargetBooking  = "booking"
+	AuditTargetProperty = "property"
+)
+
+// Account statuses an admin can filter by.
+const (
+	AccountActive    = "active"
+	AccountSuspended = "suspended"
+)
+
+// AdminAction is the body of every admin action. The reason is kept in the
+// audit log.
+type AdminAction struct {
+	Reason string `json:"reason" binding:"required,max=500" example:"repeated spam reports"`
+}
+
+// AccountSearch filters the admin account listing. Q matches name or email.
+type AccountSearch struct {
+	Query  string `form:"q" binding:"max=100"`
+	Role   string `form:"role" binding:"omitempty,oneof=guest host admin"`
+	Status string `form:"status" binding:"omitempty,oneof=active suspended"`
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+type AdminAccount struct {
+	AccountID        uuid.UUID  `json:"account_id"`
+	Name             string     `json:"name"`
+	Email            string     `json:"email"`
+	Roles            []string   `json:"roles"`
+	EmailVerifiedAt  *time.Time `json:"email_verified_at"`
+	SuspendedAt      *time.Time `json:"suspended_at"`
+	SuspensionReason string     `json:"suspension_reason,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+func NewAdminAccount(account *Account) AdminAccount {
+	return AdminAccount{
+		AccountID:        account.ID,
+		Name:             account.Name,
+		Email:            account.Email,
+		Roles:            account.RoleNames(),
+		EmailVerifiedAt:  account.EmailVerifiedAt,
+		SuspendedAt:      account.SuspendedAt,
+		SuspensionReason: account.SuspensionReason,
+		CreatedAt:        account.CreatedAt,
+	}
+}
+
+type AdminAccounts struct {
+	Accounts []AdminAccount `json:"accounts"`
+	// NextCursor fetches the next page when passed back as cursor. It is
+	// empty on the last page.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// AuditSearch filters the audit log, newest first.
+type AuditSearch struct {
+	ActorID  string `form:"actor_id" binding:"omitempty,uuid"`
+	TargetID string `form:"target_id" binding:"omitempty,uuid"`
+	Action   string `form:"action" binding:"omitempty,max=50"`
+	Cursor   string `form:"cursor"`
+	Limit    int    `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+type GetAuditEntry struct {
+	EntryID    uuid.UUID `json:"entry_id"`
+	ActorID    uuid.UUID `json:"actor_id"`
+	Action     string    `json:"action"`
+	TargetType string    `json:"target_type"`
+	TargetID   uuid.UUID `json:"target_id"`
+	Reason     string    `json:"reason"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type AuditLog struct {
+	Entries    []GetAuditEntry `json:"entries"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+}
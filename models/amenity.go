@@ -0,0 +1,48 @@
+package models
+
+import (
+	"errors"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var (
+	ErrInvalidAmenityKey = errors.New("key must be lowercase letters, digits and underscores")
+
+	amenityKey = regexp.MustCompile(`^[a-z0-9_]+$`)
+)
+
+type CreateAmenity struct {
+	Key  string `json:"key" binding:"required,max=50" example:"hot_tub"`
+	Name string `json:"name" binding:"required,max=100" example:"Hot tub"`
+}
+
+type UpdateAmenity struct {
+	Name string `json:"name" binding:"required,max=100" example:"Hot tub"`
+}
+
+type GetAmenity struct {
+	Key  string `json:"key"`
+	Name string `json:"name"`
+}
+
+// ValidateAmenityKey accepts keys that read well in a query string.
+func ValidateAmenityKey(key string) error {
+	if !amenityKey.MatchString(key) {
+		return ErrInvalidAmenityKey
+	}
+	return nil
+}
+
+// NewGetAmenities describes amenities sorted by name.
+func NewGetAmenities(amenities []Amenity) []GetAmenity {
+	response := make([]GetAmenity, 0, len(amenities))
+	for _, amenity := range amenities {
+		response = append(response, GetAmenity{Key: amenity.Key, Name: amenity.Name})
+	}
+	slices.SortFunc(response, func(a, b GetAmenity) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return response
+}
@@ -1,6 +1,118 @@
 package models
 
-import "github.com/google/uuid"
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DateLayout is the format used for check-in and check-out dates in requests and responses.
+const DateLayout = "2006-01-02"
+
+// MaxCalendarNights bounds how many nights a single availability request can span.
+const MaxCalendarNights = 366
+
+// MaxStayNights bounds how many nights a single booking or quote can span.
+const MaxStayNights = 365
+
+// Night statuses reported by the availability calendar.
+const (
+	NightAvailable = "available"
+	NightBooked    = "booked"
+	NightBlocked   = "blocked"
+	// NightUnbookable is a free night the property's StayRules put out of
+	// reach; the night's reason says which rule.
+	NightUnbookable = "unbookable"
+)
+
+var (
+	ErrInvalidDate        = errors.New("dates must be in YYYY-MM-DD format")
+	ErrInvalidDateRange   = errors.New("end date must be after start date")
+	ErrCheckInInThePast   = errors.New("check_in cannot be in the past")
+	ErrCalendarRangeLimit = errors.New("date range cannot exceed 366 nights")
+	ErrStayTooLong        = errors.New("stays cannot exceed 365 nights")
+)
+
+type CreateBooking struct {
+	CheckIn  string `json:"check_in" binding:"required,datetime=2006-01-02" example:"2025-01-10"`
+	CheckOut string `json:"check_out" binding:"required,datetime=2006-01-02" example:"2025-01-15"`
+	Guests   int    `json:"guests" binding:"min=1" example:"2"`
+}
+
+// ParseDateRange parses a check-in/check-out pair and rejects inverted ranges,
+// check-in dates before today and stays longer than MaxStayNights.
+func ParseDateRange(checkIn, checkOut string, now time.Time) (time.Time, time.Time, error) {
+	in, out, err := ParseDates(checkIn, checkOut)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if in.Before(Today(now)) {
+		return time.Time{}, time.Time{}, ErrCheckInInThePast
+	}
+	if out.After(in.AddDate(0, 0, MaxStayNights)) {
+		return time.Time{}, time.Time{}, ErrStayTooLong
+	}
+	return in, out, nil
+}
+
+// Today is now's date at midnight UTC, the form booking dates take.
+func Today(now time.Time) time.Time {
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// ParseDates parses a start/end pair and checks that end is after start,
+// without restricting either date to the future.
+func ParseDates(start, end string) (time.Time, time.Time, error) {
+	from, err := time.Parse(DateLayout, start)
+	if err != nil {
+		return time.Time{}, time.Time{}, ErrInvalidDate
+	}
+	to, err := time.Parse(DateLayout, end)
+	if err != nil {
+		return time.Time{}, time.Time{}, ErrInvalidDate
+	}
+	if !to.After(from) {
+		return time.Time{}, time.Time{}, ErrInvalidDateRange
+	}
+	return from, to, nil
+}
+
+type BlockDates struct {
+	StartDate string `json:"start_date" binding:"required,datetime=2006-01-02" example:"2025-02-01"`
+	EndDate   string `json:"end_date" binding:"required,datetime=2006-01-02" example:"2025-02-05"`
+	Reason    string `json:"reason" binding:"max=255" example:"maintenance"`
+}
+
+type NightAvailability struct {
+	Date   string `json:"date"`
+	Status string `json:"status"`
+	// Reason is set on unbookable nights: past, advance_notice,
+	// booking_window, min_nights or check_in_day.
+	Reason string `json:"reason,omitempty"`
+}
+
+type PropertyAvailability struct {
+	PropertyID uuid.UUID           `json:"property_id"`
+	From       string              `json:"from"`
+	To         string              `json:"to"`
+	Nights     []NightAvailability `json:"nights"`
+}
+
+type CancelBooking struct {
+	Reason string `json:"reason" binding:"max=500" example:"change of plans"`
+}
+
+type CancelledBooking struct {
+	Message            string    `json:"message"`
+	BookingID          uuid.UUID `json:"booking_id"`
+	Currency           string    `json:"currency"`
+	TotalPrice         int64     `json:"total_price"`
+	RefundAmount       int64     `json:"refund_amount"`
+	CancellationReason string    `json:"cancellation_reason"`
+	CancelledByID      uuid.UUID `json:"cancelled_by_id"`
+	CancelledByRole    string    `json:"cancelled_by_role"`
+}
 
 type UpdateBooking struct {
 	CheckIn  string
@@ -12,6 +124,11 @@ type UserGetBooking struct {
 	BookingID    uuid.UUID `json:"booking_id"`
 	PropertyID   uuid.UUID `json:"property_id"`
 	PropertyName string    `json:"property_name"`
+	CheckIn      time.Time `json:"check_in"`
+	CheckOut     time.Time `json:"check_out"`
+	Guests       int       `json:"guests"`
+	Currency     string    `json:"currency"`
+	TotalPrice   int64     `json:"total_price"`
 	Status       string    `json:"status"`
 }
 
@@ -23,6 +140,11 @@ type PropertyBooking struct {
 	BookingID  uuid.UUID `json:"booking_id"`
 	PropertyID uuid.UUID `json:"property_id"`
 	UserID     uuid.UUID `json:"user_id"`
+	CheckIn    time.Time `json:"check_in"`
+	CheckOut   time.Time `json:"check_out"`
+	Guests     int       `json:"guests"`
+	Currency   string    `json:"currency"`
+	TotalPrice int64     `json:"total_price"`
 	Status     string    `json:"status"`
 }
 
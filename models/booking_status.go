@@ -0,0 +1,47 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// bookingTransitions lists, for each booking status, the statuses it may move to.
+// Declined, expired, cancelled and completed are terminal.
+var bookingTransitions = map[string][]string{
+	Pending:   {Confirmed, Declined, Expired, Cancelled},
+	Confirmed: {CheckedIn, Cancelled},
+	CheckedIn: {Completed},
+}
+
+// CanTransition reports whether a booking in status from may move to status to.
+func CanTransition(from, to string) bool {
+	for _, next := range bookingTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// TransitionError is returned when a booking status change is not allowed by
+// the booking lifecycle.
+type TransitionError struct {
+	From string
+	To   string
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("cannot move booking from %s to %s", e.From, e.To)
+}
+
+// Actor identifies who changed a booking's status. ID is nil for system and
+// anonymous actors.
+type Actor struct {
+	ID   *uuid.UUID
+	Role string
+}
+
+func NewActor(id uuid.UUID, role string) Actor {
+	return Actor{ID: &id, Role: role}
+}
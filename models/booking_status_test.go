@@ -0,0 +1,23 @@
+package models
+
+import "testing"
+
+func TestCanTransition(t *testing.T) {
+	statuses := []string{Pending, Confirmed, Declined, Expired, Cancelled, CheckedIn, Completed}
+	allowed := map[[2]string]bool{
+		{Pending, Confirmed}:   true,
+		{Pending, Declined}:    true,
+		{Pending, Expired}:     true,
+		{Pending, Cancelled}:   true,
+		{Confirmed, CheckedIn}: true,
+		{Confirmed, Cancelled}: true,
+		{CheckedIn, Completed}: true,
+	}
+	for _, from := range statuses {
+		for _, to := range statuses {
+			if got, want := CanTransition(from, to), allowed[[2]string{from, to}]; got != want {
+				t.Errorf("CanTransition(%s, %s) = %v, want %v", from, to, got, want)
+			}
+		}
+	}
+}
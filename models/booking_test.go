@@ -0,0 +1,30 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseDateRange(t *testing.T) {
+	now := time.Date(2030, 1, 7, 15, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		name              string
+		checkIn, checkOut string
+		want              error
+	}{
+		{"one night from today", "2030-01-07", "2030-01-08", nil},
+		{"longest stay", "2030-01-07", "2031-01-07", nil},
+		{"a night too long", "2030-01-07", "2031-01-08", ErrStayTooLong},
+		{"far future check-out", "2030-01-07", "9999-01-01", ErrStayTooLong},
+		{"past", "2030-01-06", "2030-01-08", ErrCheckInInThePast},
+		{"same day", "2030-01-08", "2030-01-08", ErrInvalidDateRange},
+		{"inverted", "2030-01-09", "2030-01-08", ErrInvalidDateRange},
+		{"not a date", "2030-1-9", "2030-01-10", ErrInvalidDate},
+	} {
+		_, _, err := ParseDateRange(tc.checkIn, tc.checkOut, now)
+		if !errors.Is(err, tc.want) {
+			t.Errorf("%s: err = %v, want %v", tc.name, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// Cancellation policies a property can offer its guests.
+const (
+	FlexiblePolicy = "flexible"
+	ModeratePolicy = "moderate"
+	StrictPolicy   = "strict"
+)
+
+var ErrInvalidCancellationPolicy = errors.New("cancellation_policy must be one of flexible, moderate or strict")
+
+// refundTier refunds Percent of the booking total when the guest cancels at
+// least DaysBefore days ahead of check-in.
+type refundTier struct {
+	DaysBefore int
+	Percent    int64
+}
+
+// cancellationPolicies lists each policy's tiers from most to least generous.
+// Cancelling later than the last tier refunds nothing.
+var cancellationPolicies = map[string][]refundTier{
+	FlexiblePolicy: {{DaysBefore: 1, Percent: 100}},
+	ModeratePolicy: {{DaysBefore: 5, Percent: 100}, {DaysBefore: 0, Percent: 50}},
+	StrictPolicy:   {{DaysBefore: 14, Percent: 100}, {DaysBefore: 7, Percent: 50}},
+}
+
+// NormalizeCancellationPolicy defaults an empty policy to flexible and checks
+// that it is known.
+func NormalizeCancellationPolicy(policy string) (string, error) {
+	if policy == "" {
+		return FlexiblePolicy, nil
+	}
+	if _, ok := cancellationPolicies[policy]; !ok {
+		return "", ErrInvalidCancellationPolicy
+	}
+	return policy, nil
+}
+
+// RefundAmount returns how much of the booking total is refunded if the
+// booking is cancelled at now by someone with the given role. Cancellations by
+// hosts and admins, and guests cancelling a booking that was never confirmed,
+// are always refunded in full; otherwise the property's policy applies.
+func RefundAmount(policy string, booking *Booking, role string, now time.Time) int64 {
+	if role != GuestRole || booking.Status == Pending {
+		return booking.TotalPrice
+	}
+	hoursBefore := booking.CheckIn.Sub(now).Hours()
+	for _, tier := range cancellationPolicies[policy] {
+		if hoursBefore >= float64(tier.DaysBefore*24) {
+			return booking.TotalPrice * tier.Percent / 100
+		}
+	}
+	return 0
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRefundAmount(t *testing.T) {
+	checkIn := time.Date(2030, 1, 15, 0, 0, 0, 0, time.UTC)
+	before := func(d time.Duration) time.Time { return checkIn.Add(-d) }
+	day := 24 * time.Hour
+	cases := []struct {
+		name   string
+		policy string
+		status string
+		role   string
+		total  int64
+		now    time.Time
+		want   int64
+	}{
+		{name: "flexible a day ahead", policy: FlexiblePolicy, now: before(day), want: 10000},
+		{name: "flexible under a day ahead", policy: FlexiblePolicy, now: before(day - time.Hour), want: 0},
+		{name: "moderate five days ahead", policy: ModeratePolicy, now: before(5 * day), want: 10000},
+		{name: "moderate under five days ahead", policy: ModeratePolicy, now: before(5*day - time.Hour), want: 5000},
+		{name: "moderate at check-in", policy: ModeratePolicy, now: checkIn, want: 5000},
+		{name: "moderate after check-in", policy: ModeratePolicy, now: checkIn.Add(time.Hour), want: 0},
+		{name: "moderate half rounds down", policy: ModeratePolicy, total: 9999, now: before(day), want: 4999},
+		{name: "strict fourteen days ahead", policy: StrictPolicy, now: before(14 * day), want: 10000},
+		{name: "strict under fourteen days ahead", policy: StrictPolicy, now: before(14*day - time.Hour), want: 5000},
+		{name: "strict seven days ahead", policy: StrictPolicy, now: before(7 * day), want: 5000},
+		{name: "strict under seven days ahead", policy: StrictPolicy, now: before(7*day - time.Hour), want: 0},
+		{name: "guest cancels pending booking", policy: StrictPolicy, status: Pending, now: before(time.Hour), want: 10000},
+		{name: "host cancels", policy: StrictPolicy, role: HostRole, now: before(time.Hour), want: 10000},
+		{name: "admin cancels", policy: StrictPolicy, role: AdminRole, now: checkIn.Add(time.Hour), want: 10000},
+		{name: "unknown policy", policy: "lenient", now: before(30 * day), want: 0},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			booking := &Booking{CheckIn: checkIn, CheckOut: checkIn.Add(3 * day), TotalPrice: tc.total, Status: tc.status}
+			if booking.TotalPrice == 0 {
+				booking.TotalPrice = 10000
+			}
+			if booking.Status == "" {
+				booking.Status = Confirmed
+			}
+			role := tc.role
+			if role == "" {
+				role = GuestRole
+			}
+			if got := RefundAmount(tc.policy, booking, role, tc.now); got != tc.want {
+				t.Fatalf("RefundAmount = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
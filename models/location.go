@@ -0,0 +1,109 @@
+package models
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	DefaultRadiusKm = 10
+	MaxRadiusKm     = 500
+)
+
+var (
+	ErrInvalidAddress     = errors.New("address must include a city and a country")
+	ErrInvalidCoordinates = errors.New("latitude must be between -90 and 90 and longitude between -180 and 180, and both must be set together")
+	ErrInvalidRadius      = errors.New("radius_km must be greater than 0 and at most 500")
+	ErrInvalidBoundingBox = errors.New("min_lat, min_lng, max_lat and max_lng must all be set, with min_lat below max_lat")
+)
+
+// Address is a property's structured postal address. It is embedded in the
+// properties table and used as-is in requests and responses.
+type Address struct {
+	Street     string `json:"street" gorm:"size:255" binding:"max=255"`
+	City       string `json:"city" gorm:"size:100" binding:"required,max=100"`
+	Region     string `json:"region" gorm:"size:100" binding:"max=100"`
+	Country    string `json:"country" gorm:"size:100" binding:"required,max=100"`
+	PostalCode string `json:"postal_code" gorm:"size:20" binding:"max=20"`
+}
+
+func (a Address) Validate() error {
+	if strings.TrimSpace(a.City) == "" || strings.TrimSpace(a.Country) == "" {
+		return ErrInvalidAddress
+	}
+	return nil
+}
+
+// String formats the address on one line, skipping empty parts.
+func (a Address) String() string {
+	var parts []string
+	for _, part := range []string{a.Street, a.City, a.Region, a.PostalCode, a.Country} {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ValidateCoordinates accepts either no coordinates or a valid pair.
+func ValidateCoordinates(lat, lng *float64) error {
+	if lat == nil && lng == nil {
+		return nil
+	}
+	if lat == nil || lng == nil || *lat < -90 || *lat > 90 || *lng < -180 || *lng > 180 {
+		return ErrInvalidCoordinates
+	}
+	return nil
+}
+
+type NearbySearch struct {
+	Latitude  *float64 `form:"lat" binding:"required,gte=-90,lte=90"`
+	Longitude *float64 `form:"lng" binding:"required,gte=-180,lte=180"`
+	RadiusKm  float64  `form:"radius_km" binding:"gte=0,lte=500"`
+	Limit     int      `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+// Normalize applies defaults and validates the search.
+func (s *NearbySearch) Normalize() error {
+	if s.Latitude == nil || s.Longitude == nil {
+		return ErrInvalidCoordinates
+	}
+	if err := ValidateCoordinates(s.Latitude, s.Longitude); err != nil {
+		return err
+	}
+	if s.RadiusKm == 0 {
+		s.RadiusKm = DefaultRadiusKm
+	}
+	if s.RadiusKm < 0 || s.RadiusKm > MaxRadiusKm {
+		return ErrInvalidRadius
+	}
+	if s.Limit == 0 {
+		s.Limit = DefaultPageSize
+	}
+	if s.Limit < 1 || s.Limit > MaxPageSize {
+		return ErrInvalidLimit
+	}
+	return nil
+}
+
+// PropertyDistance is a property found by a nearby search and its distance
+// from the search point.
+type PropertyDistance struct {
+	Property   Property
+	DistanceKm float64
+}
+
+type GetNearbyProperty struct {
+	GetProperty
+	DistanceKm float64 `json:"distance_km"`
+}
+
+type GetNearbyProperties struct {
+	Properties []GetNearbyProperty `json:"properties"`
+}
+
+// BoundingBox is a map viewport. When MinLng is greater than MaxLng the box
+// crosses the antimeridian.
+type BoundingBox struct {
+	MinLat, MinLng, MaxLat, MaxLng float64
+}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StartConversation opens a thread with a property's host, or continues the
+// existing one, with a first message. BookingID ties the thread to one of the
+// guest's bookings of that property.
+type StartConversation struct {
+	PropertyID string `json:"property_id" binding:"required,uuid" example:"3fa85f64-5717-4562-b3fc-2c963f66afa6"`
+	BookingID  string `json:"booking_id" binding:"omitempty,uuid"`
+	Body       string `json:"body" binding:"required,max=2000" example:"Is the cabin suitable for a toddler?"`
+}
+
+type SendMessage struct {
+	Body string `json:"body" binding:"required,max=2000" example:"We will arrive around 6pm"`
+}
+
+// InboxSearch pages through conversations, most recently active first.
+type InboxSearch struct {
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+// MessageSearch pages through a conversation's messages, newest first.
+type MessageSearch struct {
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+type Participant struct {
+	AccountID uuid.UUID  `json:"account_id"`
+	Name      string     `json:"name"`
+	ReadAt    *time.Time `json:"read_at,omitempty"`
+}
+
+type GetConversation struct {
+	ConversationID uuid.UUID   `json:"conversation_id"`
+	PropertyID     uuid.UUID   `json:"property_id"`
+	PropertyName   string      `json:"property_name"`
+	BookingID      *uuid.UUID  `json:"booking_id,omitempty"`
+	Guest          Participant `json:"guest"`
+	Host           Participant `json:"host"`
+	LastMessageAt  time.Time   `json:"last_message_at"`
+	// UnreadCount is how many of the other participant's messages the
+	// signed-in account has not read.
+	UnreadCount int64 `json:"unread_count"`
+}
+
+func NewGetConversation(conversation *Conversation, unread int64) GetConversation {
+	return GetConversation{
+		ConversationID: conversation.ID,
+		PropertyID:     conversation.PropertyID,
+		PropertyName:   conversation.Property.Name,
+		BookingID:      conversation.BookingID,
+		Guest:          Participant{AccountID: conversation.GuestID, Name: conversation.Guest.Name, ReadAt: conversation.GuestReadAt},
+		Host:           Participant{AccountID: conversation.HostID, Name: conversation.Host.Name, ReadAt: conversation.HostReadAt},
+		LastMessageAt:  conversation.LastMessageAt,
+		UnreadCount:    unread,
+	}
+}
+
+type Inbox struct {
+	Conversations []GetConversation `json:"conversations"`
+	// UnreadCount totals unread messages across every conversation, not
+	// only this page.
+	UnreadCount int64 `json:"unread_count"`
+	// NextCursor fetches the next page when passed back as cursor. It is
+	// empty on the last page.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+type GetMessage struct {
+	MessageID uuid.UUID `json:"message_id"`
+	SenderID  uuid.UUID `json:"sender_id"`
+	Body      string    `json:"body"`
+	// Read reports whether the recipient has read the message.
+	Read      bool      `json:"read"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func NewGetMessage(message *Message, conversation *Conversation) GetMessage {
+	recipient := conversation.GuestID
+	if message.SenderID == conversation.GuestID {
+		recipient = conversation.HostID
+	}
+	readAt := conversation.ReadAt(recipient)
+	return GetMessage{
+		MessageID: message.ID,
+		SenderID:  message.SenderID,
+		Body:      message.Body,
+		Read:      readAt != nil && !message.CreatedAt.After(*readAt),
+		CreatedAt: message.CreatedAt,
+	}
+}
+
+type GetMessages struct {
+	Messages   []GetMessage `json:"messages"`
+	NextCursor string       `json:"next_cursor,omitempty"`
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"airbnb/pricing"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,55 +15,314 @@ type BaseModel struct {
 	DeletedAt gorm.DeletedAt `gorm:"column:deleted_at;index"`
 }
 
-type User struct {
+// Account is anyone who signs in. Its roles decide what it may do: guests book
+// properties, hosts list them and admins manage the platform. A suspended
+// account cannot sign in or use its tokens until an admin reinstates it.
+type Account struct {
 	BaseModel
-	Name     string `gorm:"size:100;not null"`
-	Email    string `gorm:"uniqueIndex;size:100;not null"`
-	Password string `gorm:"size:255;not null"`
-	Role     string `gorm:"size:100;not null"`
+	Name             string     `gorm:"size:100;not null"`
+	Email            string     `gorm:"uniqueIndex;size:100;not null"`
+	Password         string     `gorm:"size:255;not null" json:"-"` // bcrypt hash, never serialised
+	EmailVerifiedAt  *time.Time `gorm:"default:null"`
+	SuspendedAt      *time.Time `gorm:"default:null"`
+	SuspensionReason string     `gorm:"size:500"`
+	Roles            []Role     `gorm:"many2many:account_roles"`
 }
 
-type PropertyOwner struct {
+type Role struct {
+	Name string `gorm:"primaryKey;size:50"`
+}
+
+// HasRole reports whether the account holds role.
+func (a *Account) HasRole(role string) bool {
+	for _, r := range a.Roles {
+		if r.Name == role {
+			return true
+		}
+	}
+	return false
+}
+
+// IsVerified reports whether the account has confirmed its email address.
+func (a *Account) IsVerified() bool {
+	return a.EmailVerifiedAt != nil
+}
+
+// IsSuspended reports whether an admin has suspended the account.
+func (a *Account) IsSuspended() bool {
+	return a.SuspendedAt != nil
+}
+
+// RoleNames returns the names of the account's roles.
+func (a *Account) RoleNames() []string {
+	names := make([]string, 0, len(a.Roles))
+	for _, r := range a.Roles {
+		names = append(names, r.Name)
+	}
+	return names
+}
+
+// RefreshToken is stored as a SHA-256 hash of the token handed to the client.
+// Refreshing revokes it and issues its replacement in the same family, so a
+// revoked token coming back means it leaked and the whole family is revoked.
+// AccessJTI is the access token issued alongside it, which is denylisted when
+// the token is revoked.
+type RefreshToken struct {
+	BaseModel
+	AccountID       uuid.UUID  `gorm:"type:uuid;not null;index"`
+	FamilyID        uuid.UUID  `gorm:"type:uuid;not null;index"`
+	TokenHash       string     `gorm:"size:64;not null;uniqueIndex"`
+	AccessJTI       string     `gorm:"column:access_jti;size:64;not null"`
+	AccessExpiresAt time.Time  `gorm:"not null"`
+	ExpiresAt       time.Time  `gorm:"not null;index"`
+	RevokedAt       *time.Time `gorm:"default:null"`
+	ReplacedByID    *uuid.UUID `gorm:"type:uuid"`
+}
+
+// AccountToken is a single-use token emailed to the account holder, stored as
+// a SHA-256 hash. Purpose says what it may be redeemed for.
+type AccountToken struct {
 	BaseModel
-	Name       string     `gorm:"size:100;not null"`
-	Email      string     `gorm:"uniqueIndex;size:100;not null"`
-	Password   string     `gorm:"size:255;not null"`
-	Role       string     `gorm:"size:100;not null"`
-	Properties []Property `gorm:"foreignKey:OwnerID"` // one-to-many relation
+	AccountID uuid.UUID  `gorm:"type:uuid;not null;index"`
+	Purpose   string     `gorm:"size:50;not null"`
+	TokenHash string     `gorm:"size:64;not null;uniqueIndex"`
+	ExpiresAt time.Time  `gorm:"not null;index"`
+	UsedAt    *time.Time `gorm:"default:null"`
+}
+
+// RevokedToken denylists an access token by jti until it expires on its own.
+type RevokedToken struct {
+	JTI       string    `gorm:"column:jti;primaryKey;size:64"`
+	ExpiresAt time.Time `gorm:"not null;index"`
 }
 
 type Property struct {
 	BaseModel
-	Name        string        `gorm:"size:100;not null"`
-	Description string        `gorm:"size:500"`
-	Price       int64         `gorm:"not null"`
-	Location    string        `gorm:"not null"`
-	OwnerID     uuid.UUID     `gorm:"type:uuid;not null"`               // foreign key
-	Owner       PropertyOwner `gorm:"foreignKey:OwnerID;references:ID"` // GORM association
+	Name               string     `gorm:"size:100;not null"`
+	Description        string     `gorm:"size:500"`
+	Price              int64      `gorm:"not null"` // nightly price in minor units of Currency
+	CleaningFee        int64      `gorm:"not null;default:0"`
+	WeeklyDiscount     int64      `gorm:"not null;default:0"` // basis points off stays of WeeklyStayNights or more
+	MonthlyDiscount    int64      `gorm:"not null;default:0"` // basis points off stays of MonthlyStayNights or more
+	Currency           string     `gorm:"size:3;not null;default:USD"`
+	CancellationPolicy string     `gorm:"size:50;not null;default:flexible"`
+	PropertyType       string     `gorm:"size:20;not null;default:entire_home"`
+	MaxGuests          int        `gorm:"not null;default:1"`
+	Bedrooms           int        `gorm:"not null;default:0"`
+	Beds               int        `gorm:"not null;default:1"`
+	Bathrooms          float64    `gorm:"not null"` // in steps of 0.5
+	RatingAverage      float64    `gorm:"not null;default:0"`
+	RatingCount        int        `gorm:"not null;default:0"`
+	Location           string     `gorm:"not null"` // formatted Address, kept for text search
+	Address            Address    `gorm:"embedded"`
+	Latitude           *float64   `gorm:"type:double precision"`
+	Longitude          *float64   `gorm:"type:double precision"`
+	HouseRules         HouseRules `gorm:"embedded"`
+	StayRules          StayRules  `gorm:"embedded"`
+	CheckInTime        string     `gorm:"size:5;not null;default:15:00"` // local time at the property, HH:MM
+	CheckOutTime       string     `gorm:"size:5;not null;default:11:00"`
+	UnlistedAt         *time.Time `gorm:"default:null"`                     // set by an admin to hide the property from guests
+	OwnerID            uuid.UUID  `gorm:"type:uuid;not null;index"`         // foreign key
+	Owner              Account    `gorm:"foreignKey:OwnerID;references:ID"` // GORM association
+	Photos             []Photo    `gorm:"foreignKey:PropertyID"`            // ordered by Position when preloaded
+	Amenities          []Amenity  `gorm:"many2many:property_amenities"`
+	// PriceRules are only loaded where the property is priced, in the order
+	// they were added.
+	PriceRules []PriceRule `gorm:"foreignKey:PropertyID"`
+}
+
+// Amenity is an entry in the catalogue of features admins maintain for hosts
+// to pick from. Key is the stable identifier clients filter by; only Name can
+// change.
+type Amenity struct {
+	Key       string    `gorm:"size:50;primaryKey"`
+	Name      string    `gorm:"size:100;not null"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
 }
 
 type Booking struct {
 	BaseModel
-	UserID     uuid.UUID `gorm:"type:uuid;not null"`
-	PropertyID uuid.UUID `gorm:"type:uuid;not null"`
-	CheckIn    string    `gorm:"default:null"`
-	CheckOut   string    `gorm:"default:null"`
-	Status     string    `gorm:"size:50;not null"` // pending, confirmed
-	User       User      `gorm:"foreignKey:UserID;references:ID"`
+	UserID             uuid.UUID  `gorm:"type:uuid;not null;index"`
+	PropertyID         uuid.UUID  `gorm:"type:uuid;not null;index"`
+	CheckIn            time.Time  `gorm:"type:date;not null"`
+	CheckOut           time.Time  `gorm:"type:date;not null"`
+	Guests             int        `gorm:"not null;default:1"`
+	Currency           string     `gorm:"size:3;not null"` // price breakdown below is frozen at booking time
+	NightlyTotal       int64      `gorm:"not null"`
+	Discount           int64      `gorm:"not null;default:0"` // length-of-stay discount taken off NightlyTotal
+	CleaningFee        int64      `gorm:"not null"`
+	ServiceFee         int64      `gorm:"not null"`
+	Tax                int64      `gorm:"not null"`
+	TotalPrice         int64      `gorm:"not null"`
+	Status             string     `gorm:"size:50;not null"` // see bookingTransitions for the lifecycle
+	RefundAmount       int64      `gorm:"not null;default:0"`
+	CancellationReason string     `gorm:"size:500"`
+	CancelledByID      *uuid.UUID `gorm:"type:uuid"`
+	CancelledByRole    string     `gorm:"size:100"`
+	CancelledAt        *time.Time `gorm:"default:null"`
+	User               Account    `gorm:"foreignKey:UserID;references:ID"`
+	Property           Property   `gorm:"foreignKey:PropertyID;references:ID"`
+}
+
+// ApplyQuote freezes a quote's price breakdown onto the booking.
+func (b *Booking) ApplyQuote(q Quote) {
+	b.Currency = q.Currency
+	b.NightlyTotal = q.Amount(LineNightly)
+	b.Discount = -q.Amount(LineDiscount)
+	b.CleaningFee = q.Amount(LineCleaning)
+	b.ServiceFee = q.Amount(LineServiceFee)
+	b.Tax = q.Amount(LineTax)
+	b.TotalPrice = q.Total
+}
+
+// PriceRule changes a property's nightly price. A PriceOverride rule sets the
+// price of the nights from StartDate up to EndDate; a PriceWeekdays rule
+// adjusts the base price on Weekdays by AdjustmentBasisPoints. See package
+// pricing for how rules that cover the same night combine.
+type PriceRule struct {
+	BaseModel
+	PropertyID            uuid.UUID        `gorm:"type:uuid;not null;index"`
+	Kind                  string           `gorm:"size:20;not null"`
+	StartDate             *time.Time       `gorm:"type:date"`
+	EndDate               *time.Time       `gorm:"type:date"`
+	Price                 int64            `gorm:"not null;default:0"`
+	Weekdays              pricing.Weekdays `gorm:"not null;default:0"`
+	AdjustmentBasisPoints int64            `gorm:"not null;default:0"`
+}
+
+// BlockedDate is a range of nights an owner has taken off the market, e.g. for
+// maintenance. Like a booking, EndDate is the first night that is free again.
+type BlockedDate struct {
+	BaseModel
+	PropertyID uuid.UUID `gorm:"type:uuid;not null;index"`
+	StartDate  time.Time `gorm:"type:date;not null"`
+	EndDate    time.Time `gorm:"type:date;not null"`
+	Reason     string    `gorm:"size:255"`
 	Property   Property  `gorm:"foreignKey:PropertyID;references:ID"`
 }
 
+// BookingTransition records a single status change of a booking and who made it.
+// FromStatus is empty for the transition that creates the booking.
+type BookingTransition struct {
+	BaseModel
+	BookingID  uuid.UUID  `gorm:"type:uuid;not null;index"`
+	FromStatus string     `gorm:"size:50"`
+	ToStatus   string     `gorm:"size:50;not null"`
+	ActorID    *uuid.UUID `gorm:"type:uuid"`
+	ActorRole  string     `gorm:"size:100;not null"`
+}
+
+// Review is left by one side of a stay about the other: a guest rates the
+// property, a host rates the guest. SubjectID is the account reviewed, the
+// property's owner for guest reviews. The host may reply once to a guest's
+// review of their property.
+type Review struct {
+	BaseModel
+	BookingID  uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_reviews_booking_author_role"`
+	AuthorRole string     `gorm:"size:50;not null;uniqueIndex:idx_reviews_booking_author_role"`
+	PropertyID uuid.UUID  `gorm:"type:uuid;not null;index"`
+	AuthorID   uuid.UUID  `gorm:"type:uuid;not null"`
+	SubjectID  uuid.UUID  `gorm:"type:uuid;not null;index"`
+	Rating     int        `gorm:"not null"`
+	Comment    string     `gorm:"size:2000;not null"`
+	Reply      string     `gorm:"size:2000"`
+	RepliedAt  *time.Time `gorm:"default:null"`
+	Author     Account    `gorm:"foreignKey:AuthorID;references:ID"`
+}
+
+// Conversation is a message thread between a guest and the host of a
+// property, about one of the guest's bookings when BookingID is set. A guest
+// has at most one thread per booking and one per property outside any
+// booking. Each side's read marker is when they last read the thread; the
+// other side's messages after it are unread.
+type Conversation struct {
+	BaseModel
+	PropertyID    uuid.UUID  `gorm:"type:uuid;not null;index"`
+	BookingID     *uuid.UUID `gorm:"type:uuid;default:null;index"`
+	GuestID       uuid.UUID  `gorm:"type:uuid;not null;index"`
+	HostID        uuid.UUID  `gorm:"type:uuid;not null;index"`
+	LastMessageAt time.Time  `gorm:"not null"`
+	GuestReadAt   *time.Time `gorm:"default:null"`
+	HostReadAt    *time.Time `gorm:"default:null"`
+	Property      Property   `gorm:"foreignKey:PropertyID;references:ID"`
+	Guest         Account    `gorm:"foreignKey:GuestID;references:ID"`
+	Host          Account    `gorm:"foreignKey:HostID;references:ID"`
+}
+
+// ReadAt returns when the participant accountID last read the conversation.
+func (c *Conversation) ReadAt(accountID uuid.UUID) *time.Time {
+	if accountID == c.GuestID {
+		return c.GuestReadAt
+	}
+	return c.HostReadAt
+}
+
+type Message struct {
+	BaseModel
+	ConversationID uuid.UUID `gorm:"type:uuid;not null;index"`
+	SenderID       uuid.UUID `gorm:"type:uuid;not null"`
+	Body           string    `gorm:"size:2000;not null"`
+}
+
+// Wishlist is a guest's named list of saved properties. While the list is
+// shared, ShareTokenHash holds the hash of the secret in its read-only link.
+type Wishlist struct {
+	BaseModel
+	GuestID        uuid.UUID      `gorm:"type:uuid;not null;index"`
+	Name           string         `gorm:"size:100;not null"`
+	ShareTokenHash *string        `gorm:"size:64;uniqueIndex"`
+	Items          []WishlistItem `gorm:"foreignKey:WishlistID"`
+}
+
+// WishlistItem saves a property to a wishlist. Items are deleted with their
+// property so a list never points at one that is gone.
+type WishlistItem struct {
+	WishlistID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	PropertyID uuid.UUID `gorm:"type:uuid;primaryKey;index"`
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+	Property   Property  `gorm:"foreignKey:PropertyID;references:ID"`
+}
+
+// Photo is an image of a property, stored in the BlobStore once per size in
+// PhotoSizes under PhotoKey. Photos are shown in Position order, from 0; the
+// first is the property's cover.
+type Photo struct {
+	BaseModel
+	PropertyID uuid.UUID `gorm:"type:uuid;not null;index"`
+	Position   int       `gorm:"not null"`
+	Width      int       `gorm:"not null"` // of the largest size
+	Height     int       `gorm:"not null"`
+}
+
+// AuditEntry records an action an admin took on another account, a booking
+// or a property, and why.
+type AuditEntry struct {
+	BaseModel
+	ActorID    uuid.UUID `gorm:"type:uuid;not null;index"`
+	Action     string    `gorm:"size:50;not null"`
+	TargetType string    `gorm:"size:50;not null"`
+	TargetID   uuid.UUID `gorm:"type:uuid;not null;index"`
+	Reason     string    `gorm:"size:500;not null"`
+}
+
 const (
-	UserRole     = "user"
-	PropertyRole = "property_owner"
-	Pending      = "pending"
-	Confirmed    = "confirmed"
-	Cancelled    = "cancelled"
+	GuestRole     = "guest"
+	HostRole      = "host"
+	AdminRole     = "admin"
+	SystemRole    = "system"
+	AnonymousRole = "anonymous"
+	Pending       = "pending"
+	Confirmed     = "confirmed"
+	Declined      = "declined"
+	Expired       = "expired"
+	Cancelled     = "cancelled"
+	CheckedIn     = "checked_in"
+	Completed     = "completed"
 )
 
 func (b *BaseModel) BeforeCreate(tx *gorm.DB) (err error) {
-    if b.ID == uuid.Nil {
-        b.ID = uuid.New()
-    }
-    return
+	if b.ID == uuid.Nil {
+		b.ID = uuid.New()
+	}
+	return
 }
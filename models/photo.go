@@ -0,0 +1,76 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxPhotoBytes caps the size of an uploaded photo file.
+	MaxPhotoBytes = 10 << 20
+	// MaxPropertyPhotos caps how many photos one property can have.
+	MaxPropertyPhotos = 30
+)
+
+// PhotoSize is one of the copies stored for every photo, scaled down so
+// neither side exceeds MaxEdge pixels.
+type PhotoSize struct {
+	Name    string
+	MaxEdge int
+}
+
+// PhotoSizes are ordered from largest to smallest.
+var PhotoSizes = []PhotoSize{
+	{Name: "large", MaxEdge: 2048},
+	{Name: "medium", MaxEdge: 1024},
+	{Name: "small", MaxEdge: 320},
+}
+
+// PhotoKey is the BlobStore key of one size of a photo.
+func PhotoKey(propertyID, photoID uuid.UUID, size string) string {
+	return fmt.Sprintf("properties/%s/photos/%s/%s.jpg", propertyID, photoID, size)
+}
+
+// ReorderPhotos lists every photo of a property, once, in the new order.
+type ReorderPhotos struct {
+	PhotoIDs []string `json:"photo_ids" binding:"required,min=1,max=30,dive,uuid"`
+}
+
+type GetPhoto struct {
+	PhotoID uuid.UUID `json:"photo_id"`
+	IsCover bool      `json:"is_cover"`
+	Width   int       `json:"width"`
+	Height  int       `json:"height"`
+	// URLs maps each size name to where that copy can be downloaded.
+	URLs map[string]string `json:"urls"`
+}
+
+// NewGetPhoto describes photo, with url giving the download URL of a
+// BlobStore key.
+func NewGetPhoto(photo *Photo, url func(key string) string) GetPhoto {
+	urls := make(map[string]string, len(PhotoSizes))
+	for _, size := range PhotoSizes {
+		urls[size.Name] = url(PhotoKey(photo.PropertyID, photo.ID, size.Name))
+	}
+	return GetPhoto{
+		PhotoID: photo.ID,
+		IsCover: photo.Position == 0,
+		Width:   photo.Width,
+		Height:  photo.Height,
+		URLs:    urls,
+	}
+}
+
+type GetPhotos struct {
+	Photos []GetPhoto `json:"photos"`
+}
+
+// NewGetPhotos describes photos, which must be in display order.
+func NewGetPhotos(photos []Photo, url func(key string) string) GetPhotos {
+	response := GetPhotos{Photos: []GetPhoto{}}
+	for _, photo := range photos {
+		response.Photos = append(response.Photos, NewGetPhoto(&photo, url))
+	}
+	return response
+}
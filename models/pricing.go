@@ -0,0 +1,161 @@
+package models
+
+import (
+	"airbnb/pricing"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Price rule kinds.
+const (
+	PriceOverride = "date_range"
+	PriceWeekdays = "weekday"
+)
+
+const (
+	// WeeklyStayNights and MonthlyStayNights are the stay lengths from which
+	// a property's weekly and monthly discounts apply.
+	WeeklyStayNights  = 7
+	MonthlyStayNights = 28
+	// MaxPriceRules caps how many price rules one property can have.
+	MaxPriceRules = 100
+	// MaxOverrideNights bounds how many nights one date-range rule spans.
+	MaxOverrideNights = 366
+)
+
+var (
+	ErrInvalidDiscount   = errors.New("discounts must be between 0 and 9000 basis points")
+	ErrInvalidPriceRule  = errors.New("a date_range rule needs start_date, end_date and price; a weekday rule needs weekdays and adjustment")
+	ErrInvalidWeekday    = errors.New("weekdays must be mon, tue, wed, thu, fri, sat or sun")
+	ErrInvalidAdjustment = errors.New("adjustment must be between -9000 and 10000 basis points and not 0")
+	ErrOverrideTooLong   = errors.New("a date_range rule can span at most 366 nights")
+)
+
+var weekdayNames = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// ValidateDiscount accepts a discount of up to 90%, in basis points.
+func ValidateDiscount(bps int64) error {
+	if bps < 0 || bps > 9000 {
+		return ErrInvalidDiscount
+	}
+	return nil
+}
+
+// CreatePriceRule adds either a date_range rule, which sets the price of the
+// nights from start_date up to end_date, or a weekday rule, which adjusts the
+// base price on the given weekdays by adjustment basis points.
+type CreatePriceRule struct {
+	Kind      string `json:"kind" binding:"required,oneof=date_range weekday" example:"weekday"`
+	StartDate string `json:"start_date" binding:"omitempty,datetime=2006-01-02" example:"2025-12-20"`
+	EndDate   string `json:"end_date" binding:"omitempty,datetime=2006-01-02" example:"2026-01-03"`
+	Price     int64  `json:"price" binding:"gte=0" example:"25000"`
+	// Weekdays are three-letter names, e.g. ["fri","sat"].
+	Weekdays   []string `json:"weekdays" binding:"max=7" example:"fri,sat"`
+	Adjustment int64    `json:"adjustment" example:"2000"`
+}
+
+// NewPriceRule validates the request and returns the rule it describes for
+// propertyID.
+func (c CreatePriceRule) NewPriceRule(propertyID uuid.UUID) (*PriceRule, error) {
+	rule := &PriceRule{PropertyID: propertyID, Kind: c.Kind}
+	switch c.Kind {
+	case PriceOverride:
+		if c.StartDate == "" || c.EndDate == "" || c.Price <= 0 || len(c.Weekdays) > 0 || c.Adjustment != 0 {
+			return nil, ErrInvalidPriceRule
+		}
+		start, end, err := ParseDates(c.StartDate, c.EndDate)
+		if err != nil {
+			return nil, err
+		}
+		if end.Sub(start) > MaxOverrideNights*24*time.Hour {
+			return nil, ErrOverrideTooLong
+		}
+		rule.StartDate, rule.EndDate, rule.Price = &start, &end, c.Price
+	case PriceWeekdays:
+		if len(c.Weekdays) == 0 || c.StartDate != "" || c.EndDate != "" || c.Price != 0 {
+			return nil, ErrInvalidPriceRule
+		}
+		if c.Adjustment == 0 || c.Adjustment < -9000 || c.Adjustment > 10000 {
+			return nil, ErrInvalidAdjustment
+		}
+		for _, name := range c.Weekdays {
+			day := slices.Index(weekdayNames, name)
+			if day < 0 {
+				return nil, ErrInvalidWeekday
+			}
+			rule.Weekdays |= pricing.NewWeekdays(time.Weekday(day))
+		}
+		rule.AdjustmentBasisPoints = c.Adjustment
+	default:
+		return nil, ErrInvalidPriceRule
+	}
+	return rule, nil
+}
+
+type GetPriceRule struct {
+	RuleID     uuid.UUID `json:"rule_id"`
+	Kind       string    `json:"kind"`
+	StartDate  string    `json:"start_date,omitempty"`
+	EndDate    string    `json:"end_date,omitempty"`
+	Price      int64     `json:"price,omitempty"`
+	Weekdays   []string  `json:"weekdays,omitempty"`
+	Adjustment int64     `json:"adjustment,omitempty"`
+}
+
+func NewGetPriceRule(rule *PriceRule) GetPriceRule {
+	response := GetPriceRule{RuleID: rule.ID, Kind: rule.Kind, Price: rule.Price, Adjustment: rule.AdjustmentBasisPoints}
+	if rule.StartDate != nil && rule.EndDate != nil {
+		response.StartDate, response.EndDate = rule.StartDate.Format(DateLayout), rule.EndDate.Format(DateLayout)
+	}
+	for _, day := range rule.Weekdays.Days() {
+		response.Weekdays = append(response.Weekdays, weekdayNames[day])
+	}
+	return response
+}
+
+// GetPricing is everything that sets a property's nightly prices.
+type GetPricing struct {
+	Price           int64          `json:"price"`
+	Currency        string         `json:"currency"`
+	WeeklyDiscount  int64          `json:"weekly_discount"`
+	MonthlyDiscount int64          `json:"monthly_discount"`
+	Rules           []GetPriceRule `json:"rules"`
+}
+
+func NewGetPricing(property *Property, rules []PriceRule) GetPricing {
+	response := GetPricing{
+		Price:           property.Price,
+		Currency:        property.Currency,
+		WeeklyDiscount:  property.WeeklyDiscount,
+		MonthlyDiscount: property.MonthlyDiscount,
+		Rules:           []GetPriceRule{},
+	}
+	for _, rule := range rules {
+		response.Rules = append(response.Rules, NewGetPriceRule(&rule))
+	}
+	return response
+}
+
+// PricingRules gathers the property's price, discounts and loaded PriceRules
+// for the pricing engine.
+func (p *Property) PricingRules() pricing.Rules {
+	rules := pricing.Rules{
+		BasePrice: p.Price,
+		Discounts: []pricing.StayDiscount{
+			{MinNights: WeeklyStayNights, BasisPoints: p.WeeklyDiscount},
+			{MinNights: MonthlyStayNights, BasisPoints: p.MonthlyDiscount},
+		},
+	}
+	for _, rule := range p.PriceRules {
+		switch rule.Kind {
+		case PriceOverride:
+			rules.Overrides = append(rules.Overrides, pricing.Override{Start: *rule.StartDate, End: *rule.EndDate, Price: rule.Price})
+		case PriceWeekdays:
+			rules.Adjustments = append(rules.Adjustments, pricing.WeekdayAdjustment{Weekdays: rule.Weekdays, BasisPoints: rule.AdjustmentBasisPoints})
+		}
+	}
+	return rules
+}
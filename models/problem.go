@@ -0,0 +1,15 @@
+package models
+
+// Problem is the body of every error response, an RFC 7807 problem details
+// object sent as application/problem+json. Code identifies the error and is
+// stable, so clients should match on it rather than on Detail. Errors lists
+// the invalid fields of a validation_failed problem and the broken rules of a
+// stay_not_allowed one.
+type Problem struct {
+	Type   string       `json:"type" example:"about:blank"`
+	Title  string       `json:"title" example:"Not Found"`
+	Status int          `json:"status" example:"404"`
+	Detail string       `json:"detail" example:"property not found"`
+	Code   string       `json:"code" example:"property_not_found"`
+	Errors []FieldError `json:"errors,omitempty"`
+}
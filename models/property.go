@@ -1,11 +1,100 @@
 package models
 
-import "github.com/google/uuid"
+import (
+	"errors"
+	"math"
+	"slices"
+	"strings"
+	"time"
 
-type CreatePropertyOwner struct {
-	Name     string `json:"name"`
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidPropertyName = errors.New("property_name must be between 1 and 100 characters")
+	ErrInvalidDescription  = errors.New("description must be at most 500 characters")
+	ErrInvalidPrice        = errors.New("price must be greater than 0")
+	ErrInvalidCleaningFee  = errors.New("cleaning_fee cannot be negative")
+	ErrInvalidMaxGuests    = errors.New("max_guests must be at least 1")
+	ErrInvalidPropertyType = errors.New("property_type must be one of entire_home, private_room or shared_room")
+	ErrInvalidBedrooms     = errors.New("bedrooms must be between 0 and 50")
+	ErrInvalidBeds         = errors.New("beds must be between 1 and 100")
+	ErrInvalidBathrooms    = errors.New("bathrooms must be between 0 and 50 in steps of 0.5")
+	ErrInvalidTimeOfDay    = errors.New("times must be given as HH:MM")
+	ErrInvalidQuietHours   = errors.New("quiet_hours_start and quiet_hours_end must be set together")
+	ErrInvalidSort         = errors.New("sort must be one of newest, price_asc, price_desc or rating")
+	ErrInvalidLimit        = errors.New("limit must be between 1 and 100")
+)
+
+// Property types.
+const (
+	EntireHome  = "entire_home"
+	PrivateRoom = "private_room"
+	SharedRoom  = "shared_room"
+)
+
+// Default check-in and check-out times.
+const (
+	DefaultCheckInTime  = "15:00"
+	DefaultCheckOutTime = "11:00"
+)
+
+// Sort orders for the public property listing.
+const (
+	SortNewest    = "newest"
+	SortPriceAsc  = "price_asc"
+	SortPriceDesc = "price_desc"
+	SortRating    = "rating"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// HouseRules are what a host allows during a stay. Quiet hours, when set, run
+// from QuietHoursStart to QuietHoursEnd and may wrap past midnight.
+type HouseRules struct {
+	PetsAllowed     bool   `json:"pets_allowed" gorm:"not null;default:false"`
+	SmokingAllowed  bool   `json:"smoking_allowed" gorm:"not null;default:false"`
+	EventsAllowed   bool   `json:"events_allowed" gorm:"not null;default:false"`
+	QuietHoursStart string `json:"quiet_hours_start" gorm:"size:5" binding:"omitempty,datetime=15:04" example:"22:00"`
+	QuietHoursEnd   string `json:"quiet_hours_end" gorm:"size:5" binding:"omitempty,datetime=15:04" example:"07:00"`
+}
+
+// Normalize validates the rules and writes quiet hours as HH:MM.
+func (r *HouseRules) Normalize() error {
+	if (r.QuietHoursStart == "") != (r.QuietHoursEnd == "") {
+		return ErrInvalidQuietHours
+	}
+	if r.QuietHoursStart == "" {
+		return nil
+	}
+	var err error
+	if r.QuietHoursStart, err = NormalizeTimeOfDay(r.QuietHoursStart); err != nil {
+		return err
+	}
+	r.QuietHoursEnd, err = NormalizeTimeOfDay(r.QuietHoursEnd)
+	return err
+}
+
+// NormalizeTimeOfDay parses a 24-hour time such as 9:30 or 09:30 and returns
+// it as HH:MM.
+func NormalizeTimeOfDay(value string) (string, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return "", ErrInvalidTimeOfDay
+	}
+	return t.Format("15:04"), nil
+}
+
+// ValidateBathrooms accepts counts from 0 to 50 in half steps, a half being
+// a bathroom without a shower or bath.
+func ValidateBathrooms(bathrooms float64) error {
+	if bathrooms < 0 || bathrooms > 50 || math.Mod(bathrooms*2, 1) != 0 {
+		return ErrInvalidBathrooms
+	}
+	return nil
 }
 
 type GetPropertyOwner struct {
@@ -13,24 +102,318 @@ type GetPropertyOwner struct {
 	Name    string    `json:"name"`
 	Email   string    `json:"email"`
 }
-type LoginPropertyOwner struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
-}
+
+// CreateProperty amounts are in minor units of Currency, e.g. cents for USD.
 type CreateProperty struct {
-	PropertyName string `json:"property_name"`
-	Description  string `json:"description"`
-	Price        int64  `json:"price"`
+	PropertyName string `json:"property_name" binding:"required,max=100"`
+	Description  string `json:"description" binding:"max=500"`
+	Price        int64  `json:"price" binding:"gt=0" example:"12550"`
+	CleaningFee  int64  `json:"cleaning_fee" binding:"gte=0" example:"3000"`
+	Currency     string `json:"currency" binding:"omitempty,len=3,alpha" example:"USD"`
+	// WeeklyDiscount and MonthlyDiscount come off stays of 7 and 28 nights or
+	// more, in basis points.
+	WeeklyDiscount  int64 `json:"weekly_discount" binding:"gte=0,lte=9000" example:"1000"`
+	MonthlyDiscount int64 `json:"monthly_discount" binding:"gte=0,lte=9000" example:"2000"`
+	// CancellationPolicy is one of flexible (default), moderate or strict.
+	CancellationPolicy string `json:"cancellation_policy" binding:"omitempty,oneof=flexible moderate strict" example:"moderate"`
+	// MaxGuests defaults to 1.
+	MaxGuests int      `json:"max_guests" binding:"gte=0" example:"4"`
+	Address   Address  `json:"address"`
+	Latitude  *float64 `json:"latitude" binding:"omitempty,gte=-90,lte=90" example:"6.4281"`
+	Longitude *float64 `json:"longitude" binding:"omitempty,gte=-180,lte=180" example:"3.4219"`
+	// PropertyType is one of entire_home (default), private_room or shared_room.
+	PropertyType string `json:"property_type" binding:"omitempty,oneof=entire_home private_room shared_room" example:"entire_home"`
+	Bedrooms     int    `json:"bedrooms" binding:"gte=0,lte=50" example:"2"`
+	// Beds defaults to 1 and Bathrooms to 1, which may end in .5.
+	Beds      int      `json:"beds" binding:"gte=0,lte=100" example:"3"`
+	Bathrooms *float64 `json:"bathrooms" binding:"omitempty,gte=0,lte=50" example:"1.5"`
+	// Amenities are keys from the catalogue at GET /amenities.
+	Amenities  []string   `json:"amenities" binding:"max=50,dive,max=50" example:"wifi,kitchen"`
+	HouseRules HouseRules `json:"house_rules"`
+	// StayRules default to stays of at least one night, bookable from today
+	// with no other limits.
+	StayRules StayRules `json:"stay_rules"`
+	// CheckInTime and CheckOutTime default to 15:00 and 11:00.
+	CheckInTime  string `json:"check_in_time" binding:"omitempty,datetime=15:04" example:"15:00"`
+	CheckOutTime string `json:"check_out_time" binding:"omitempty,datetime=15:04" example:"11:00"`
+}
+
+// UpdateProperty holds a partial update: only fields present in the request
+// body are changed.
+type UpdateProperty struct {
+	PropertyName       *string `json:"property_name" binding:"omitempty,min=1,max=100"`
+	Description        *string `json:"description" binding:"omitempty,max=500"`
+	Price              *int64  `json:"price" binding:"omitempty,gt=0" example:"12550"`
+	CleaningFee        *int64  `json:"cleaning_fee" binding:"omitempty,gte=0" example:"3000"`
+	WeeklyDiscount     *int64  `json:"weekly_discount" binding:"omitempty,gte=0,lte=9000" example:"1000"`
+	MonthlyDiscount    *int64  `json:"monthly_discount" binding:"omitempty,gte=0,lte=9000" example:"2000"`
+	Currency           *string `json:"currency" binding:"omitempty,len=3,alpha" example:"USD"`
+	CancellationPolicy *string `json:"cancellation_policy" binding:"omitempty,oneof=flexible moderate strict" example:"moderate"`
+	MaxGuests          *int    `json:"max_guests" binding:"omitempty,min=1" example:"4"`
+	// Listing details.
+	PropertyType *string  `json:"property_type" binding:"omitempty,oneof=entire_home private_room shared_room" example:"private_room"`
+	Bedrooms     *int     `json:"bedrooms" binding:"omitempty,gte=0,lte=50" example:"2"`
+	Beds         *int     `json:"beds" binding:"omitempty,min=1,lte=100" example:"3"`
+	Bathrooms    *float64 `json:"bathrooms" binding:"omitempty,gte=0,lte=50" example:"1.5"`
+	// Amenities replaces the whole list when present; [] removes them all.
+	Amenities []string `json:"amenities" binding:"omitempty,max=50,dive,max=50" example:"wifi,pool"`
+	// HouseRules replaces all the rules when present.
+	HouseRules *HouseRules `json:"house_rules"`
+	// StayRules replaces all the rules when present. Bookings already made
+	// are kept.
+	StayRules    *StayRules `json:"stay_rules"`
+	CheckInTime  *string    `json:"check_in_time" binding:"omitempty,datetime=15:04" example:"14:00"`
+	CheckOutTime *string    `json:"check_out_time" binding:"omitempty,datetime=15:04" example:"10:00"`
+	// Address replaces the whole address when present.
+	Address   *Address `json:"address"`
+	Latitude  *float64 `json:"latitude" binding:"omitempty,gte=-90,lte=90" example:"6.4281"`
+	Longitude *float64 `json:"longitude" binding:"omitempty,gte=-180,lte=180" example:"3.4219"`
+}
+
+// Apply validates the fields present in the update and copies them onto the
+// property. The property is left untouched if any field is invalid.
+func (u UpdateProperty) Apply(property *Property) error {
+	updated := *property
+	if u.PropertyName != nil {
+		if len(*u.PropertyName) == 0 || len(*u.PropertyName) > 100 {
+			return ErrInvalidPropertyName
+		}
+		updated.Name = *u.PropertyName
+	}
+	if u.Description != nil {
+		if len(*u.Description) > 500 {
+			return ErrInvalidDescription
+		}
+		updated.Description = *u.Description
+	}
+	if u.Price != nil {
+		if *u.Price <= 0 {
+			return ErrInvalidPrice
+		}
+		updated.Price = *u.Price
+	}
+	if u.CleaningFee != nil {
+		if *u.CleaningFee < 0 {
+			return ErrInvalidCleaningFee
+		}
+		updated.CleaningFee = *u.CleaningFee
+	}
+	if u.WeeklyDiscount != nil {
+		if err := ValidateDiscount(*u.WeeklyDiscount); err != nil {
+			return err
+		}
+		updated.WeeklyDiscount = *u.WeeklyDiscount
+	}
+	if u.MonthlyDiscount != nil {
+		if err := ValidateDiscount(*u.MonthlyDiscount); err != nil {
+			return err
+		}
+		updated.MonthlyDiscount = *u.MonthlyDiscount
+	}
+	if u.Currency != nil {
+		currency, err := NormalizeCurrency(*u.Currency)
+		if err != nil {
+			return err
+		}
+		updated.Currency = currency
+	}
+	if u.CancellationPolicy != nil {
+		policy, err := NormalizeCancellationPolicy(*u.CancellationPolicy)
+		if err != nil {
+			return err
+		}
+		updated.CancellationPolicy = policy
+	}
+	if u.PropertyType != nil {
+		if !slices.Contains([]string{EntireHome, PrivateRoom, SharedRoom}, *u.PropertyType) {
+			return ErrInvalidPropertyType
+		}
+		updated.PropertyType = *u.PropertyType
+	}
+	if u.MaxGuests != nil {
+		if *u.MaxGuests < 1 {
+			return ErrInvalidMaxGuests
+		}
+		updated.MaxGuests = *u.MaxGuests
+	}
+	if u.Bedrooms != nil {
+		if *u.Bedrooms < 0 || *u.Bedrooms > 50 {
+			return ErrInvalidBedrooms
+		}
+		updated.Bedrooms = *u.Bedrooms
+	}
+	if u.Beds != nil {
+		if *u.Beds < 1 || *u.Beds > 100 {
+			return ErrInvalidBeds
+		}
+		updated.Beds = *u.Beds
+	}
+	if u.Bathrooms != nil {
+		if err := ValidateBathrooms(*u.Bathrooms); err != nil {
+			return err
+		}
+		updated.Bathrooms = *u.Bathrooms
+	}
+	if u.HouseRules != nil {
+		rules := *u.HouseRules
+		if err := rules.Normalize(); err != nil {
+			return err
+		}
+		updated.HouseRules = rules
+	}
+	if u.StayRules != nil {
+		rules := *u.StayRules
+		if err := rules.Normalize(); err != nil {
+			return err
+		}
+		updated.StayRules = rules
+	}
+	if u.CheckInTime != nil {
+		checkIn, err := NormalizeTimeOfDay(*u.CheckInTime)
+		if err != nil {
+			return err
+		}
+		updated.CheckInTime = checkIn
+	}
+	if u.CheckOutTime != nil {
+		checkOut, err := NormalizeTimeOfDay(*u.CheckOutTime)
+		if err != nil {
+			return err
+		}
+		updated.CheckOutTime = checkOut
+	}
+	if u.Address != nil {
+		if err := u.Address.Validate(); err != nil {
+			return err
+		}
+		updated.Address = *u.Address
+		updated.Location = u.Address.String()
+	}
+	if u.Latitude != nil || u.Longitude != nil {
+		if err := ValidateCoordinates(u.Latitude, u.Longitude); err != nil {
+			return err
+		}
+		updated.Latitude, updated.Longitude = u.Latitude, u.Longitude
+	}
+	*property = updated
+	return nil
 }
 
 type GetProperty struct {
-	PropertyID    uuid.UUID        `json:"property_id"`
-	PropertyName  string           `json:"property_name"`
-	Description   string           `json:"description"`
-	Price         int64            `json:"price"`
+	PropertyID         uuid.UUID `json:"property_id"`
+	PropertyName       string    `json:"property_name"`
+	Description        string    `json:"description"`
+	Price              int64     `json:"price"`
+	CleaningFee        int64     `json:"cleaning_fee"`
+	WeeklyDiscount     int64     `json:"weekly_discount"`
+	MonthlyDiscount    int64     `json:"monthly_discount"`
+	Currency           string    `json:"currency"`
+	CancellationPolicy string    `json:"cancellation_policy"`
+	PropertyType       string    `json:"property_type"`
+	MaxGuests          int       `json:"max_guests"`
+	Bedrooms           int       `json:"bedrooms"`
+	Beds               int       `json:"beds"`
+	Bathrooms          float64   `json:"bathrooms"`
+	// RatingAverage is the mean of guests' 1-5 ratings over RatingCount
+	// reviews, 0 until the first review.
+	RatingAverage float64  `json:"rating_average"`
+	RatingCount   int      `json:"rating_count"`
+	Address       Address  `json:"address"`
+	Latitude      *float64 `json:"latitude"`
+	Longitude     *float64 `json:"longitude"`
+	// Amenities are sorted by name.
+	Amenities    []GetAmenity `json:"amenities"`
+	HouseRules   HouseRules   `json:"house_rules"`
+	StayRules    StayRules    `json:"stay_rules"`
+	CheckInTime  string       `json:"check_in_time"`
+	CheckOutTime string       `json:"check_out_time"`
+	// UnlistedAt is set when an admin has hidden the property from guests.
+	UnlistedAt    *time.Time       `json:"unlisted_at,omitempty"`
 	PropertyOwner GetPropertyOwner `json:"property_owner"`
+	// Photos are in display order; the first is the cover.
+	Photos []GetPhoto `json:"photos"`
 }
 
 type GetAllProperties struct {
 	Properties []GetProperty `json:"properties"`
+	// NextCursor fetches the next page when passed back as cursor. It is
+	// empty on the last page.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// PropertySearch holds the public listing's filters, sort order and page.
+// Dates use the YYYY-MM-DD format; when both are set only properties free
+// for the whole stay are returned.
+type PropertySearch struct {
+	Query    string `form:"q" binding:"max=100"`
+	Location string `form:"location" binding:"max=100"`
+	MinPrice *int64 `form:"min_price" binding:"omitempty,gte=0"`
+	MaxPrice *int64 `form:"max_price" binding:"omitempty,gte=0"`
+	Guests   int    `form:"guests" binding:"gte=0"`
+	CheckIn  string `form:"check_in" binding:"omitempty,datetime=2006-01-02"`
+	CheckOut string `form:"check_out" binding:"omitempty,datetime=2006-01-02"`
+	Sort     string `form:"sort" binding:"omitempty,oneof=newest price_asc price_desc rating"`
+	Cursor   string `form:"cursor"`
+	Limit    int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	// Listing details: a property must match every filter given.
+	PropertyType string  `form:"property_type" binding:"omitempty,oneof=entire_home private_room shared_room"`
+	MinBedrooms  int     `form:"min_bedrooms" binding:"gte=0"`
+	MinBeds      int     `form:"min_beds" binding:"gte=0"`
+	MinBathrooms float64 `form:"min_bathrooms" binding:"gte=0"`
+	// Amenities is a comma-separated list of amenity keys.
+	Amenities      string `form:"amenities" binding:"max=500"`
+	PetsAllowed    bool   `form:"pets_allowed"`
+	SmokingAllowed bool   `form:"smoking_allowed"`
+	EventsAllowed  bool   `form:"events_allowed"`
+	// Map viewport; either all four are set or none.
+	MinLat *float64 `form:"min_lat"`
+	MinLng *float64 `form:"min_lng"`
+	MaxLat *float64 `form:"max_lat"`
+	MaxLng *float64 `form:"max_lng"`
+
+	From        time.Time    `form:"-"`
+	To          time.Time    `form:"-"`
+	Box         *BoundingBox `form:"-"`
+	AmenityKeys []string     `form:"-"`
+}
+
+// Normalize applies defaults and validates the search, parsing the stay dates
+// into From and To.
+func (s *PropertySearch) Normalize() error {
+	switch s.Sort {
+	case "":
+		s.Sort = SortNewest
+	case SortNewest, SortPriceAsc, SortPriceDesc, SortRating:
+	default:
+		return ErrInvalidSort
+	}
+	if s.Limit == 0 {
+		s.Limit = DefaultPageSize
+	}
+	if s.Limit < 1 || s.Limit > MaxPageSize {
+		return ErrInvalidLimit
+	}
+	if s.CheckIn != "" || s.CheckOut != "" {
+		from, to, err := ParseDates(s.CheckIn, s.CheckOut)
+		if err != nil {
+			return err
+		}
+		s.From, s.To = from, to
+	}
+	if s.MinLat != nil || s.MinLng != nil || s.MaxLat != nil || s.MaxLng != nil {
+		if s.MinLat == nil || s.MinLng == nil || s.MaxLat == nil || s.MaxLng == nil || *s.MinLat > *s.MaxLat {
+			return ErrInvalidBoundingBox
+		}
+		if ValidateCoordinates(s.MinLat, s.MinLng) != nil || ValidateCoordinates(s.MaxLat, s.MaxLng) != nil {
+			return ErrInvalidCoordinates
+		}
+		s.Box = &BoundingBox{MinLat: *s.MinLat, MinLng: *s.MinLng, MaxLat: *s.MaxLat, MaxLng: *s.MaxLng}
+	}
+	s.AmenityKeys = nil
+	for _, key := range strings.Split(s.Amenities, ",") {
+		if key = strings.TrimSpace(key); key != "" && !slices.Contains(s.AmenityKeys, key) {
+			s.AmenityKeys = append(s.AmenityKeys, key)
+		}
+	}
+	return nil
 }
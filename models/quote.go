@@ -0,0 +1,136 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Monetary amounts throughout the API are int64 values in the minor unit of
+// the property's currency, e.g. cents for USD, so 12550 with currency "USD"
+// is $125.50. Percentages are expressed in basis points (1/100 of a percent).
+const (
+	DefaultCurrency       = "USD"
+	ServiceFeeBasisPoints = 1200
+	TaxBasisPoints        = 750
+)
+
+// Quote line codes.
+const (
+	LineNightly    = "nightly"
+	LineDiscount   = "stay_discount"
+	LineCleaning   = "cleaning_fee"
+	LineServiceFee = "service_fee"
+	LineTax        = "tax"
+)
+
+var (
+	ErrInvalidGuests   = errors.New("guests must be at least 1")
+	ErrInvalidCurrency = errors.New("currency must be a three-letter ISO 4217 code")
+)
+
+// NormalizeCurrency upper-cases a currency code, defaulting to DefaultCurrency
+// when empty, and checks that it is three letters.
+func NormalizeCurrency(code string) (string, error) {
+	if code == "" {
+		return DefaultCurrency, nil
+	}
+	code = strings.ToUpper(code)
+	if len(code) != 3 {
+		return "", ErrInvalidCurrency
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return "", ErrInvalidCurrency
+		}
+	}
+	return code, nil
+}
+
+type CreateQuote struct {
+	CheckIn  string `json:"check_in" binding:"required,datetime=2006-01-02" example:"2025-01-10"`
+	CheckOut string `json:"check_out" binding:"required,datetime=2006-01-02" example:"2025-01-15"`
+	Guests   int    `json:"guests" binding:"min=1" example:"2"`
+}
+
+type QuoteLine struct {
+	Code        string `json:"code"`
+	Description string `json:"description"`
+	Amount      int64  `json:"amount"`
+}
+
+// QuoteNight is the price of the night starting on Date.
+type QuoteNight struct {
+	Date  string `json:"date"`
+	Price int64  `json:"price"`
+}
+
+type Quote struct {
+	PropertyID uuid.UUID `json:"property_id"`
+	CheckIn    string    `json:"check_in"`
+	CheckOut   string    `json:"check_out"`
+	Nights     int       `json:"nights"`
+	Guests     int       `json:"guests"`
+	Currency   string    `json:"currency"`
+	// NightlyPrice is the property's base price; NightlyPrices has each
+	// night's price after the owner's price rules.
+	NightlyPrice  int64        `json:"nightly_price"`
+	NightlyPrices []QuoteNight `json:"nightly_prices"`
+	Lines         []QuoteLine  `json:"lines"`
+	Total         int64        `json:"total"`
+}
+
+// NewQuote prices a stay at a property, whose PriceRules must be loaded. A
+// length-of-stay discount is a negative line. The service fee is charged on
+// the discounted nightly total plus cleaning fee, and tax on everything the
+// guest pays.
+func NewQuote(property *Property, checkIn, checkOut time.Time, guests int) Quote {
+	stay := property.PricingRules().Price(checkIn, checkOut)
+	subtotal := stay.Total() + property.CleaningFee
+	serviceFee := applyBasisPoints(subtotal, ServiceFeeBasisPoints)
+	tax := applyBasisPoints(subtotal+serviceFee, TaxBasisPoints)
+
+	lines := []QuoteLine{{Code: LineNightly, Description: "nightly prices", Amount: stay.Subtotal}}
+	if stay.Discount > 0 {
+		lines = append(lines, QuoteLine{Code: LineDiscount, Description: "length-of-stay discount", Amount: -stay.Discount})
+	}
+	lines = append(lines,
+		QuoteLine{Code: LineCleaning, Description: "cleaning fee", Amount: property.CleaningFee},
+		QuoteLine{Code: LineServiceFee, Description: "service fee", Amount: serviceFee},
+		QuoteLine{Code: LineTax, Description: "taxes", Amount: tax},
+	)
+	nights := make([]QuoteNight, 0, len(stay.Nights))
+	for _, night := range stay.Nights {
+		nights = append(nights, QuoteNight{Date: night.Date.Format(DateLayout), Price: night.Price})
+	}
+
+	return Quote{
+		PropertyID:    property.ID,
+		CheckIn:       checkIn.Format(DateLayout),
+		CheckOut:      checkOut.Format(DateLayout),
+		Nights:        len(stay.Nights),
+		Guests:        guests,
+		Currency:      property.Currency,
+		NightlyPrice:  property.Price,
+		NightlyPrices: nights,
+		Lines:         lines,
+		Total:         subtotal + serviceFee + tax,
+	}
+}
+
+// Amount returns the amount of the line with the given code, or 0.
+func (q Quote) Amount(code string) int64 {
+	for _, line := range q.Lines {
+		if line.Code == code {
+			return line.Amount
+		}
+	}
+	return 0
+}
+
+// applyBasisPoints returns amount * bps / 10000, rounded half up.
+func applyBasisPoints(amount int64, bps int64) int64 {
+	return (amount*bps + 5000) / 10000
+}
@@ -0,0 +1,105 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewQuote(t *testing.T) {
+	checkIn := time.Date(2030, 1, 7, 0, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		name     string
+		property Property
+		nights   int
+		// Wanted line amounts; the discount line is only present when it is
+		// not 0.
+		nightly, discount, cleaning, serviceFee, tax, total int64
+	}{
+		{
+			name:     "no cleaning fee",
+			property: Property{Price: 10000},
+			nights:   2,
+			nightly:  20000, serviceFee: 2400, tax: 1680, total: 24080,
+		},
+		{
+			name:     "cleaning fee is charged service fee and tax",
+			property: Property{Price: 10000, CleaningFee: 2500},
+			nights:   2,
+			nightly:  20000, cleaning: 2500, serviceFee: 2700, tax: 1890, total: 27090,
+		},
+		{
+			name:     "six nights get no weekly discount",
+			property: Property{Price: 10000, WeeklyDiscount: 1000},
+			nights:   6,
+			nightly:  60000, serviceFee: 7200, tax: 5040, total: 72240,
+		},
+		{
+			name:     "fees on the discounted nights",
+			property: Property{Price: 10000, CleaningFee: 2000, WeeklyDiscount: 1000},
+			nights:   7,
+			nightly:  70000, discount: -7000, cleaning: 2000, serviceFee: 7800, tax: 5460, total: 78260,
+		},
+		{
+			name:     "monthly discount replaces weekly",
+			property: Property{Price: 5000, WeeklyDiscount: 1000, MonthlyDiscount: 2000},
+			nights:   28,
+			nightly:  140000, discount: -28000, serviceFee: 13440, tax: 9408, total: 134848,
+		},
+		{
+			name:     "discount and fees round to the nearest unit",
+			property: Property{Price: 1001, WeeklyDiscount: 333},
+			nights:   7,
+			// 233.33 off, a fee of 812.88 and tax of 569.025.
+			nightly: 7007, discount: -233, serviceFee: 813, tax: 569, total: 8156,
+		},
+		{
+			name:     "service fee rounds down below a half",
+			property: Property{Price: 1004},
+			nights:   1,
+			nightly:  1004, serviceFee: 120, tax: 84, total: 1208,
+		},
+		{
+			name:     "service fee rounds up above a half",
+			property: Property{Price: 1005},
+			nights:   1,
+			nightly:  1005, serviceFee: 121, tax: 84, total: 1210,
+		},
+		{
+			name:     "tax rounds half up",
+			property: Property{Price: 18},
+			nights:   1,
+			// Tax is 7.5% of 20.
+			nightly: 18, serviceFee: 2, tax: 2, total: 22,
+		},
+	} {
+		tc.property.Currency = DefaultCurrency
+		quote := NewQuote(&tc.property, checkIn, checkIn.AddDate(0, 0, tc.nights), 2)
+
+		got := map[string]int64{}
+		var sum int64
+		for _, line := range quote.Lines {
+			got[line.Code] = line.Amount
+			sum += line.Amount
+		}
+		if _, ok := got[LineDiscount]; ok != (tc.discount != 0) {
+			t.Errorf("%s: lines = %+v, want a discount line %v", tc.name, quote.Lines, tc.discount != 0)
+		}
+		for code, want := range map[string]int64{
+			LineNightly:    tc.nightly,
+			LineDiscount:   tc.discount,
+			LineCleaning:   tc.cleaning,
+			LineServiceFee: tc.serviceFee,
+			LineTax:        tc.tax,
+		} {
+			if got[code] != want {
+				t.Errorf("%s: %s = %d, want %d", tc.name, code, got[code], want)
+			}
+		}
+		if quote.Total != tc.total || sum != tc.total {
+			t.Errorf("%s: total = %d, lines add up to %d, want %d", tc.name, quote.Total, sum, tc.total)
+		}
+		if quote.Nights != tc.nights || len(quote.NightlyPrices) != tc.nights || quote.Currency != DefaultCurrency || quote.Guests != 2 {
+			t.Errorf("%s: quote = %+v", tc.name, quote)
+		}
+	}
+}
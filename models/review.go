@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CreateReview struct {
+	Rating  int    `json:"rating" binding:"required,min=1,max=5" example:"5"`
+	Comment string `json:"comment" binding:"required,max=2000" example:"Spotless and quiet, would stay again"`
+}
+
+type ReplyToReview struct {
+	Reply string `json:"reply" binding:"required,max=2000" example:"Thank you, come back soon!"`
+}
+
+// ReviewSearch pages through reviews, newest first.
+type ReviewSearch struct {
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+type GetReview struct {
+	ReviewID   uuid.UUID  `json:"review_id"`
+	BookingID  uuid.UUID  `json:"booking_id"`
+	PropertyID uuid.UUID  `json:"property_id"`
+	AuthorName string     `json:"author_name"`
+	AuthorRole string     `json:"author_role"`
+	Rating     int        `json:"rating"`
+	Comment    string     `json:"comment"`
+	Reply      string     `json:"reply,omitempty"`
+	RepliedAt  *time.Time `json:"replied_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func NewGetReview(review *Review) GetReview {
+	return GetReview{
+		ReviewID:   review.ID,
+		BookingID:  review.BookingID,
+		PropertyID: review.PropertyID,
+		AuthorName: review.Author.Name,
+		AuthorRole: review.AuthorRole,
+		Rating:     review.Rating,
+		Comment:    review.Comment,
+		Reply:      review.Reply,
+		RepliedAt:  review.RepliedAt,
+		CreatedAt:  review.CreatedAt,
+	}
+}
+
+type GetReviews struct {
+	Reviews []GetReview `json:"reviews"`
+	// NextCursor fetches the next page when passed back as cursor. It is
+	// empty on the last page.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// AddRating folds a new rating into a running average over count ratings and
+// returns the new average and count.
+func AddRating(average float64, count int, rating int) (float64, int) {
+	count++
+	return average + (float64(rating)-average)/float64(count), count
+}
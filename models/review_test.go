@@ -0,0 +1,22 @@
+package models
+
+import "testing"
+
+func TestAddRating(t *testing.T) {
+	average, count := 0.0, 0
+	for i, want := range []struct {
+		rating  int
+		average float64
+	}{
+		{5, 5},
+		{4, 4.5},
+		{3, 4},
+		{1, 3.25},
+		{5, 3.6},
+	} {
+		average, count = AddRating(average, count, want.rating)
+		if count != i+1 || average < want.average-1e-9 || average > want.average+1e-9 {
+			t.Fatalf("after rating %d: %v over %d, want %v over %d", want.rating, average, count, want.average, i+1)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package models
+
+import (
+	"airbnb/pricing"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Reasons a stay breaks a property's StayRules, reported as the code of a
+// FieldError and on unbookable nights of the availability calendar.
+const (
+	StayPast          = "past"
+	StayMinNights     = "min_nights"
+	StayMaxNights     = "max_nights"
+	StayAdvanceNotice = "advance_notice"
+	StayBookingWindow = "booking_window"
+	StayCheckInDay    = "check_in_day"
+)
+
+var ErrInvalidStayRules = errors.New("max_nights must be 0 or at least min_nights, and booking_window_days 0 or more than advance_notice_days")
+
+// CheckInDays are the weekdays guests may arrive on, sent as three-letter
+// names such as ["fri","sat"]. None means any day.
+type CheckInDays pricing.Weekdays
+
+func (d CheckInDays) Allows(day time.Weekday) bool {
+	return d == 0 || pricing.Weekdays(d).Has(day)
+}
+
+func (d CheckInDays) names() []string {
+	names := []string{}
+	for _, day := range pricing.Weekdays(d).Days() {
+		names = append(names, weekdayNames[day])
+	}
+	return names
+}
+
+func (d CheckInDays) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.names())
+}
+
+func (d *CheckInDays) UnmarshalJSON(data []byte) error {
+	var names []string
+	if err := json.Unmarshal(data, &names); err != nil {
+		return err
+	}
+	var days pricing.Weekdays
+	for _, name := range names {
+		day := slices.Index(weekdayNames, name)
+		if day < 0 {
+			return ErrInvalidWeekday
+		}
+		days |= pricing.NewWeekdays(time.Weekday(day))
+	}
+	*d = CheckInDays(days)
+	return nil
+}
+
+// StayRules limit which stays guests can book. Days count from today in UTC:
+// with AdvanceNoticeDays 1 the earliest check-in is tomorrow, and with
+// BookingWindowDays 90 every night must fall within the next 90 days. A zero
+// MaxNights, BookingWindowDays or CheckInDays sets no limit.
+type StayRules struct {
+	MinNights         int         `json:"min_nights" gorm:"not null;default:1" binding:"gte=0,lte=365" example:"2"`
+	MaxNights         int         `json:"max_nights" gorm:"not null;default:0" binding:"gte=0,lte=365" example:"28"`
+	AdvanceNoticeDays int         `json:"advance_notice_days" gorm:"not null;default:0" binding:"gte=0,lte=365" example:"1"`
+	BookingWindowDays int         `json:"booking_window_days" gorm:"not null;default:0" binding:"gte=0,lte=730" example:"180"`
+	CheckInDays       CheckInDays `json:"check_in_days" gorm:"not null;default:0" swaggertype:"array,string" example:"fri,sat"`
+}
+
+// Normalize validates the rules and defaults MinNights to 1.
+func (r *StayRules) Normalize() error {
+	if r.MinNights == 0 {
+		r.MinNights = 1
+	}
+	if r.MinNights < 1 || r.MinNights > 365 || r.MaxNights < 0 || r.MaxNights > 365 ||
+		r.AdvanceNoticeDays < 0 || r.AdvanceNoticeDays > 365 || r.BookingWindowDays < 0 || r.BookingWindowDays > 730 {
+		return ErrInvalidStayRules
+	}
+	if r.MaxNights != 0 && r.MaxNights < r.MinNights {
+		return ErrInvalidStayRules
+	}
+	if r.BookingWindowDays != 0 && r.BookingWindowDays <= r.AdvanceNoticeDays {
+		return ErrInvalidStayRules
+	}
+	return nil
+}
+
+// minNights is MinNights for rows that predate the rules.
+func (r StayRules) minNights() int {
+	return max(r.MinNights, 1)
+}
+
+// earliestCheckIn is the first night a stay booked at now may start on.
+func (r StayRules) earliestCheckIn(now time.Time) time.Time {
+	return Today(now).AddDate(0, 0, r.AdvanceNoticeDays)
+}
+
+// windowEnd is the first night a stay booked at now may not include, or the
+// zero time when the booking window is open-ended.
+func (r StayRules) windowEnd(now time.Time) time.Time {
+	if r.BookingWindowDays == 0 {
+		return time.Time{}
+	}
+	return Today(now).AddDate(0, 0, r.BookingWindowDays)
+}
+
+// Check lists every rule the stay from checkIn to checkOut breaks if booked
+// at now, or nothing if it can be booked.
+func (r StayRules) Check(checkIn, checkOut, now time.Time) []FieldError {
+	var violations []FieldError
+	nights := int(checkOut.Sub(checkIn).Hours() / 24)
+	if nights < r.minNights() {
+		violations = append(violations, FieldError{Field: "check_out", Code: StayMinNights, Message: fmt.Sprintf("stays must be at least %d nights", r.minNights())})
+	}
+	if r.MaxNights != 0 && nights > r.MaxNights {
+		violations = append(violations, FieldError{Field: "check_out", Code: StayMaxNights, Message: fmt.Sprintf("stays can be at most %d nights", r.MaxNights)})
+	}
+	if checkIn.Before(r.earliestCheckIn(now)) {
+		violations = append(violations, FieldError{Field: "check_in", Code: StayAdvanceNotice, Message: fmt.Sprintf("check_in must be at least %d days from today", r.AdvanceNoticeDays)})
+	}
+	if end := r.windowEnd(now); !end.IsZero() && checkOut.After(end) {
+		violations = append(violations, FieldError{Field: "check_out", Code: StayBookingWindow, Message: fmt.Sprintf("stays must end within %d days from today", r.BookingWindowDays)})
+	}
+	if !r.CheckInDays.Allows(checkIn.Weekday()) {
+		violations = append(violations, FieldError{Field: "check_in", Code: StayCheckInDay, Message: "check_in must be on " + strings.Join(r.CheckInDays.names(), ", ")})
+	}
+	return violations
+}
+
+// CalendarPadding is how many nights either side of a calendar's range
+// Unbookable needs to see to judge the nights inside it.
+func (r StayRules) CalendarPadding() int {
+	// A stay covering a night can start up to MaxNights-1 nights earlier, or
+	// with no maximum, up to a week before the last night it could start on.
+	return max(r.minNights()+7, r.MaxNights)
+}
+
+// Unbookable works out which of a run of nights no bookable stay can cover.
+// taken marks the nights from first onwards that are booked or blocked, and
+// the result gives the reason for each free night that cannot be booked at
+// now, or "" for one that can. Free runs are cut off where taken ends, so it
+// should reach CalendarPadding nights beyond the nights of interest.
+func (r StayRules) Unbookable(first time.Time, taken []bool, now time.Time) []string {
+	reasons := make([]string, len(taken))
+	today, earliest, windowEnd := Today(now), r.earliestCheckIn(now), r.windowEnd(now)
+	night := func(i int) time.Time { return first.AddDate(0, 0, i) }
+
+	open := make([]bool, len(taken))
+	for i := range taken {
+		switch date := night(i); {
+		case taken[i]:
+		case date.Before(today):
+			reasons[i] = StayPast
+		case date.Before(earliest):
+			reasons[i] = StayAdvanceNotice
+		case !windowEnd.IsZero() && !date.Before(windowEnd):
+			reasons[i] = StayBookingWindow
+		default:
+			open[i] = true
+		}
+	}
+
+	for start := 0; start < len(open); start++ {
+		if !open[start] {
+			continue
+		}
+		end := start
+		for end < len(open) && open[end] {
+			end++
+		}
+		// Night n of the run [start, end) can be booked if a stay can check in
+		// on an allowed day d no later than n, last at least minNights
+		// without leaving the run, and reach n within MaxNights.
+		for n := start; n < end; n++ {
+			if end-start < r.minNights() {
+				reasons[n] = StayMinNights
+				continue
+			}
+			lowest := start
+			if r.MaxNights != 0 {
+				lowest = max(start, n-r.MaxNights+1)
+			}
+			bookable := false
+			for d := min(n, end-r.minNights()); d >= lowest && d > min(n, end-r.minNights())-7; d-- {
+				if r.CheckInDays.Allows(night(d).Weekday()) {
+					bookable = true
+					break
+				}
+			}
+			if !bookable {
+				reasons[n] = StayCheckInDay
+			}
+		}
+		start = end
+	}
+	return reasons
+}
+
+// StayError is a request for a stay the property's StayRules do not allow.
+type StayError struct {
+	Violations []FieldError
+}
+
+func (e *StayError) Error() string {
+	return "the stay breaks the property's booking rules"
+}
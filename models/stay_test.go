@@ -0,0 +1,145 @@
+package models
+
+import (
+	"airbnb/pricing"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+// stayNow is a Monday afternoon; stay dates in these tests count from it.
+var stayNow = time.Date(2030, 1, 7, 15, 0, 0, 0, time.UTC)
+
+func stayDay(offset int) time.Time {
+	return Today(stayNow).AddDate(0, 0, offset)
+}
+
+func TestStayRulesNormalize(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		rules StayRules
+		valid bool
+	}{
+		{"zero", StayRules{}, true},
+		{"all set", StayRules{MinNights: 2, MaxNights: 28, AdvanceNoticeDays: 1, BookingWindowDays: 180}, true},
+		{"max equals min", StayRules{MinNights: 3, MaxNights: 3}, true},
+		{"max below min", StayRules{MinNights: 3, MaxNights: 2}, false},
+		{"window within notice", StayRules{AdvanceNoticeDays: 30, BookingWindowDays: 30}, false},
+		{"negative notice", StayRules{AdvanceNoticeDays: -1}, false},
+		{"min above a year", StayRules{MinNights: 366}, false},
+		{"window above two years", StayRules{BookingWindowDays: 731}, false},
+	} {
+		rules := tc.rules
+		err := rules.Normalize()
+		if (err == nil) != tc.valid {
+			t.Errorf("%s: err = %v, want valid %v", tc.name, err, tc.valid)
+		}
+		if err == nil && rules.MinNights < 1 {
+			t.Errorf("%s: MinNights = %d after Normalize", tc.name, rules.MinNights)
+		}
+	}
+}
+
+func TestCheckInDaysJSON(t *testing.T) {
+	var days CheckInDays
+	if err := json.Unmarshal([]byte(`["sat","fri","sat"]`), &days); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if !days.Allows(time.Friday) || !days.Allows(time.Saturday) || days.Allows(time.Sunday) {
+		t.Fatalf("days = %v", pricing.Weekdays(days).Days())
+	}
+	data, err := json.Marshal(days)
+	if err != nil || string(data) != `["fri","sat"]` {
+		t.Fatalf("marshal = %s, %v", data, err)
+	}
+	if data, err := json.Marshal(CheckInDays(0)); err != nil || string(data) != `[]` {
+		t.Fatalf("marshal none = %s, %v", data, err)
+	}
+	if !CheckInDays(0).Allows(time.Wednesday) {
+		t.Fatal("no check-in days should allow any day")
+	}
+	if err := json.Unmarshal([]byte(`["saturday"]`), &days); !errors.Is(err, ErrInvalidWeekday) {
+		t.Fatalf("unmarshal full name: err = %v", err)
+	}
+}
+
+func TestStayRulesCheck(t *testing.T) {
+	// stayDay(5) is a Saturday and stayDay(61) the first day outside a 60-day
+	// window.
+	saturdays := StayRules{MinNights: 3, MaxNights: 7, AdvanceNoticeDays: 2, BookingWindowDays: 60, CheckInDays: CheckInDays(pricing.NewWeekdays(time.Saturday))}
+	open := StayRules{AdvanceNoticeDays: 2, BookingWindowDays: 60}
+	for _, tc := range []struct {
+		name            string
+		rules           StayRules
+		checkIn, nights int
+		want            []string
+	}{
+		{"allowed", saturdays, 5, 3, nil},
+		{"max nights", saturdays, 5, 7, nil},
+		{"too long", saturdays, 5, 8, []string{StayMaxNights}},
+		{"too short", saturdays, 5, 2, []string{StayMinNights}},
+		{"wrong day", saturdays, 2, 3, []string{StayCheckInDay}},
+		{"everything", saturdays, 1, 1, []string{StayMinNights, StayAdvanceNotice, StayCheckInDay}},
+		{"outside the window", saturdays, 61, 3, []string{StayBookingWindow}},
+		{"first day after notice", open, 2, 1, nil},
+		{"inside notice", open, 1, 1, []string{StayAdvanceNotice}},
+		{"ends on the window's end", open, 57, 3, nil},
+		{"ends past the window", open, 58, 3, []string{StayBookingWindow}},
+		{"no rules", StayRules{}, 0, 1, nil},
+	} {
+		var codes []string
+		for _, violation := range tc.rules.Check(stayDay(tc.checkIn), stayDay(tc.checkIn+tc.nights), stayNow) {
+			codes = append(codes, violation.Code)
+		}
+		if !slices.Equal(codes, tc.want) {
+			t.Errorf("%s: violations = %v, want %v", tc.name, codes, tc.want)
+		}
+	}
+}
+
+func TestStayRulesUnbookable(t *testing.T) {
+	saturdays := StayRules{MinNights: 3, MaxNights: 7, AdvanceNoticeDays: 2, BookingWindowDays: 60, CheckInDays: CheckInDays(pricing.NewWeekdays(time.Saturday))}
+	if got := saturdays.CalendarPadding(); got != 10 {
+		t.Fatalf("CalendarPadding = %d, want 10", got)
+	}
+
+	// The calendar starts yesterday. Nights 5-7 are booked and 10-21 blocked
+	// or booked, leaving two free nights between, too few for a stay, and
+	// nights after them that only the next Saturday reaches.
+	first := stayDay(-1)
+	taken := make([]bool, 75)
+	for _, night := range []int{5, 6, 7, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21} {
+		taken[night+1] = true
+	}
+	reasons := saturdays.Unbookable(first, taken, stayNow)
+	for night, want := range map[int]string{
+		-1: StayPast,
+		0:  StayAdvanceNotice,
+		1:  StayAdvanceNotice,
+		2:  StayCheckInDay,
+		4:  StayCheckInDay,
+		5:  "",
+		8:  StayMinNights,
+		9:  StayMinNights,
+		10: "",
+		22: StayCheckInDay,
+		25: StayCheckInDay,
+		26: "",
+		59: "",
+		60: StayBookingWindow,
+	} {
+		if got := reasons[night+1]; got != want {
+			t.Errorf("night %d: reason %q, want %q", night, got, want)
+		}
+	}
+
+	// Every night of a free run is reachable from a check-in day within
+	// MaxNights.
+	weekend := StayRules{MinNights: 1, MaxNights: 2, CheckInDays: CheckInDays(pricing.NewWeekdays(time.Saturday))}
+	reasons = weekend.Unbookable(stayDay(5), make([]bool, 7), stayNow)
+	if want := []string{"", "", StayCheckInDay, StayCheckInDay, StayCheckInDay, StayCheckInDay, StayCheckInDay}; !slices.Equal(reasons, want) {
+		t.Fatalf("reasons = %q, want %q", reasons, want)
+	}
+}
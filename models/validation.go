@@ -0,0 +1,17 @@
+package models
+
+// FieldError describes one invalid request field. Field is the JSON (or query
+// parameter) path, e.g. "address.city" or "roles[1]", Code the rule that
+// failed, e.g. "required" or "max", and Message a sentence for the user.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// MinPasswordLength and MaxPasswordLength bound new passwords. bcrypt ignores
+// anything past 72 bytes.
+const (
+	MinPasswordLength = 8
+	MaxPasswordLength = 72
+)
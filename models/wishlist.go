@@ -0,0 +1,88 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxWishlistProperties caps how many properties one wishlist can hold.
+const MaxWishlistProperties = 100
+
+type CreateWishlist struct {
+	Name string `json:"name" binding:"required,max=100" example:"Summer by the lake"`
+}
+
+// WishlistDates asks for the price and availability of every saved property
+// for a stay. Both dates must be given; guests defaults to 1.
+type WishlistDates struct {
+	CheckIn  string `form:"check_in" binding:"omitempty,datetime=2006-01-02"`
+	CheckOut string `form:"check_out" binding:"omitempty,datetime=2006-01-02"`
+	Guests   int    `form:"guests" binding:"omitempty,min=1"`
+
+	From time.Time `form:"-"`
+	To   time.Time `form:"-"`
+}
+
+// Normalize defaults the guest count and parses the stay dates into From and
+// To, rejecting check-in dates before today.
+func (d *WishlistDates) Normalize(now time.Time) error {
+	if d.Guests == 0 {
+		d.Guests = 1
+	}
+	if d.CheckIn == "" && d.CheckOut == "" {
+		return nil
+	}
+	from, to, err := ParseDateRange(d.CheckIn, d.CheckOut, now)
+	if err != nil {
+		return err
+	}
+	d.From, d.To = from, to
+	return nil
+}
+
+// HasDates reports whether a stay was asked for.
+func (d *WishlistDates) HasDates() bool {
+	return !d.From.IsZero()
+}
+
+// Fits reports whether property sleeps the guests and its StayRules allow the
+// stay when booked at now. Whether the nights are free is checked separately.
+func (d *WishlistDates) Fits(property *Property, now time.Time) bool {
+	return d.Guests <= property.MaxGuests && len(property.StayRules.Check(d.From, d.To, now)) == 0
+}
+
+type WishlistSummary struct {
+	WishlistID    uuid.UUID `json:"wishlist_id"`
+	Name          string    `json:"name"`
+	Shared        bool      `json:"shared"`
+	PropertyCount int       `json:"property_count"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type WishlistProperty struct {
+	Property GetProperty `json:"property"`
+	SavedAt  time.Time   `json:"saved_at"`
+	// Available and Quote are only set when the request gives dates.
+	// Available is false when the stay overlaps a booking or blocked dates,
+	// breaks the property's stay rules, or there are more guests than the
+	// property fits.
+	Available *bool  `json:"available,omitempty"`
+	Quote     *Quote `json:"quote,omitempty"`
+}
+
+// GetWishlist lists a wishlist's properties, most recently saved first.
+// Properties an admin has unlisted are left out until they are relisted.
+type GetWishlist struct {
+	WishlistID uuid.UUID          `json:"wishlist_id"`
+	Name       string             `json:"name"`
+	Shared     bool               `json:"shared"`
+	Properties []WishlistProperty `json:"properties"`
+}
+
+// WishlistShare is the read-only link to a shared wishlist. It is shown once;
+// sharing again replaces it.
+type WishlistShare struct {
+	WishlistID uuid.UUID `json:"wishlist_id"`
+	ShareURL   string    `json:"share_url"`
+}
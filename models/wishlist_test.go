@@ -0,0 +1,59 @@
+package models
+
+import (
+	"airbnb/pricing"
+	"testing"
+	"time"
+)
+
+func TestWishlistDates(t *testing.T) {
+	now := time.Date(2030, 1, 7, 15, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		name    string
+		dates   WishlistDates
+		wantErr bool
+	}{
+		{name: "no dates", dates: WishlistDates{}},
+		{name: "stay", dates: WishlistDates{CheckIn: "2030-01-07", CheckOut: "2030-01-09"}},
+		{name: "check-in only", dates: WishlistDates{CheckIn: "2030-01-07"}, wantErr: true},
+		{name: "inverted", dates: WishlistDates{CheckIn: "2030-01-09", CheckOut: "2030-01-07"}, wantErr: true},
+		{name: "past", dates: WishlistDates{CheckIn: "2030-01-06", CheckOut: "2030-01-08"}, wantErr: true},
+	} {
+		dates := tc.dates
+		err := dates.Normalize(now)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%s: err = %v, want error %v", tc.name, err, tc.wantErr)
+			continue
+		}
+		if err == nil && (dates.Guests != 1 || dates.HasDates() != (tc.dates.CheckIn != "")) {
+			t.Errorf("%s: normalized to %+v", tc.name, dates)
+		}
+	}
+}
+
+func TestWishlistDatesFits(t *testing.T) {
+	now := time.Date(2030, 1, 7, 15, 0, 0, 0, time.UTC)
+	monday := Today(now)
+	property := func(maxGuests int, rules StayRules) *Property {
+		return &Property{MaxGuests: maxGuests, StayRules: rules}
+	}
+	for _, tc := range []struct {
+		name     string
+		nights   int
+		guests   int
+		property *Property
+		want     bool
+	}{
+		{"fits", 2, 2, property(2, StayRules{MinNights: 1}), true},
+		{"too many guests", 2, 3, property(2, StayRules{MinNights: 1}), false},
+		{"too short", 2, 1, property(2, StayRules{MinNights: 3}), false},
+		{"too soon", 2, 1, property(2, StayRules{MinNights: 1, AdvanceNoticeDays: 1}), false},
+		{"wrong check-in day", 2, 1, property(2, StayRules{MinNights: 1, CheckInDays: CheckInDays(pricing.NewWeekdays(time.Saturday))}), false},
+		{"rules predating min nights", 1, 1, property(1, StayRules{}), true},
+	} {
+		dates := WishlistDates{Guests: tc.guests, From: monday, To: monday.AddDate(0, 0, tc.nights)}
+		if got := dates.Fits(tc.property, now); got != tc.want {
+			t.Errorf("%s: Fits = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
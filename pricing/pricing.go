@@ -0,0 +1,154 @@
+// Package pricing works out what each night of a stay costs from a
+// property's base price and the rules its owner has set.
+//
+// A night's price is resolved in this order:
+//
+//  1. If date-range overrides cover the night, the one spanning the fewest
+//     nights sets its price, so a holiday inside a season wins. Among
+//     overrides of the same length, the one listed last wins. Weekday
+//     adjustments do not apply to an overridden night.
+//  2. Otherwise the base price is adjusted by every weekday adjustment for
+//     the night's weekday, their basis points added together. An adjusted
+//     price never goes below zero.
+//
+// A length-of-stay discount then comes off the nights' total: the one with
+// the longest MinNights the stay reaches, not the largest. Amounts are in the
+// minor unit of the property's currency and rounded half up.
+package pricing
+
+import "time"
+
+// Weekdays is a set of days of the week.
+type Weekdays uint8
+
+func NewWeekdays(days ...time.Weekday) Weekdays {
+	var w Weekdays
+	for _, day := range days {
+		w |= 1 << day
+	}
+	return w
+}
+
+func (w Weekdays) Has(day time.Weekday) bool {
+	return w&(1<<day) != 0
+}
+
+// Days lists the days in w from Sunday to Saturday.
+func (w Weekdays) Days() []time.Weekday {
+	var days []time.Weekday
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if w.Has(day) {
+			days = append(days, day)
+		}
+	}
+	return days
+}
+
+// Override sets the price of the nights from Start up to, but not including,
+// End.
+type Override struct {
+	Start time.Time
+	End   time.Time
+	Price int64
+}
+
+func (o Override) covers(night time.Time) bool {
+	return !night.Before(o.Start) && night.Before(o.End)
+}
+
+func (o Override) nights() int {
+	return nightsBetween(o.Start, o.End)
+}
+
+// WeekdayAdjustment changes the base price on Weekdays by BasisPoints, e.g.
+// 2000 for 20% more or -1000 for 10% less.
+type WeekdayAdjustment struct {
+	Weekdays    Weekdays
+	BasisPoints int64
+}
+
+// StayDiscount takes BasisPoints off stays of at least MinNights nights.
+type StayDiscount struct {
+	MinNights   int
+	BasisPoints int64
+}
+
+// Rules are everything that sets a property's nightly prices.
+type Rules struct {
+	BasePrice   int64
+	Overrides   []Override
+	Adjustments []WeekdayAdjustment
+	Discounts   []StayDiscount
+}
+
+// Night is the price of the night starting on Date.
+type Night struct {
+	Date  time.Time
+	Price int64
+}
+
+// Stay is the price of a run of nights.
+type Stay struct {
+	Nights []Night
+	// Subtotal is the sum of the nights' prices.
+	Subtotal int64
+	// Discount is taken off Subtotal, at DiscountBasisPoints.
+	DiscountBasisPoints int64
+	Discount            int64
+}
+
+// Total is what the nights cost after the discount.
+func (s Stay) Total() int64 {
+	return s.Subtotal - s.Discount
+}
+
+// NightlyPrice returns the price of the night starting on night, which should
+// be a date at midnight UTC like the bounds of the overrides.
+func (r Rules) NightlyPrice(night time.Time) int64 {
+	var override *Override
+	for i, o := range r.Overrides {
+		if o.covers(night) && (override == nil || o.nights() <= override.nights()) {
+			override = &r.Overrides[i]
+		}
+	}
+	if override != nil {
+		return override.Price
+	}
+
+	var bps int64
+	for _, adjustment := range r.Adjustments {
+		if adjustment.Weekdays.Has(night.Weekday()) {
+			bps += adjustment.BasisPoints
+		}
+	}
+	return applyBasisPoints(r.BasePrice, max(10000+bps, 0))
+}
+
+// Price prices the nights from checkIn up to, but not including, checkOut.
+func (r Rules) Price(checkIn, checkOut time.Time) Stay {
+	var stay Stay
+	for night := checkIn; night.Before(checkOut); night = night.AddDate(0, 0, 1) {
+		price := r.NightlyPrice(night)
+		stay.Nights = append(stay.Nights, Night{Date: night, Price: price})
+		stay.Subtotal += price
+	}
+
+	minNights := 0
+	for _, discount := range r.Discounts {
+		if discount.BasisPoints > 0 && len(stay.Nights) >= discount.MinNights && discount.MinNights >= minNights {
+			minNights = discount.MinNights
+			stay.DiscountBasisPoints = discount.BasisPoints
+		}
+	}
+	stay.Discount = applyBasisPoints(stay.Subtotal, stay.DiscountBasisPoints)
+	return stay
+}
+
+func nightsBetween(start, end time.Time) int {
+	return int(end.Sub(start).Hours() / 24)
+}
+
+// applyBasisPoints returns amount * bps / 10000, rounded half up.
+func applyBasisPoints(amount, bps int64) int64 {
+	return (amount*bps + 5000) / 10000
+}
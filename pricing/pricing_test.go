@@ -0,0 +1,181 @@
+package pricing
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func date(s string) time.Time {
+	d, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// 2030-01-04 is a Friday.
+var weekend = NewWeekdays(time.Friday, time.Saturday)
+
+func TestNightlyPrice(t *testing.T) {
+	cases := []struct {
+		name  string
+		rules Rules
+		night string
+		want  int64
+	}{
+		{name: "base price", rules: Rules{BasePrice: 10000}, night: "2030-01-02", want: 10000},
+		{
+			name:  "weekend premium",
+			rules: Rules{BasePrice: 10000, Adjustments: []WeekdayAdjustment{{Weekdays: weekend, BasisPoints: 2500}}},
+			night: "2030-01-04", want: 12500,
+		},
+		{
+			name:  "weekend premium on a weekday",
+			rules: Rules{BasePrice: 10000, Adjustments: []WeekdayAdjustment{{Weekdays: weekend, BasisPoints: 2500}}},
+			night: "2030-01-03", want: 10000,
+		},
+		{
+			name: "adjustments for the same day add up",
+			rules: Rules{BasePrice: 10000, Adjustments: []WeekdayAdjustment{
+				{Weekdays: weekend, BasisPoints: 2500},
+				{Weekdays: NewWeekdays(time.Friday), BasisPoints: -1000},
+			}},
+			night: "2030-01-04", want: 11500,
+		},
+		{
+			name: "adjustments never make a night negative",
+			rules: Rules{BasePrice: 10000, Adjustments: []WeekdayAdjustment{
+				{Weekdays: weekend, BasisPoints: -9000},
+				{Weekdays: weekend, BasisPoints: -9000},
+			}},
+			night: "2030-01-05", want: 0,
+		},
+		{
+			name:  "adjustments round half up",
+			rules: Rules{BasePrice: 999, Adjustments: []WeekdayAdjustment{{Weekdays: weekend, BasisPoints: 500}}},
+			night: "2030-01-04", want: 1049,
+		},
+		{
+			name: "override replaces the weekday adjustment",
+			rules: Rules{
+				BasePrice:   10000,
+				Overrides:   []Override{{Start: date("2030-01-01"), End: date("2030-01-08"), Price: 8000}},
+				Adjustments: []WeekdayAdjustment{{Weekdays: weekend, BasisPoints: 2500}},
+			},
+			night: "2030-01-04", want: 8000,
+		},
+		{
+			name:  "override end is exclusive",
+			rules: Rules{BasePrice: 10000, Overrides: []Override{{Start: date("2030-01-01"), End: date("2030-01-04"), Price: 8000}}},
+			night: "2030-01-04", want: 10000,
+		},
+		{
+			name: "shorter override wins whatever the order",
+			rules: Rules{BasePrice: 10000, Overrides: []Override{
+				{Start: date("2029-12-24"), End: date("2029-12-27"), Price: 30000},
+				{Start: date("2029-12-01"), End: date("2030-01-10"), Price: 15000},
+			}},
+			night: "2029-12-25", want: 30000,
+		},
+		{
+			name: "longer override outside the shorter one",
+			rules: Rules{BasePrice: 10000, Overrides: []Override{
+				{Start: date("2029-12-01"), End: date("2030-01-10"), Price: 15000},
+				{Start: date("2029-12-24"), End: date("2029-12-27"), Price: 30000},
+			}},
+			night: "2029-12-27", want: 15000,
+		},
+		{
+			name: "last override of the same length wins",
+			rules: Rules{BasePrice: 10000, Overrides: []Override{
+				{Start: date("2030-01-01"), End: date("2030-01-04"), Price: 11000},
+				{Start: date("2030-01-02"), End: date("2030-01-05"), Price: 12000},
+			}},
+			night: "2030-01-03", want: 12000,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.rules.NightlyPrice(date(tc.night)); got != tc.want {
+				t.Fatalf("price = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPrice(t *testing.T) {
+	rules := Rules{
+		BasePrice:   10000,
+		Overrides:   []Override{{Start: date("2030-01-01"), End: date("2030-01-02"), Price: 20000}},
+		Adjustments: []WeekdayAdjustment{{Weekdays: weekend, BasisPoints: 2000}},
+		Discounts:   []StayDiscount{{MinNights: 28, BasisPoints: 1500}, {MinNights: 7, BasisPoints: 1000}},
+	}
+
+	stay := rules.Price(date("2029-12-31"), date("2030-01-05"))
+	var prices []int64
+	for _, night := range stay.Nights {
+		prices = append(prices, night.Price)
+	}
+	if want := []int64{10000, 20000, 10000, 10000, 12000}; !slices.Equal(prices, want) {
+		t.Fatalf("nights = %v, want %v", prices, want)
+	}
+	if stay.Subtotal != 62000 || stay.Discount != 0 || stay.Total() != 62000 {
+		t.Fatalf("short stay = %+v", stay)
+	}
+
+	cases := []struct {
+		name      string
+		rules     Rules
+		nights    int
+		bps       int64
+		wantTotal int64
+	}{
+		{name: "six nights", rules: rules, nights: 6, bps: 0},
+		{name: "weekly discount", rules: rules, nights: 7, bps: 1000},
+		{name: "weekly discount up to 27 nights", rules: rules, nights: 27, bps: 1000},
+		{name: "monthly discount replaces weekly", rules: rules, nights: 28, bps: 1500},
+		{
+			name:   "monthly discount applies even when smaller",
+			rules:  Rules{BasePrice: 10000, Discounts: []StayDiscount{{MinNights: 7, BasisPoints: 2000}, {MinNights: 28, BasisPoints: 500}}},
+			nights: 28, bps: 500, wantTotal: 266000,
+		},
+		{
+			name:   "a zero discount does not hide a shorter one",
+			rules:  Rules{BasePrice: 10000, Discounts: []StayDiscount{{MinNights: 7, BasisPoints: 1000}, {MinNights: 28, BasisPoints: 0}}},
+			nights: 28, bps: 1000, wantTotal: 252000,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			checkIn := date("2030-03-01")
+			stay := tc.rules.Price(checkIn, checkIn.AddDate(0, 0, tc.nights))
+			if len(stay.Nights) != tc.nights || stay.DiscountBasisPoints != tc.bps {
+				t.Fatalf("nights = %d, discount = %d bps", len(stay.Nights), stay.DiscountBasisPoints)
+			}
+			if stay.Discount != (stay.Subtotal*tc.bps+5000)/10000 {
+				t.Fatalf("discount = %d on %d", stay.Discount, stay.Subtotal)
+			}
+			if tc.wantTotal != 0 && stay.Total() != tc.wantTotal {
+				t.Fatalf("total = %d, want %d", stay.Total(), tc.wantTotal)
+			}
+		})
+	}
+}
+
+func TestPriceWithoutNights(t *testing.T) {
+	stay := Rules{BasePrice: 10000}.Price(date("2030-01-01"), date("2030-01-01"))
+	if len(stay.Nights) != 0 || stay.Total() != 0 {
+		t.Fatalf("stay = %+v", stay)
+	}
+}
+
+func TestWeekdays(t *testing.T) {
+	days := NewWeekdays(time.Saturday, time.Monday, time.Monday).Days()
+	if !slices.Equal(days, []time.Weekday{time.Monday, time.Saturday}) {
+		t.Fatalf("days = %v", days)
+	}
+	if weekend.Has(time.Sunday) || !weekend.Has(time.Friday) {
+		t.Fatalf("weekend = %v", weekend.Days())
+	}
+}
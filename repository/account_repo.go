@@ -0,0 +1,344 @@
+package repository
+
+import (
+	"airbnb/models"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrAccountNotFound     = NewError(ErrNotFound, "account_not_found", "account not found")
+	ErrEmailTaken          = NewError(ErrConflict, "email_taken", "an account with this email already exists")
+	ErrAccountTokenInvalid = NewError(ErrInvalid, "invalid_account_token", "token is invalid, expired or already used")
+	ErrAccountSuspended    = NewError(ErrForbidden, "account_suspended", "account is suspended")
+	ErrAlreadySuspended    = NewError(ErrConflict, "account_already_suspended", "account is already suspended")
+	ErrNotSuspended        = NewError(ErrConflict, "account_not_suspended", "account is not suspended")
+	ErrSuspendSelf         = NewError(ErrForbidden, "cannot_suspend_self", "admins cannot suspend their own account")
+	ErrAccountHasBookings  = NewError(ErrConflict, "account_has_bookings", "account has upcoming bookings as a guest or on its properties; cancel them first")
+)
+
+const accountCursorKind = "accounts"
+
+type AccountRepo struct {
+	DB *gorm.DB
+}
+
+func NewAccountRepo(db *gorm.DB) *AccountRepo {
+	return &AccountRepo{DB: db}
+}
+
+// CreateAccount creates the account together with its roles, or returns
+// ErrEmailTaken if the email is already registered.
+func (r *AccountRepo) CreateAccount(ctx context.Context, account *models.Account) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.Account{}).Unscoped().Where("email = ?", account.Email).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrEmailTaken
+		}
+		// The count cannot see an account created concurrently; the unique
+		// index can.
+		if err := tx.Create(account).Error; err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return ErrEmailTaken
+			}
+			return err
+		}
+		return nil
+	})
+}
+
+func (r *AccountRepo) GetAccountByID(ctx context.Context, id uuid.UUID) (*models.Account, error) {
+	var account models.Account
+	if err := r.DB.WithContext(ctx).Preload("Roles").Where("id = ?", id).First(&account).Error; err != nil {
+		return nil, translate(err, ErrAccountNotFound)
+	}
+	return &account, nil
+}
+
+func (r *AccountRepo) GetAccountByEmail(ctx context.Context, email string) (*models.Account, error) {
+	var account models.Account
+	if err := r.DB.WithContext(ctx).Preload("Roles").Where("email = ?", email).First(&account).Error; err != nil {
+		return nil, translate(err, ErrAccountNotFound)
+	}
+	return &account, nil
+}
+
+// SearchAccounts returns one page of accounts matching search, newest first,
+// and the cursor for the next page ("" on the last page).
+func (r *AccountRepo) SearchAccounts(ctx context.Context, search models.AccountSearch) ([]models.Account, string, error) {
+	query := r.DB.WithContext(ctx).Preload("Roles")
+	if search.Query != "" {
+		pattern := "%" + escapeLike(search.Query) + "%"
+		query = query.Where("("+containsText(r.DB, "accounts.name")+" OR "+containsText(r.DB, "accounts.email")+")", pattern, pattern)
+	}
+	if search.Role != "" {
+		holders := r.DB.Table("account_roles").Select("account_id").Where("role_name = ?", search.Role)
+		query = query.Where("accounts.id IN (?)", holders)
+	}
+	switch search.Status {
+	case models.AccountActive:
+		query = query.Where("accounts.suspended_at IS NULL")
+	case models.AccountSuspended:
+		query = query.Where("accounts.suspended_at IS NOT NULL")
+	}
+	query, err := newestFirst(query, "accounts", accountCursorKind, search.Cursor, search.Limit)
+	if err != nil {
+		return nil, "", err
+	}
+	var accounts []models.Account
+	if err := query.Find(&accounts).Error; err != nil {
+		return nil, "", err
+	}
+	return nextPage(accounts, accountCursorKind, search.Limit, func(a models.Account) (time.Time, uuid.UUID) {
+		return a.CreatedAt, a.ID
+	})
+}
+
+// SuspendAccount suspends the account on behalf of adminID and signs it out
+// everywhere, returning the access tokens that were denylisted. Admins cannot
+// suspend themselves, so there is always one left to reinstate them.
+func (r *AccountRepo) SuspendAccount(ctx context.Context, id, adminID uuid.UUID, reason string, now time.Time) ([]models.RevokedToken, error) {
+	if id == adminID {
+		return nil, ErrSuspendSelf
+	}
+	var revoked []models.RevokedToken
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		account, err := lockAccount(tx, id)
+		if err != nil {
+			return err
+		}
+		if account.IsSuspended() {
+			return ErrAlreadySuspended
+		}
+		err = tx.Model(account).Updates(map[string]any{
+			"suspended_at":      now,
+			"suspension_reason": reason,
+		}).Error
+		if err != nil {
+			return err
+		}
+		if revoked, err = revokeTokens(tx, "account_id = ?", id, now); err != nil {
+			return err
+		}
+		return recordAudit(tx, adminID, models.AuditSuspendAccount, models.AuditTargetAccount, id, reason)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return revoked, nil
+}
+
+// ReinstateAccount lifts the account's suspension on behalf of adminID. Its
+// old sessions stay revoked; the account holder signs in again.
+func (r *AccountRepo) ReinstateAccount(ctx context.Context, id, adminID uuid.UUID, reason string) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		account, err := lockAccount(tx, id)
+		if err != nil {
+			return err
+		}
+		if !account.IsSuspended() {
+			return ErrNotSuspended
+		}
+		err = tx.Model(account).Updates(map[string]any{
+			"suspended_at":      nil,
+			"suspension_reason": "",
+		}).Error
+		if err != nil {
+			return err
+		}
+		return recordAudit(tx, adminID, models.AuditReinstateAccount, models.AuditTargetAccount, id, reason)
+	})
+}
+
+func lockAccount(tx *gorm.DB, id uuid.UUID) (*models.Account, error) {
+	var account models.Account
+	err := tx.Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate}).
+		First(&account, "id = ?", id).Error
+	if err != nil {
+		return nil, translate(err, ErrAccountNotFound)
+	}
+	return &account, nil
+}
+
+// AddRole grants role to the account. Granting a role it already holds is a
+// no-op.
+func (r *AccountRepo) AddRole(ctx context.Context, account *models.Account, role string) error {
+	if account.HasRole(role) {
+		return nil
+	}
+	return r.DB.WithContext(ctx).Model(account).Association("Roles").Append(&models.Role{Name: role})
+}
+
+// UpdateAccount saves the account's own columns, or returns ErrEmailTaken if
+// its email now belongs to another account.
+func (r *AccountRepo) UpdateAccount(ctx context.Context, account *models.Account) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		err := tx.Model(&models.Account{}).Unscoped().
+			Where("email = ? AND id <> ?", account.Email, account.ID).
+			Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrEmailTaken
+		}
+		if err := tx.Omit(clause.Associations).Save(account).Error; err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return ErrEmailTaken
+			}
+			return err
+		}
+		return nil
+	})
+}
+
+// DeleteAccount deletes the account, its wishlists and the properties it owns
+// and signs it out everywhere, returning the access tokens that were
+// denylisted. Accounts with upcoming bookings, as a guest or on their
+// properties, cannot be deleted until those are cancelled. The account's name
+// and email are erased so the email can be used to sign up again; its
+// bookings are kept for the other party's records.
+func (r *AccountRepo) DeleteAccount(ctx context.Context, id uuid.UUID, now time.Time) ([]models.RevokedToken, error) {
+	var revoked []models.RevokedToken
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		account, err := lockAccount(tx, id)
+		if err != nil {
+			return err
+		}
+		owned := tx.Session(&gorm.Session{NewDB: true}).
+			Model(&models.Property{}).
+			Select("id").
+			Where("owner_id = ?", id)
+		var count int64
+		err = tx.Model(&models.Booking{}).
+			Where("status IN ?", activeStatuses).
+			Where("user_id = ? OR property_id IN (?)", id, owned).
+			Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrAccountHasBookings
+		}
+
+		if err := removeFromWishlists(tx, owned); err != nil {
+			return err
+		}
+		if err := tx.Where("owner_id = ?", id).Delete(&models.Property{}).Error; err != nil {
+			return err
+		}
+		if err := deleteWishlists(tx, "guest_id = ?", id); err != nil {
+			return err
+		}
+		if revoked, err = revokeTokens(tx, "account_id = ?", id, now); err != nil {
+			return err
+		}
+		err = tx.Model(account).Updates(map[string]any{
+			"name":     "deleted account",
+			"email":    "deleted-" + id.String() + "@invalid",
+			"password": "",
+		}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Delete(account).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return revoked, nil
+}
+
+// CreateAccountToken stores token and invalidates the account's earlier unused
+// tokens for the same purpose, so only the latest emailed link works.
+func (r *AccountRepo) CreateAccountToken(ctx context.Context, token *models.AccountToken) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.AccountToken{}).
+			Where("account_id = ? AND purpose = ? AND used_at IS NULL", token.AccountID, token.Purpose).
+			Update("used_at", time.Now()).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(token).Error
+	})
+}
+
+// VerifyEmail redeems the email verification token with the given hash and
+// marks its account verified.
+func (r *AccountRepo) VerifyEmail(ctx context.Context, hash string, now time.Time) (*models.Account, error) {
+	var account models.Account
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		token, err := redeemAccountToken(tx, models.VerifyEmailPurpose, hash, now)
+		if err != nil {
+			return err
+		}
+		if err := tx.First(&account, "id = ?", token.AccountID).Error; err != nil {
+			return err
+		}
+		if account.EmailVerifiedAt != nil {
+			return nil
+		}
+		account.EmailVerifiedAt = &now
+		return tx.Model(&account).Update("email_verified_at", now).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// ResetPassword redeems the password reset token with the given hash and sets
+// the account's password hash. Receiving the token proves the account holder
+// controls the email address, so the account is verified as well.
+func (r *AccountRepo) ResetPassword(ctx context.Context, hash, passwordHash string, now time.Time) (*models.Account, error) {
+	var account models.Account
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		token, err := redeemAccountToken(tx, models.ResetPasswordPurpose, hash, now)
+		if err != nil {
+			return err
+		}
+		if err := tx.First(&account, "id = ?", token.AccountID).Error; err != nil {
+			return err
+		}
+		updates := map[string]any{"password": passwordHash}
+		if account.EmailVerifiedAt == nil {
+			updates["email_verified_at"] = now
+		}
+		return tx.Model(&account).Updates(updates).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// redeemAccountToken locks the unused, unexpired token with the given purpose
+// and hash and marks it used.
+func redeemAccountToken(tx *gorm.DB, purpose, hash string, now time.Time) (*models.AccountToken, error) {
+	var token models.AccountToken
+	err := tx.Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate}).
+		Where("token_hash = ? AND purpose = ?", hash, purpose).
+		First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAccountTokenInvalid
+		}
+		return nil, err
+	}
+	if token.UsedAt != nil || !token.ExpiresAt.After(now) {
+		return nil, ErrAccountTokenInvalid
+	}
+	if err := tx.Model(&token).Update("used_at", now).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"airbnb/models"
+	"context"
+	"errors"
+	"slices"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAmenityNotFound = NewError(ErrNotFound, "amenity_not_found", "amenity not found")
+	ErrAmenityExists   = NewError(ErrConflict, "amenity_exists", "an amenity with this key already exists")
+	ErrUnknownAmenity  = NewError(ErrInvalid, "unknown_amenity", "amenities must be keys from the catalogue at GET /amenities")
+)
+
+type AmenityRepo struct {
+	DB *gorm.DB
+}
+
+func NewAmenityRepo(db *gorm.DB) *AmenityRepo {
+	return &AmenityRepo{DB: db}
+}
+
+// ListAmenities returns the whole catalogue sorted by name.
+func (r *AmenityRepo) ListAmenities(ctx context.Context) ([]models.Amenity, error) {
+	amenities := []models.Amenity{}
+	err := r.DB.WithContext(ctx).Order("name, key").Find(&amenities).Error
+	return amenities, err
+}
+
+func (r *AmenityRepo) CreateAmenity(ctx context.Context, amenity *models.Amenity) error {
+	err := r.DB.WithContext(ctx).Create(amenity).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return ErrAmenityExists
+	}
+	return err
+}
+
+// RenameAmenity changes the name shown for an amenity. Its key never changes,
+// so saved searches and properties keep pointing at it.
+func (r *AmenityRepo) RenameAmenity(ctx context.Context, key, name string) (*models.Amenity, error) {
+	var amenity models.Amenity
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&amenity, "key = ?", key).Error; err != nil {
+			return translate(err, ErrAmenityNotFound)
+		}
+		amenity.Name = name
+		return tx.Model(&amenity).Update("name", name).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &amenity, nil
+}
+
+// DeleteAmenity removes an amenity from the catalogue and from every property
+// that had it.
+func (r *AmenityRepo) DeleteAmenity(ctx context.Context, key string) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM property_amenities WHERE amenity_key = ?", key).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&models.Amenity{}, "key = ?", key)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrAmenityNotFound
+		}
+		return nil
+	})
+}
+
+// findAmenities returns the catalogue entries for keys, ignoring repeats, or
+// ErrUnknownAmenity if any key is not in the catalogue.
+func findAmenities(tx *gorm.DB, keys []string) ([]models.Amenity, error) {
+	amenities := []models.Amenity{}
+	if len(keys) == 0 {
+		return amenities, nil
+	}
+	if err := tx.Where("key IN ?", keys).Find(&amenities).Error; err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		if !slices.ContainsFunc(amenities, func(a models.Amenity) bool { return a.Key == key }) {
+			return nil, ErrUnknownAmenity
+		}
+	}
+	return amenities, nil
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"airbnb/models"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const auditCursorKind = "audit"
+
+type AuditRepo struct {
+	DB *gorm.DB
+}
+
+func NewAuditRepo(db *gorm.DB) *AuditRepo {
+	return &AuditRepo{DB: db}
+}
+
+// ListAuditEntries returns one page of the audit log matching search, newest
+// first, and the cursor for the next page ("" on the last page).
+func (r *AuditRepo) ListAuditEntries(ctx context.Context, search models.AuditSearch) ([]models.AuditEntry, string, error) {
+	query := r.DB.WithContext(ctx).Model(&models.AuditEntry{})
+	if search.ActorID != "" {
+		query = query.Where("actor_id = ?", search.ActorID)
+	}
+	if search.TargetID != "" {
+		query = query.Where("target_id = ?", search.TargetID)
+	}
+	if search.Action != "" {
+		query = query.Where("action = ?", search.Action)
+	}
+	query, err := newestFirst(query, "audit_entries", auditCursorKind, search.Cursor, search.Limit)
+	if err != nil {
+		return nil, "", err
+	}
+	var entries []models.AuditEntry
+	if err := query.Find(&entries).Error; err != nil {
+		return nil, "", err
+	}
+	return nextPage(entries, auditCursorKind, search.Limit, func(e models.AuditEntry) (time.Time, uuid.UUID) {
+		return e.CreatedAt, e.ID
+	})
+}
+
+// recordAudit writes an audit entry for an action adminID took on a target.
+// It runs in the action's transaction so one is never kept without the other.
+func recordAudit(tx *gorm.DB, adminID uuid.UUID, action, targetType string, targetID uuid.UUID, reason string) error {
+	return tx.Create(&models.AuditEntry{
+		ActorID:    adminID,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Reason:     reason,
+	}).Error
+}
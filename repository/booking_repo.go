@@ -3,11 +3,26 @@ package repository
 import (
 	"airbnb/models"
 	"context"
+	"errors"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+var (
+	ErrPropertyNotFound    = NewError(ErrNotFound, "property_not_found", "property not found")
+	ErrBookingOverlap      = NewError(ErrConflict, "booking_overlap", "property is already booked for the selected dates")
+	ErrDatesBlocked        = NewError(ErrConflict, "dates_blocked", "property is unavailable for the selected dates")
+	ErrBlockedDateNotFound = NewError(ErrNotFound, "blocked_dates_not_found", "blocked date range not found")
+	ErrBookingNotFound     = NewError(ErrNotFound, "booking_not_found", "booking not found")
+	ErrTooManyGuests       = NewError(ErrInvalid, "too_many_guests", "guests exceed the property's max_guests")
+)
+
+// activeStatuses are the booking statuses that hold a property's nights.
+var activeStatuses = []string{models.Pending, models.Confirmed, models.CheckedIn}
+
 type BookingRepo struct {
 	DB *gorm.DB
 }
@@ -18,8 +33,95 @@ func NewBookingRepo(db *gorm.DB) *BookingRepo {
 	}
 }
 
-func (r *BookingRepo) CreateBooking(ctx context.Context, booking *models.Booking) error {
-	return r.DB.WithContext(ctx).Create(booking).Error
+// CreateBooking inserts a booking after checking that the property sleeps its
+// guests, that its StayRules allow the stay when booked at now, and that no
+// pending or confirmed booking on the same property overlaps its dates. The
+// property row is locked for the duration of the transaction so concurrent
+// requests for the same property are serialised and cannot double-book a
+// night. The booking is priced from the locked property row, freezing the
+// total at creation.
+func (r *BookingRepo) CreateBooking(ctx context.Context, booking *models.Booking, now time.Time) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		property, err := lockProperty(tx, booking.PropertyID)
+		if err != nil {
+			return err
+		}
+		if booking.Guests > property.MaxGuests {
+			return ErrTooManyGuests
+		}
+		if violations := property.StayRules.Check(booking.CheckIn, booking.CheckOut, now); len(violations) > 0 {
+			return &models.StayError{Violations: violations}
+		}
+
+		var overlapping int64
+		err = tx.Model(&models.Booking{}).
+			Where("property_id = ?", booking.PropertyID).
+			Where("status IN ?", activeStatuses).
+			Where("check_in < ? AND check_out > ?", booking.CheckOut, booking.CheckIn).
+			Count(&overlapping).Error
+		if err != nil {
+			return err
+		}
+		if overlapping > 0 {
+			return ErrBookingOverlap
+		}
+
+		var blocked int64
+		err = tx.Model(&models.BlockedDate{}).
+			Where("property_id = ?", booking.PropertyID).
+			Where("start_date < ? AND end_date > ?", booking.CheckOut, booking.CheckIn).
+			Count(&blocked).Error
+		if err != nil {
+			return err
+		}
+		if blocked > 0 {
+			return ErrDatesBlocked
+		}
+
+		if err := loadPriceRules(tx, property); err != nil {
+			return err
+		}
+		booking.ApplyQuote(models.NewQuote(property, booking.CheckIn, booking.CheckOut, booking.Guests))
+		if err := tx.Create(booking).Error; err != nil {
+			return err
+		}
+		return recordTransition(tx, booking.ID, "", booking.Status, models.NewActor(booking.UserID, models.GuestRole))
+	})
+}
+
+func recordTransition(tx *gorm.DB, bookingID uuid.UUID, from, to string, actor models.Actor) error {
+	return tx.Create(&models.BookingTransition{
+		BookingID:  bookingID,
+		FromStatus: from,
+		ToStatus:   to,
+		ActorID:    actor.ID,
+		ActorRole:  actor.Role,
+	}).Error
+}
+
+// lockProperty takes a row lock on the property so that booking and blocking
+// writes for the same property are serialised within the transaction. Unlisted
+// properties cannot be booked and are reported as not found.
+func lockProperty(tx *gorm.DB, propertyID uuid.UUID) (*models.Property, error) {
+	return lockPropertyRow(listed(tx).Where("id = ?", propertyID))
+}
+
+// lockOwnedProperty is lockProperty restricted to properties owned by ownerID.
+func lockOwnedProperty(tx *gorm.DB, propertyID, ownerID uuid.UUID) (*models.Property, error) {
+	return lockPropertyRow(tx.Where("id = ? AND owner_id = ?", propertyID, ownerID))
+}
+
+func lockPropertyRow(query *gorm.DB) (*models.Property, error) {
+	var property models.Property
+	err := query.Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate}).
+		First(&property).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPropertyNotFound
+		}
+		return nil, err
+	}
+	return &property, nil
 }
 
 func (r *BookingRepo) GetBookingByID(ctx context.Context, id uuid.UUID) (*models.Booking, error) {
@@ -46,21 +148,162 @@ func (r *BookingRepo) GetBookingsByPropertyID(ctx context.Context, propertyID uu
 	return bookings, nil
 }
 
-func (r *BookingRepo) CancelBooking(ctx context.Context, id uuid.UUID) error {
-	return r.DB.WithContext(ctx).Delete(&models.Booking{}, "id = ?", id).Error
+// TransitionBooking moves a booking to a new status if the booking lifecycle
+// allows it, recording the change and its actor. Users and property owners can
+// only transition their own bookings; anyone else gets ErrBookingNotFound.
+// It returns a *models.TransitionError for illegal transitions.
+func (r *BookingRepo) TransitionBooking(ctx context.Context, id uuid.UUID, to string, actor models.Actor) (*models.Booking, error) {
+	return r.transition(ctx, id, to, actor, nil)
+}
+
+// CancelBooking cancels a booking on behalf of its guest or host, applying the
+// property's cancellation policy to work out the refund. The refund, reason and
+// actor are stored on the booking.
+func (r *BookingRepo) CancelBooking(ctx context.Context, id uuid.UUID, actor models.Actor, reason string, now time.Time) (*models.Booking, error) {
+	return r.transition(ctx, id, models.Cancelled, actor, cancelBooking(actor, reason, now))
+}
+
+// ForceCancelBooking cancels any guest's booking on behalf of adminID with a
+// full refund and records it in the audit log. Bookings that have checked in
+// or ended cannot be cancelled.
+func (r *BookingRepo) ForceCancelBooking(ctx context.Context, id, adminID uuid.UUID, reason string, now time.Time) (*models.Booking, error) {
+	actor := models.NewActor(adminID, models.AdminRole)
+	cancel := cancelBooking(actor, reason, now)
+	return r.transition(ctx, id, models.Cancelled, actor, func(tx *gorm.DB, booking *models.Booking) error {
+		if err := cancel(tx, booking); err != nil {
+			return err
+		}
+		return recordAudit(tx, adminID, models.AuditCancelBooking, models.AuditTargetBooking, id, reason)
+	})
+}
+
+// cancelBooking stores the refund, reason and actor of a cancellation on the
+// booking being transitioned.
+func cancelBooking(actor models.Actor, reason string, now time.Time) func(tx *gorm.DB, booking *models.Booking) error {
+	return func(tx *gorm.DB, booking *models.Booking) error {
+		var property models.Property
+		if err := tx.Select("cancellation_policy").First(&property, "id = ?", booking.PropertyID).Error; err != nil {
+			return err
+		}
+		booking.RefundAmount = models.RefundAmount(property.CancellationPolicy, booking, actor.Role, now)
+		booking.CancellationReason = reason
+		booking.CancelledByID = actor.ID
+		booking.CancelledByRole = actor.Role
+		booking.CancelledAt = &now
+		return tx.Model(booking).Select("refund_amount", "cancellation_reason", "cancelled_by_id", "cancelled_by_role", "cancelled_at").
+			Updates(booking).Error
+	}
+}
+
+// transition locks the booking, checks the move is allowed and, before the
+// status changes, runs apply so callers can update other columns in the same
+// transaction.
+func (r *BookingRepo) transition(ctx context.Context, id uuid.UUID, to string, actor models.Actor, apply func(tx *gorm.DB, booking *models.Booking) error) (*models.Booking, error) {
+	var booking models.Booking
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := scopeBookingsToActor(tx, actor).
+			Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate}).
+			First(&booking, "id = ?", id).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrBookingNotFound
+			}
+			return err
+		}
+
+		from := booking.Status
+		if !models.CanTransition(from, to) {
+			return &models.TransitionError{From: from, To: to}
+		}
+		if apply != nil {
+			if err := apply(tx, &booking); err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&booking).Update("status", to).Error; err != nil {
+			return err
+		}
+		return recordTransition(tx, booking.ID, from, to, actor)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &booking, nil
+}
+
+// BookingRole returns the part accountID plays in a booking: guest if they
+// made it, host if it is for one of their properties. It returns
+// ErrBookingNotFound if the booking does not exist or is none of theirs.
+func (r *BookingRepo) BookingRole(ctx context.Context, bookingID, accountID uuid.UUID) (string, error) {
+	var booking models.Booking
+	err := r.DB.WithContext(ctx).Preload("Property").First(&booking, "id = ?", bookingID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", ErrBookingNotFound
+		}
+		return "", err
+	}
+	switch accountID {
+	case booking.UserID:
+		return models.GuestRole, nil
+	case booking.Property.OwnerID:
+		return models.HostRole, nil
+	}
+	return "", ErrBookingNotFound
 }
 
-func (r *BookingRepo) ConfirmBooking(ctx context.Context, id uuid.UUID) error {
-	return r.DB.WithContext(ctx).Model(&models.Booking{}).
-		Where("id = ?", id).
-		Update("status", models.Confirmed).Error
+// scopeBookingsToActor restricts a bookings query to the bookings the actor may
+// act on: a guest's own bookings, or bookings on a host's properties.
+func scopeBookingsToActor(db *gorm.DB, actor models.Actor) *gorm.DB {
+	if actor.ID == nil {
+		return db
+	}
+	switch actor.Role {
+	case models.GuestRole:
+		return db.Where("user_id = ?", *actor.ID)
+	case models.HostRole:
+		owned := db.Session(&gorm.Session{NewDB: true}).
+			Model(&models.Property{}).
+			Select("id").
+			Where("owner_id = ?", *actor.ID)
+		return db.Where("property_id IN (?)", owned)
+	}
+	return db
+}
+
+// ExpirePendingBookings moves pending bookings whose check-in date is before
+// the given day to expired, returning how many were expired. Bookings checking
+// in on day itself stay pending so a host can still confirm a same-day stay.
+func (r *BookingRepo) ExpirePendingBookings(ctx context.Context, day time.Time) (int, error) {
+	var ids []uuid.UUID
+	err := r.DB.WithContext(ctx).Model(&models.Booking{}).
+		Where("status = ? AND check_in < ?", models.Pending, day).
+		Pluck("id", &ids).Error
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, id := range ids {
+		_, err := r.TransitionBooking(ctx, id, models.Expired, models.Actor{Role: models.SystemRole})
+		var transitionErr *models.TransitionError
+		if errors.As(err, &transitionErr) {
+			// confirmed or cancelled since we listed it
+			continue
+		}
+		if err != nil {
+			return expired, err
+		}
+		expired++
+	}
+	return expired, nil
 }
 
 func (r *BookingRepo) GetUserBookings(ctx context.Context, userID uuid.UUID) ([]models.UserGetBooking, error) {
 	var bookings []models.UserGetBooking
 	err := r.DB.WithContext(ctx).
 		Table("bookings").
-		Select("bookings.id as booking_id, bookings.property_id, properties.name as property_name, bookings.status").
+		Select("bookings.id as booking_id, bookings.property_id, properties.name as property_name, bookings.check_in, bookings.check_out, bookings.guests, bookings.currency, bookings.total_price, bookings.status").
 		Joins("JOIN properties ON bookings.property_id = properties.id").
 		Where("bookings.user_id = ?", userID).
 		Scan(&bookings).Error
@@ -71,36 +314,120 @@ func (r *BookingRepo) GetPropertyBookings(ctx context.Context, ownerID uuid.UUID
 	var bookings []models.PropertyBooking
 	err := r.DB.WithContext(ctx).
 		Table("bookings").
-		Select("bookings.id as booking_id, bookings.property_id, bookings.user_id, bookings.status").
+		Select("bookings.id as booking_id, bookings.property_id, bookings.user_id, bookings.check_in, bookings.check_out, bookings.guests, bookings.currency, bookings.total_price, bookings.status").
 		Joins("JOIN properties ON bookings.property_id = properties.id").
 		Where("properties.owner_id = ?", ownerID).
 		Scan(&bookings).Error
 	return bookings, err
 }
 
-func (r *BookingRepo) GetUserBookingByID(ctx context.Context, bookingID uuid.UUID) (*models.UserGetBooking, error) {
+// GetUserBookingByID returns one of userID's bookings, or ErrBookingNotFound if
+// the booking does not exist or belongs to another user.
+func (r *BookingRepo) GetUserBookingByID(ctx context.Context, userID, bookingID uuid.UUID) (*models.UserGetBooking, error) {
 	var booking models.UserGetBooking
 	err := r.DB.WithContext(ctx).
 		Table("bookings").
-		Select("bookings.id as booking_id, properties.id as property_id, properties.name as property_name, bookings.status").
+		Select("bookings.id as booking_id, properties.id as property_id, properties.name as property_name, bookings.check_in, bookings.check_out, bookings.guests, bookings.currency, bookings.total_price, bookings.status").
 		Joins("JOIN properties ON bookings.property_id = properties.id").
-		Where("bookings.id = ?", bookingID).
+		Where("bookings.id = ? AND bookings.user_id = ?", bookingID, userID).
 		Scan(&booking).Error
 	if err != nil {
 		return nil, err
 	}
+	if booking.BookingID == uuid.Nil {
+		return nil, ErrBookingNotFound
+	}
 	return &booking, nil
 }
 
-func (r *BookingRepo) GetPropertyBookingByID(ctx context.Context, ID uuid.UUID) (*models.PropertyBooking, error) {
+// GetPropertyBookingByID returns a booking on one of ownerID's properties, or
+// ErrBookingNotFound if the booking does not exist or is for another owner.
+func (r *BookingRepo) GetPropertyBookingByID(ctx context.Context, ownerID, ID uuid.UUID) (*models.PropertyBooking, error) {
 	var booking models.PropertyBooking
 	err := r.DB.WithContext(ctx).
 		Table("bookings").
-		Select("bookings.id as booking_id, bookings.property_id, bookings.user_id, bookings.status").
-		Where("bookings.id = ?", ID).
+		Select("bookings.id as booking_id, bookings.property_id, bookings.user_id, bookings.check_in, bookings.check_out, bookings.guests, bookings.currency, bookings.total_price, bookings.status").
+		Joins("JOIN properties ON bookings.property_id = properties.id").
+		Where("bookings.id = ? AND properties.owner_id = ?", ID, ownerID).
 		Scan(&booking).Error
 	if err != nil {
 		return nil, err
 	}
+	if booking.BookingID == uuid.Nil {
+		return nil, ErrBookingNotFound
+	}
 	return &booking, nil
 }
+
+// GetStayRules returns the stay rules of a property guests can see.
+func (r *BookingRepo) GetStayRules(ctx context.Context, propertyID uuid.UUID) (models.StayRules, error) {
+	var property models.Property
+	err := listed(r.DB.WithContext(ctx)).Select("id", "min_nights", "max_nights", "advance_notice_days", "booking_window_days", "check_in_days").
+		First(&property, "id = ?", propertyID).Error
+	return property.StayRules, translate(err, ErrPropertyNotFound)
+}
+
+// GetAvailability returns the active bookings and owner-blocked ranges that
+// overlap the nights from (inclusive) to (exclusive) for a property. It does
+// not check the property exists; GetStayRules does.
+func (r *BookingRepo) GetAvailability(ctx context.Context, propertyID uuid.UUID, from, to time.Time) ([]models.Booking, []models.BlockedDate, error) {
+	var bookings []models.Booking
+	err := r.DB.WithContext(ctx).
+		Where("property_id = ?", propertyID).
+		Where("status IN ?", activeStatuses).
+		Where("check_in < ? AND check_out > ?", to, from).
+		Find(&bookings).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var blocks []models.BlockedDate
+	err = r.DB.WithContext(ctx).
+		Where("property_id = ?", propertyID).
+		Where("start_date < ? AND end_date > ?", to, from).
+		Find(&blocks).Error
+	if err != nil {
+		return nil, nil, err
+	}
+	return bookings, blocks, nil
+}
+
+// BlockDates takes a range of nights off the market for a property owned by
+// ownerID. Ranges that overlap an active booking are rejected.
+func (r *BookingRepo) BlockDates(ctx context.Context, ownerID uuid.UUID, block *models.BlockedDate) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, err := lockOwnedProperty(tx, block.PropertyID, ownerID); err != nil {
+			return err
+		}
+
+		var overlapping int64
+		err := tx.Model(&models.Booking{}).
+			Where("property_id = ?", block.PropertyID).
+			Where("status IN ?", activeStatuses).
+			Where("check_in < ? AND check_out > ?", block.EndDate, block.StartDate).
+			Count(&overlapping).Error
+		if err != nil {
+			return err
+		}
+		if overlapping > 0 {
+			return ErrBookingOverlap
+		}
+
+		return tx.Create(block).Error
+	})
+}
+
+// UnblockDates removes a blocked range from a property owned by ownerID.
+func (r *BookingRepo) UnblockDates(ctx context.Context, ownerID, propertyID, blockID uuid.UUID) error {
+	result := r.DB.WithContext(ctx).
+		Where("id = ? AND property_id = ?", blockID, propertyID).
+		Where("property_id IN (?)", r.DB.Model(&models.Property{}).Select("id").Where("owner_id = ?", ownerID)).
+		Delete(&models.BlockedDate{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrBlockedDateNotFound
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"airbnb/models"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestExpirePendingBookings(t *testing.T) {
+	db := newTestDB(t)
+	owner := createOwner(t, db)
+	property := models.Property{Name: "cabin", Price: 10000, Currency: models.DefaultCurrency, Location: "lake", OwnerID: owner.ID}
+	if err := NewPropertyRepo(db).CreateProperty(t.Context(), &property, nil); err != nil {
+		t.Fatalf("create property: %v", err)
+	}
+
+	today := models.Today(time.Now())
+	bookings := map[string]*models.Booking{}
+	for name, checkIn := range map[string]time.Time{
+		"yesterday": today.AddDate(0, 0, -1),
+		"today":     today,
+		"tomorrow":  today.AddDate(0, 0, 1),
+	} {
+		// Created directly: the API refuses check-ins in the past.
+		booking := &models.Booking{UserID: owner.ID, PropertyID: property.ID, CheckIn: checkIn, CheckOut: checkIn.AddDate(0, 0, 1), Guests: 1, Currency: models.DefaultCurrency, Status: models.Pending}
+		if err := db.Create(booking).Error; err != nil {
+			t.Fatalf("create booking: %v", err)
+		}
+		bookings[name] = booking
+	}
+
+	repo := NewBookingRepo(db)
+	expired, err := repo.ExpirePendingBookings(t.Context(), today)
+	if err != nil || expired != 1 {
+		t.Fatalf("expired %d, %v; want 1", expired, err)
+	}
+	for name, want := range map[string]string{"yesterday": models.Expired, "today": models.Pending, "tomorrow": models.Pending} {
+		booking, err := repo.GetBookingByID(t.Context(), bookings[name].ID)
+		if err != nil || booking.Status != want {
+			t.Fatalf("%s booking: %+v, %v; want %s", name, booking, err, want)
+		}
+	}
+}
+
+func TestBlockedDates(t *testing.T) {
+	db := newTestDB(t)
+	owner, guest := createOwner(t, db), createGuest(t, db)
+	property := models.Property{Name: "cabin", Price: 10000, MaxGuests: 2, Currency: models.DefaultCurrency, Location: "lake", OwnerID: owner.ID}
+	if err := NewPropertyRepo(db).CreateProperty(t.Context(), &property, nil); err != nil {
+		t.Fatalf("create property: %v", err)
+	}
+	now := time.Now()
+	day := func(offset int) time.Time { return models.Today(now).AddDate(0, 0, 10+offset) }
+	for status, checkIn := range map[string]int{models.Pending: 0, models.Cancelled: 5} {
+		booking := &models.Booking{UserID: guest.ID, PropertyID: property.ID, CheckIn: day(checkIn), CheckOut: day(checkIn + 3), Guests: 1, Currency: models.DefaultCurrency, Status: status}
+		if err := db.Create(booking).Error; err != nil {
+			t.Fatalf("create booking: %v", err)
+		}
+	}
+	repo := NewBookingRepo(db)
+
+	if err := repo.BlockDates(t.Context(), owner.ID, &models.BlockedDate{PropertyID: property.ID, StartDate: day(2), EndDate: day(4)}); !errors.Is(err, ErrBookingOverlap) {
+		t.Fatalf("block over a booking: err = %v, want %v", err, ErrBookingOverlap)
+	}
+	// Cancelled bookings leave their nights free to block.
+	block := &models.BlockedDate{PropertyID: property.ID, StartDate: day(5), EndDate: day(8)}
+	if err := repo.BlockDates(t.Context(), owner.ID, block); err != nil {
+		t.Fatalf("block dates: %v", err)
+	}
+	if err := repo.BlockDates(t.Context(), guest.ID, &models.BlockedDate{PropertyID: property.ID, StartDate: day(20), EndDate: day(21)}); !errors.Is(err, ErrPropertyNotFound) {
+		t.Fatalf("block someone else's property: err = %v, want %v", err, ErrPropertyNotFound)
+	}
+
+	bookings, blocks, err := repo.GetAvailability(t.Context(), property.ID, day(2), day(6))
+	if err != nil {
+		t.Fatalf("availability: %v", err)
+	}
+	if len(bookings) != 1 || bookings[0].Status != models.Pending || len(blocks) != 1 || blocks[0].ID != block.ID {
+		t.Fatalf("availability = %+v, %+v", bookings, blocks)
+	}
+	if bookings, blocks, err := repo.GetAvailability(t.Context(), property.ID, day(2), day(5)); err != nil || len(bookings) != 1 || len(blocks) != 0 {
+		t.Fatalf("availability up to the block = %+v, %+v, %v", bookings, blocks, err)
+	}
+
+	stay := func() *models.Booking {
+		return &models.Booking{UserID: guest.ID, PropertyID: property.ID, CheckIn: day(4), CheckOut: day(6), Guests: 1}
+	}
+	if err := repo.CreateBooking(t.Context(), stay(), now); !errors.Is(err, ErrDatesBlocked) {
+		t.Fatalf("book across the block: err = %v, want %v", err, ErrDatesBlocked)
+	}
+	if err := repo.UnblockDates(t.Context(), guest.ID, property.ID, block.ID); !errors.Is(err, ErrBlockedDateNotFound) {
+		t.Fatalf("unblock as someone else: err = %v, want %v", err, ErrBlockedDateNotFound)
+	}
+	if err := repo.UnblockDates(t.Context(), owner.ID, property.ID, block.ID); err != nil {
+		t.Fatalf("unblock: %v", err)
+	}
+	if _, blocks, err := repo.GetAvailability(t.Context(), property.ID, day(2), day(6)); err != nil || len(blocks) != 0 {
+		t.Fatalf("blocks after unblocking = %+v, %v", blocks, err)
+	}
+	if err := repo.CreateBooking(t.Context(), stay(), now); err != nil {
+		t.Fatalf("book after unblocking: %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var ErrInvalidCursor = NewError(ErrInvalid, "invalid_cursor", "invalid cursor")
+
+// cursor marks the last row of a page for keyset pagination: the value of the
+// sort column and the row's ID, which breaks ties. Kind records which sort the
+// cursor was issued for so it cannot be replayed against another ordering.
+type cursor struct {
+	Kind  string          `json:"k"`
+	Value json.RawMessage `json:"v"`
+	ID    uuid.UUID       `json:"id"`
+}
+
+func encodeCursor(kind string, value interface{}, id uuid.UUID) (string, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(cursor{Kind: kind, Value: raw, ID: id})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeCursor parses an opaque cursor issued for kind, unmarshalling its sort
+// value into value.
+func decodeCursor(s, kind string, value interface{}) (uuid.UUID, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return uuid.Nil, ErrInvalidCursor
+	}
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.Kind != kind {
+		return uuid.Nil, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(c.Value, value); err != nil {
+		return uuid.Nil, ErrInvalidCursor
+	}
+	return c.ID, nil
+}
+
+// newestFirst orders a query on table by creation time, newest first, and
+// resumes after the row the cursor points at. One row more than limit is
+// fetched so the caller can tell whether there is a next page; see nextPage.
+func newestFirst(query *gorm.DB, table, kind, after string, limit int) (*gorm.DB, error) {
+	return latestFirst(query, table, "created_at", kind, after, limit)
+}
+
+// latestFirst is newestFirst over another time column of table.
+func latestFirst(query *gorm.DB, table, column, kind, after string, limit int) (*gorm.DB, error) {
+	if after != "" {
+		var at time.Time
+		id, err := decodeCursor(after, kind, &at)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where(fmt.Sprintf("(%[1]s.%[2]s, %[1]s.id) < (?, ?)", table, column), at, id)
+	}
+	return query.
+		Order(fmt.Sprintf("%[1]s.%[2]s DESC, %[1]s.id DESC", table, column)).
+		Limit(limit + 1), nil
+}
+
+// nextPage trims a newestFirst or latestFirst result to limit rows and
+// returns the cursor for the next page, or "" if this was the last one.
+func nextPage[T any](rows []T, kind string, limit int, position func(T) (time.Time, uuid.UUID)) ([]T, string, error) {
+	if len(rows) <= limit {
+		return rows, "", nil
+	}
+	rows = rows[:limit]
+	createdAt, id := position(rows[len(rows)-1])
+	next, err := encodeCursor(kind, createdAt, id)
+	if err != nil {
+		return nil, "", err
+	}
+	return rows, next, nil
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// Kinds of failure. Every error a repository returns for a reason other than
+// an unexpected database failure wraps one of these, so callers can decide how
+// to respond without knowing the specific error.
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrForbidden    = errors.New("forbidden")
+	ErrInvalid      = errors.New("invalid")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrTooLarge     = errors.New("too large")
+	ErrUnsupported  = errors.New("unsupported media type")
+)
+
+// Error is a failure of a known kind. Code is a stable identifier clients can
+// match on and Message is safe to show them.
+type Error struct {
+	Kind    error
+	Code    string
+	Message string
+}
+
+func NewError(kind error, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+var ErrDuplicate = NewError(ErrConflict, "duplicate", "a record with the same details already exists")
+
+// translate maps GORM errors onto the kinds above: a missing row becomes
+// notFound and a unique constraint violation ErrDuplicate. Other errors are
+// returned unchanged. Unique violations are only recognised with
+// gorm.Config.TranslateError set.
+func translate(err, notFound error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return notFound
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return ErrDuplicate
+	}
+	return err
+}
@@ -0,0 +1,256 @@
+package repository
+
+import (
+	"airbnb/models"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrConversationNotFound = NewError(ErrNotFound, "conversation_not_found", "conversation not found")
+	ErrMessageOwnProperty   = NewError(ErrForbidden, "own_property", "hosts cannot start a conversation about their own property")
+)
+
+const (
+	inboxCursorKind   = "inbox"
+	messageCursorKind = "messages"
+)
+
+type MessageRepo struct {
+	DB *gorm.DB
+}
+
+func NewMessageRepo(db *gorm.DB) *MessageRepo {
+	return &MessageRepo{DB: db}
+}
+
+// StartConversation sends a guest's message to the host of a property, in the
+// thread about bookingID when it is set, creating the thread the first time.
+// Questions outside a booking can only be asked about listed properties; a
+// booking's thread stays open whatever happens to the listing.
+func (r *MessageRepo) StartConversation(ctx context.Context, guestID, propertyID uuid.UUID, bookingID *uuid.UUID, body string, now time.Time) (*models.Conversation, error) {
+	var conversation models.Conversation
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		query := tx.Where("id = ?", propertyID)
+		if bookingID != nil {
+			var count int64
+			err := tx.Model(&models.Booking{}).
+				Where("id = ? AND user_id = ? AND property_id = ?", *bookingID, guestID, propertyID).
+				Count(&count).Error
+			if err != nil {
+				return err
+			}
+			if count == 0 {
+				return ErrBookingNotFound
+			}
+		} else {
+			query = listed(query)
+		}
+		// The lock serialises guests' first messages so each gets one thread.
+		property, err := lockPropertyRow(query)
+		if err != nil {
+			return err
+		}
+		if property.OwnerID == guestID {
+			return ErrMessageOwnProperty
+		}
+
+		thread := tx.Where("property_id = ? AND guest_id = ?", propertyID, guestID)
+		if bookingID != nil {
+			thread = thread.Where("booking_id = ?", *bookingID)
+		} else {
+			thread = thread.Where("booking_id IS NULL")
+		}
+		err = thread.First(&conversation).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			conversation = models.Conversation{
+				PropertyID:    propertyID,
+				BookingID:     bookingID,
+				GuestID:       guestID,
+				HostID:        property.OwnerID,
+				LastMessageAt: now,
+			}
+			err = tx.Omit(clause.Associations).Create(&conversation).Error
+		}
+		if err != nil {
+			return err
+		}
+		return sendMessage(tx, &conversation, &models.Message{SenderID: guestID, Body: body}, now)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.getConversation(ctx, guestID, conversation.ID)
+}
+
+// SendMessage adds accountID's message to a conversation they take part in.
+func (r *MessageRepo) SendMessage(ctx context.Context, accountID, conversationID uuid.UUID, body string, now time.Time) (*models.Message, *models.Conversation, error) {
+	message := models.Message{SenderID: accountID, Body: body}
+	var conversation models.Conversation
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := participantOf(tx, accountID).
+			Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate}).
+			First(&conversation, "id = ?", conversationID).Error
+		if err != nil {
+			return translate(err, ErrConversationNotFound)
+		}
+		return sendMessage(tx, &conversation, &message, now)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return &message, &conversation, nil
+}
+
+// sendMessage stores message in conversation at now and moves the thread's
+// last activity and the sender's read marker to it: whoever replies has read
+// what came before.
+func sendMessage(tx *gorm.DB, conversation *models.Conversation, message *models.Message, now time.Time) error {
+	message.ConversationID = conversation.ID
+	message.CreatedAt = now
+	if err := tx.Create(message).Error; err != nil {
+		return err
+	}
+	conversation.LastMessageAt = now
+	updates := map[string]any{"last_message_at": now}
+	if message.SenderID == conversation.GuestID {
+		conversation.GuestReadAt = &now
+		updates["guest_read_at"] = now
+	} else {
+		conversation.HostReadAt = &now
+		updates["host_read_at"] = now
+	}
+	return tx.Model(conversation).Updates(updates).Error
+}
+
+// MarkRead moves accountID's read marker in a conversation to now.
+func (r *MessageRepo) MarkRead(ctx context.Context, accountID, conversationID uuid.UUID, now time.Time) (*models.Conversation, error) {
+	conversation, err := r.getConversation(ctx, accountID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	column := "host_read_at"
+	if accountID == conversation.GuestID {
+		column = "guest_read_at"
+		conversation.GuestReadAt = &now
+	} else {
+		conversation.HostReadAt = &now
+	}
+	err = r.DB.WithContext(ctx).Model(&models.Conversation{}).Where("id = ?", conversation.ID).Update(column, now).Error
+	if err != nil {
+		return nil, err
+	}
+	return conversation, nil
+}
+
+// GetMessages returns one page of a conversation's messages, newest first,
+// with the conversation so callers can tell which have been read.
+func (r *MessageRepo) GetMessages(ctx context.Context, accountID, conversationID uuid.UUID, search models.MessageSearch) ([]models.Message, *models.Conversation, string, error) {
+	var conversation models.Conversation
+	if err := participantOf(r.DB.WithContext(ctx), accountID).First(&conversation, "id = ?", conversationID).Error; err != nil {
+		return nil, nil, "", translate(err, ErrConversationNotFound)
+	}
+	query, err := newestFirst(r.DB.WithContext(ctx).Where("conversation_id = ?", conversation.ID), "messages", messageCursorKind, search.Cursor, search.Limit)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	var messages []models.Message
+	if err := query.Find(&messages).Error; err != nil {
+		return nil, nil, "", err
+	}
+	messages, next, err := nextPage(messages, messageCursorKind, search.Limit, func(m models.Message) (time.Time, uuid.UUID) {
+		return m.CreatedAt, m.ID
+	})
+	if err != nil {
+		return nil, nil, "", err
+	}
+	return messages, &conversation, next, nil
+}
+
+// GetInbox returns one page of accountID's conversations as guest or host,
+// most recently active first, with the unread count of each, and the cursor
+// for the next page. A conversation that gets a new message while paging
+// moves to the top and may be skipped until the first page is fetched again.
+func (r *MessageRepo) GetInbox(ctx context.Context, accountID uuid.UUID, search models.InboxSearch) ([]models.Conversation, map[uuid.UUID]int64, string, error) {
+	query, err := latestFirst(withParties(participantOf(r.DB.WithContext(ctx), accountID)), "conversations", "last_message_at", inboxCursorKind, search.Cursor, search.Limit)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	var conversations []models.Conversation
+	if err := query.Find(&conversations).Error; err != nil {
+		return nil, nil, "", err
+	}
+	conversations, next, err := nextPage(conversations, inboxCursorKind, search.Limit, func(c models.Conversation) (time.Time, uuid.UUID) {
+		return c.LastMessageAt, c.ID
+	})
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	ids := make([]uuid.UUID, 0, len(conversations))
+	for _, c := range conversations {
+		ids = append(ids, c.ID)
+	}
+	var counts []struct {
+		ConversationID uuid.UUID
+		Unread         int64
+	}
+	err = unread(r.DB.WithContext(ctx), accountID).
+		Where("messages.conversation_id IN ?", ids).
+		Select("messages.conversation_id, COUNT(*) AS unread").
+		Group("messages.conversation_id").
+		Scan(&counts).Error
+	if err != nil {
+		return nil, nil, "", err
+	}
+	unreadCounts := make(map[uuid.UUID]int64, len(counts))
+	for _, c := range counts {
+		unreadCounts[c.ConversationID] = c.Unread
+	}
+	return conversations, unreadCounts, next, nil
+}
+
+// CountUnread returns how many messages accountID has not read across all of
+// their conversations.
+func (r *MessageRepo) CountUnread(ctx context.Context, accountID uuid.UUID) (int64, error) {
+	var count int64
+	err := unread(r.DB.WithContext(ctx), accountID).Count(&count).Error
+	return count, err
+}
+
+func (r *MessageRepo) getConversation(ctx context.Context, accountID, conversationID uuid.UUID) (*models.Conversation, error) {
+	var conversation models.Conversation
+	err := withParties(participantOf(r.DB.WithContext(ctx), accountID)).First(&conversation, "conversations.id = ?", conversationID).Error
+	if err != nil {
+		return nil, translate(err, ErrConversationNotFound)
+	}
+	return &conversation, nil
+}
+
+// participantOf restricts a conversations query to those accountID takes part
+// in, so nobody else can tell a thread exists.
+func participantOf(db *gorm.DB, accountID uuid.UUID) *gorm.DB {
+	return db.Where("(conversations.guest_id = ? OR conversations.host_id = ?)", accountID, accountID)
+}
+
+// withParties loads a conversation's property and participants, including
+// deleted ones so old threads keep their names.
+func withParties(db *gorm.DB) *gorm.DB {
+	unscoped := func(db *gorm.DB) *gorm.DB { return db.Unscoped() }
+	return db.Preload("Property", unscoped).Preload("Guest", unscoped).Preload("Host", unscoped)
+}
+
+// unread selects the messages in accountID's conversations sent by the other
+// participant after accountID's read marker.
+func unread(db *gorm.DB, accountID uuid.UUID) *gorm.DB {
+	return db.Model(&models.Message{}).
+		Joins("JOIN conversations ON conversations.id = messages.conversation_id AND conversations.deleted_at IS NULL").
+		Where("messages.sender_id <> ?", accountID).
+		Where("((conversations.guest_id = ? AND (conversations.guest_read_at IS NULL OR messages.created_at > conversations.guest_read_at)) OR "+
+			"(conversations.host_id = ? AND (conversations.host_read_at IS NULL OR messages.created_at > conversations.host_read_at)))", accountID, accountID)
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"airbnb/models"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMessageUnreadCounts(t *testing.T) {
+	db := newTestDB(t)
+	property := models.Property{Name: "cabin", Price: 10000, Location: "lake"}
+	createProperties(t, db, &property)
+	guest, stranger := createGuest(t, db), createGuest(t, db)
+	host := property.OwnerID
+	repo := NewMessageRepo(db)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	at := func(minutes int) time.Time { return now.Add(time.Duration(minutes) * time.Minute) }
+	conversation, err := repo.StartConversation(t.Context(), guest.ID, property.ID, nil, "is there parking?", at(0))
+	if err != nil {
+		t.Fatalf("start conversation: %v", err)
+	}
+	unread := func(step string, wantGuest, wantHost int64) {
+		t.Helper()
+		for _, side := range []struct {
+			name  string
+			count int64
+		}{{"guest", wantGuest}, {"host", wantHost}} {
+			id := guest.ID
+			if side.name == "host" {
+				id = host
+			}
+			count, err := repo.CountUnread(t.Context(), id)
+			if err != nil {
+				t.Fatalf("%s: count %s unread: %v", step, side.name, err)
+			}
+			_, counts, _, err := repo.GetInbox(t.Context(), id, models.InboxSearch{Limit: models.DefaultPageSize})
+			if err != nil {
+				t.Fatalf("%s: %s inbox: %v", step, side.name, err)
+			}
+			if count != side.count || counts[conversation.ID] != side.count {
+				t.Errorf("%s: %s unread = %d, inbox %d, want %d", step, side.name, count, counts[conversation.ID], side.count)
+			}
+		}
+	}
+
+	unread("first message", 0, 1)
+	if _, err := repo.MarkRead(t.Context(), host, conversation.ID, at(1)); err != nil {
+		t.Fatalf("host marks read: %v", err)
+	}
+	unread("host read", 0, 0)
+	for _, minute := range []int{2, 3} {
+		if _, _, err := repo.SendMessage(t.Context(), guest.ID, conversation.ID, "hello?", at(minute)); err != nil {
+			t.Fatalf("guest sends: %v", err)
+		}
+	}
+	unread("guest follows up", 0, 2)
+	if _, _, err := repo.SendMessage(t.Context(), host, conversation.ID, "yes, two spaces", at(4)); err != nil {
+		t.Fatalf("host replies: %v", err)
+	}
+	unread("host replies", 1, 0)
+
+	// A read marker behind the account's own messages does not count them.
+	if _, err := repo.MarkRead(t.Context(), host, conversation.ID, at(-1)); err != nil {
+		t.Fatalf("host marks read early: %v", err)
+	}
+	unread("host marker moved back", 1, 3)
+	if _, err := repo.MarkRead(t.Context(), guest.ID, conversation.ID, at(5)); err != nil {
+		t.Fatalf("guest marks read: %v", err)
+	}
+	unread("guest read", 0, 3)
+
+	// Someone outside the conversation cannot see or touch it.
+	if _, _, err := repo.SendMessage(t.Context(), stranger.ID, conversation.ID, "hi", at(6)); !errors.Is(err, ErrConversationNotFound) {
+		t.Errorf("stranger sends: err = %v, want %v", err, ErrConversationNotFound)
+	}
+	if _, err := repo.MarkRead(t.Context(), stranger.ID, conversation.ID, at(6)); !errors.Is(err, ErrConversationNotFound) {
+		t.Errorf("stranger marks read: err = %v, want %v", err, ErrConversationNotFound)
+	}
+	if _, _, _, err := repo.GetMessages(t.Context(), stranger.ID, conversation.ID, models.MessageSearch{Limit: models.DefaultPageSize}); !errors.Is(err, ErrConversationNotFound) {
+		t.Errorf("stranger reads messages: err = %v, want %v", err, ErrConversationNotFound)
+	}
+	conversations, _, _, err := repo.GetInbox(t.Context(), stranger.ID, models.InboxSearch{Limit: models.DefaultPageSize})
+	if err != nil || len(conversations) != 0 {
+		t.Errorf("stranger inbox = %d conversations, %v", len(conversations), err)
+	}
+	if count, err := repo.CountUnread(t.Context(), stranger.ID); err != nil || count != 0 {
+		t.Errorf("stranger unread = %d, %v", count, err)
+	}
+	unread("after the stranger", 0, 3)
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"airbnb/models"
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrPhotoNotFound   = NewError(ErrNotFound, "photo_not_found", "photo not found")
+	ErrTooManyPhotos   = NewError(ErrConflict, "too_many_photos", "a property can have up to 30 photos")
+	ErrInvalidPhotoIDs = NewError(ErrInvalid, "invalid_photo_order", "photo_ids must list each of the property's photos once")
+)
+
+type PhotoRepo struct {
+	DB *gorm.DB
+}
+
+func NewPhotoRepo(db *gorm.DB) *PhotoRepo {
+	return &PhotoRepo{DB: db}
+}
+
+// AddPhoto adds photo, whose files are already stored, after the other photos
+// of a property owned by ownerID.
+func (r *PhotoRepo) AddPhoto(ctx context.Context, ownerID uuid.UUID, photo *models.Photo) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// The lock serialises uploads so positions stay unique.
+		if _, err := lockOwnedProperty(tx, photo.PropertyID, ownerID); err != nil {
+			return err
+		}
+		var count int64
+		if err := tx.Model(&models.Photo{}).Where("property_id = ?", photo.PropertyID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count >= models.MaxPropertyPhotos {
+			return ErrTooManyPhotos
+		}
+		photo.Position = int(count)
+		return tx.Create(photo).Error
+	})
+}
+
+// ReorderPhotos puts the photos of a property owned by ownerID in the order of
+// photoIDs, which must name each of them once, and returns them in that order.
+func (r *PhotoRepo) ReorderPhotos(ctx context.Context, ownerID, propertyID uuid.UUID, photoIDs []uuid.UUID) ([]models.Photo, error) {
+	return r.arrange(ctx, ownerID, propertyID, func(photos []models.Photo) ([]models.Photo, error) {
+		byID := make(map[uuid.UUID]models.Photo, len(photos))
+		for _, photo := range photos {
+			byID[photo.ID] = photo
+		}
+		if len(photoIDs) != len(photos) {
+			return nil, ErrInvalidPhotoIDs
+		}
+		ordered := make([]models.Photo, 0, len(photos))
+		for _, id := range photoIDs {
+			photo, ok := byID[id]
+			if !ok {
+				return nil, ErrInvalidPhotoIDs
+			}
+			delete(byID, id)
+			ordered = append(ordered, photo)
+		}
+		return ordered, nil
+	})
+}
+
+// SetCoverPhoto moves a photo of a property owned by ownerID to the front,
+// making it the cover, and returns the photos in their new order.
+func (r *PhotoRepo) SetCoverPhoto(ctx context.Context, ownerID, propertyID, photoID uuid.UUID) ([]models.Photo, error) {
+	return r.arrange(ctx, ownerID, propertyID, func(photos []models.Photo) ([]models.Photo, error) {
+		for i, photo := range photos {
+			if photo.ID == photoID {
+				ordered := append([]models.Photo{photo}, photos[:i]...)
+				return append(ordered, photos[i+1:]...), nil
+			}
+		}
+		return nil, ErrPhotoNotFound
+	})
+}
+
+// arrange stores the order that reorder gives the photos of a property owned
+// by ownerID, passed to it in their current order.
+func (r *PhotoRepo) arrange(ctx context.Context, ownerID, propertyID uuid.UUID, reorder func([]models.Photo) ([]models.Photo, error)) ([]models.Photo, error) {
+	var ordered []models.Photo
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, err := lockOwnedProperty(tx, propertyID, ownerID); err != nil {
+			return err
+		}
+		var photos []models.Photo
+		if err := tx.Where("property_id = ?", propertyID).Order("position").Find(&photos).Error; err != nil {
+			return err
+		}
+		var err error
+		if ordered, err = reorder(photos); err != nil {
+			return err
+		}
+		for i := range ordered {
+			if ordered[i].Position == i {
+				continue
+			}
+			ordered[i].Position = i
+			if err := tx.Model(&ordered[i]).Update("position", i).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ordered, nil
+}
+
+// DeletePhoto removes a photo of a property owned by ownerID and closes the
+// gap it leaves in the order. The photo is returned so its files can be
+// deleted from the BlobStore.
+func (r *PhotoRepo) DeletePhoto(ctx context.Context, ownerID, propertyID, photoID uuid.UUID) (*models.Photo, error) {
+	var photo models.Photo
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, err := lockOwnedProperty(tx, propertyID, ownerID); err != nil {
+			return err
+		}
+		if err := tx.Where("id = ? AND property_id = ?", photoID, propertyID).First(&photo).Error; err != nil {
+			return translate(err, ErrPhotoNotFound)
+		}
+		// Photos are hard-deleted: their files go with them.
+		if err := tx.Unscoped().Delete(&photo).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Photo{}).
+			Where("property_id = ? AND position > ?", propertyID, photo.Position).
+			Update("position", gorm.Expr("position - 1")).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &photo, nil
+}
+
+func inPhotoOrder(db *gorm.DB) *gorm.DB {
+	return db.Order("photos.position")
+}
@@ -21,16 +21,11 @@ func ConnectToDB(dsn string) (*gorm.DB, error) {
 		},
 	)
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: newLogger})
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: newLogger, TranslateError: true})
 	if err != nil {
 		log.Println("Error in connection", err)
 		return nil, err
 	}
-	// err = db.AutoMigrate(&models.User{}, &models.PropertyOwner{}, &models.Property{}, &models.Booking{})
-	// if err != nil {
-	// 	log.Printf("unable to migrate data models : %s", err)
-	// 	return nil, err
-	// }
 
 	return db, nil
 }
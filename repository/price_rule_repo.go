@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"airbnb/models"
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrPriceRuleNotFound = NewError(ErrNotFound, "price_rule_not_found", "price rule not found")
+	ErrTooManyPriceRules = NewError(ErrConflict, "too_many_price_rules", "a property can have up to 100 price rules")
+)
+
+type PriceRuleRepo struct {
+	DB *gorm.DB
+}
+
+func NewPriceRuleRepo(db *gorm.DB) *PriceRuleRepo {
+	return &PriceRuleRepo{DB: db}
+}
+
+// ListPriceRules returns a property's price rules in the order they were
+// added, which decides ties between them.
+func (r *PriceRuleRepo) ListPriceRules(ctx context.Context, propertyID uuid.UUID) ([]models.PriceRule, error) {
+	rules := []models.PriceRule{}
+	err := inRuleOrder(r.DB.WithContext(ctx)).Where("property_id = ?", propertyID).Find(&rules).Error
+	return rules, err
+}
+
+// AddPriceRule adds rule to a property owned by ownerID.
+func (r *PriceRuleRepo) AddPriceRule(ctx context.Context, ownerID uuid.UUID, rule *models.PriceRule) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// The lock keeps the count honest and serialises the change with
+		// bookings being priced.
+		if _, err := lockOwnedProperty(tx, rule.PropertyID, ownerID); err != nil {
+			return err
+		}
+		var count int64
+		if err := tx.Model(&models.PriceRule{}).Where("property_id = ?", rule.PropertyID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count >= models.MaxPriceRules {
+			return ErrTooManyPriceRules
+		}
+		return tx.Create(rule).Error
+	})
+}
+
+// DeletePriceRule removes a price rule from a property owned by ownerID.
+// Bookings already made keep the price they were given.
+func (r *PriceRuleRepo) DeletePriceRule(ctx context.Context, ownerID, propertyID, ruleID uuid.UUID) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, err := lockOwnedProperty(tx, propertyID, ownerID); err != nil {
+			return err
+		}
+		result := tx.Unscoped().Where("id = ? AND property_id = ?", ruleID, propertyID).Delete(&models.PriceRule{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrPriceRuleNotFound
+		}
+		return nil
+	})
+}
+
+// loadPriceRules fills in property's PriceRules for pricing a stay.
+func loadPriceRules(tx *gorm.DB, property *models.Property) error {
+	return inRuleOrder(tx).Where("property_id = ?", property.ID).Find(&property.PriceRules).Error
+}
+
+func inRuleOrder(db *gorm.DB) *gorm.DB {
+	return db.Order("price_rules.created_at, price_rules.id")
+}
@@ -5,10 +5,20 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrPropertyHasBookings = NewError(ErrConflict, "property_has_bookings", "property has upcoming confirmed bookings; pass force=true to cancel them")
+	ErrPropertyOccupied    = NewError(ErrConflict, "property_occupied", "property has guests checked in")
+	ErrAlreadyUnlisted     = NewError(ErrConflict, "property_already_unlisted", "property is already unlisted")
+	ErrNotUnlisted         = NewError(ErrConflict, "property_not_unlisted", "property is not unlisted")
 )
 
 type PropertyRepo struct {
@@ -21,18 +31,52 @@ func NewPropertyRepo(db *gorm.DB) *PropertyRepo {
 	}
 }
 
-func (r *PropertyRepo) CreateProperty(ctx context.Context, property *models.Property) error {
-	if err := r.DB.WithContext(ctx).Create(property).Error; err != nil {
+// CreateProperty stores a new property with the catalogue amenities named by
+// amenityKeys, failing with ErrUnknownAmenity if any is not in the catalogue.
+func (r *PropertyRepo) CreateProperty(ctx context.Context, property *models.Property, amenityKeys []string) error {
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		amenities, err := findAmenities(tx, amenityKeys)
+		if err != nil {
+			return err
+		}
+		property.Amenities = amenities
+		// Link the amenities without writing to the catalogue.
+		return tx.Omit("Amenities.*").Create(property).Error
+	})
+	if err != nil {
+		var repoErr *Error
+		if errors.As(err, &repoErr) {
+			return err
+		}
 		return fmt.Errorf("failed to create property: %w", err)
 	}
 	return nil
 }
 
+// GetPropertyByID returns a property guests can see, with its price rules for
+// quoting, or ErrPropertyNotFound if it does not exist or has been unlisted.
 func (r *PropertyRepo) GetPropertyByID(ctx context.Context, id uuid.UUID) (*models.Property, error) {
 	var property models.Property
-	if err := r.DB.WithContext(ctx).Preload("Owner").First(&property, "id = ?", id).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, nil
+	if err := withDetails(listed(r.DB.WithContext(ctx))).Preload("Owner").Preload("PriceRules", inRuleOrder).First(&property, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPropertyNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch property: %w", err)
+	}
+	return &property, nil
+}
+
+// GetOwnedPropertyByID returns a property owned by ownerID, listed or not. It
+// returns ErrPropertyNotFound when the property does not exist or belongs to
+// another owner.
+func (r *PropertyRepo) GetOwnedPropertyByID(ctx context.Context, ownerID, id uuid.UUID) (*models.Property, error) {
+	var property models.Property
+	err := withDetails(r.DB.WithContext(ctx)).Preload("Owner").
+		Where("owner_id = ?", ownerID).
+		First(&property, "id = ?", id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPropertyNotFound
 		}
 		return nil, fmt.Errorf("failed to fetch property: %w", err)
 	}
@@ -41,65 +85,399 @@ func (r *PropertyRepo) GetPropertyByID(ctx context.Context, id uuid.UUID) (*mode
 
 func (r *PropertyRepo) GetAllProperties(ctx context.Context, ownerID uuid.UUID) ([]models.Property, error) {
 	var properties []models.Property
-	if err := r.DB.WithContext(ctx).Where("owner_id = ?", ownerID).Preload("Owner").Find(&properties).Error; err != nil {
+	if err := withDetails(r.DB.WithContext(ctx)).Where("owner_id = ?", ownerID).Preload("Owner").Find(&properties).Error; err != nil {
 		return nil, fmt.Errorf("failed to fetch properties: %w", err)
 	}
 	return properties, nil
 }
 
-func (r *PropertyRepo) GetProperties(ctx context.Context) ([]models.Property, error) {
+// SearchProperties returns one page of the public listing matching search,
+// which must already be normalized, and the cursor for the next page ("" on
+// the last page). Rows are ordered by the sort column and then ID, so the
+// cursor is a stable keyset position.
+func (r *PropertyRepo) SearchProperties(ctx context.Context, search models.PropertySearch) ([]models.Property, string, error) {
+	query := withDetails(listed(r.DB.WithContext(ctx))).Preload("Owner")
+
+	if search.Query != "" {
+		pattern := "%" + escapeLike(search.Query) + "%"
+		query = query.Where("("+containsText(r.DB, "properties.name")+" OR "+containsText(r.DB, "properties.description")+")", pattern, pattern)
+	}
+	if search.Location != "" {
+		query = query.Where(containsText(r.DB, "properties.location"), "%"+escapeLike(search.Location)+"%")
+	}
+	if search.MinPrice != nil {
+		query = query.Where("properties.price >= ?", *search.MinPrice)
+	}
+	if search.MaxPrice != nil {
+		query = query.Where("properties.price <= ?", *search.MaxPrice)
+	}
+	if search.Guests > 0 {
+		query = query.Where("properties.max_guests >= ?", search.Guests)
+	}
+	if search.Box != nil {
+		query = withinBox(query, *search.Box)
+	}
+	if search.PropertyType != "" {
+		query = query.Where("properties.property_type = ?", search.PropertyType)
+	}
+	if search.MinBedrooms > 0 {
+		query = query.Where("properties.bedrooms >= ?", search.MinBedrooms)
+	}
+	if search.MinBeds > 0 {
+		query = query.Where("properties.beds >= ?", search.MinBeds)
+	}
+	if search.MinBathrooms > 0 {
+		query = query.Where("properties.bathrooms >= ?", search.MinBathrooms)
+	}
+	if search.PetsAllowed {
+		query = query.Where("properties.pets_allowed = ?", true)
+	}
+	if search.SmokingAllowed {
+		query = query.Where("properties.smoking_allowed = ?", true)
+	}
+	if search.EventsAllowed {
+		query = query.Where("properties.events_allowed = ?", true)
+	}
+	if len(search.AmenityKeys) > 0 {
+		withAll := r.DB.Table("property_amenities").Select("property_id").
+			Where("amenity_key IN ?", search.AmenityKeys).
+			Group("property_id").
+			Having("COUNT(*) = ?", len(search.AmenityKeys))
+		query = query.Where("properties.id IN (?)", withAll)
+	}
+	if !search.From.IsZero() {
+		booked := r.DB.Model(&models.Booking{}).Select("1").
+			Where("bookings.property_id = properties.id").
+			Where("bookings.status IN ?", activeStatuses).
+			Where("bookings.check_in < ? AND bookings.check_out > ?", search.To, search.From)
+		blocked := r.DB.Model(&models.BlockedDate{}).Select("1").
+			Where("blocked_dates.property_id = properties.id").
+			Where("blocked_dates.start_date < ? AND blocked_dates.end_date > ?", search.To, search.From)
+		query = query.Where("NOT EXISTS (?) AND NOT EXISTS (?)", booked, blocked)
+	}
+
+	column, descending := "properties.created_at", true
+	switch search.Sort {
+	case models.SortPriceAsc:
+		column, descending = "properties.price", false
+	case models.SortPriceDesc:
+		column = "properties.price"
+	case models.SortRating:
+		column = "properties.rating_average"
+	}
+	direction, compare := "ASC", ">"
+	if descending {
+		direction, compare = "DESC", "<"
+	}
+
+	if search.Cursor != "" {
+		after, id, err := decodePropertyCursor(search)
+		if err != nil {
+			return nil, "", err
+		}
+		query = query.Where(fmt.Sprintf("(%s, properties.id) %s (?, ?)", column, compare), after, id)
+	}
+
 	var properties []models.Property
-	if err := r.DB.WithContext(ctx).Preload("Owner").Find(&properties).Error; err != nil {
-		return nil, fmt.Errorf("failed to fetch properties: %w", err)
+	err := query.
+		Order(fmt.Sprintf("%s %s, properties.id %s", column, direction, direction)).
+		Limit(search.Limit + 1).
+		Find(&properties).Error
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch properties: %w", err)
 	}
-	return properties, nil
+	if len(properties) <= search.Limit {
+		return properties, "", nil
+	}
+
+	properties = properties[:search.Limit]
+	last := properties[len(properties)-1]
+	var value interface{} = last.Price
+	switch search.Sort {
+	case models.SortNewest:
+		value = last.CreatedAt
+	case models.SortRating:
+		value = last.RatingAverage
+	}
+	next, err := encodeCursor(search.Sort, value, last.ID)
+	if err != nil {
+		return nil, "", err
+	}
+	return properties, next, nil
 }
 
-func (r *PropertyRepo) UpdateProperty(ctx context.Context, property *models.Property) error {
-	property.UpdatedAt = time.Now()
-	if err := r.DB.WithContext(ctx).Save(property).Error; err != nil {
-		return fmt.Errorf("failed to update property: %w", err)
+// decodePropertyCursor returns the sort value and ID a listing cursor points
+// at, decoding the value into the type of the search's sort column.
+func decodePropertyCursor(search models.PropertySearch) (interface{}, uuid.UUID, error) {
+	switch search.Sort {
+	case models.SortNewest:
+		var createdAt time.Time
+		id, err := decodeCursor(search.Cursor, search.Sort, &createdAt)
+		return createdAt, id, err
+	case models.SortRating:
+		var rating float64
+		id, err := decodeCursor(search.Cursor, search.Sort, &rating)
+		return rating, id, err
+	default:
+		var price int64
+		id, err := decodeCursor(search.Cursor, search.Sort, &price)
+		return price, id, err
 	}
-	return nil
 }
 
-func (r *PropertyRepo) DeleteProperty(ctx context.Context, id uuid.UUID) error {
-	if err := r.DB.WithContext(ctx).Delete(&models.Property{}, "id = ?", id).Error; err != nil {
-		return fmt.Errorf("failed to delete property: %w", err)
+// distanceKm is the haversine great-circle distance in kilometres between a
+// property and the point bound to its placeholders as (lat, lat, lng). The
+// square root is capped at 1 against rounding, with LEAST on Postgres and the
+// two-argument MIN on SQLite.
+func distanceKm(db *gorm.DB) string {
+	least := "LEAST"
+	if db.Dialector.Name() == "sqlite" {
+		least = "MIN"
 	}
-	return nil
+	return `6371 * 2 * ASIN(` + least + `(1, SQRT(
+	POWER(SIN(RADIANS(properties.latitude - ?) / 2), 2) +
+	COS(RADIANS(?)) * COS(RADIANS(properties.latitude)) * POWER(SIN(RADIANS(properties.longitude - ?) / 2), 2)
+)))`
 }
 
-func (r *PropertyRepo) GetPropertiesByOwnerID(ctx context.Context, ownerID uuid.UUID) ([]models.Property, error) {
+// NearbyProperties returns the properties within search.RadiusKm of the search
+// point, closest first. Candidates are narrowed with an indexable bounding box
+// before the exact distance is computed.
+func (r *PropertyRepo) NearbyProperties(ctx context.Context, search models.NearbySearch) ([]models.PropertyDistance, error) {
+	lat, lng := *search.Latitude, *search.Longitude
+	const kmPerDegree = 111.045
+	latDelta := search.RadiusKm / kmPerDegree
+	box := models.BoundingBox{MinLat: lat - latDelta, MaxLat: lat + latDelta, MinLng: -180, MaxLng: 180}
+	if cos := math.Cos(lat * math.Pi / 180); cos > 0.01 {
+		lngDelta := search.RadiusKm / (kmPerDegree * cos)
+		if lngDelta < 180 {
+			box.MinLng = normalizeLongitude(lng - lngDelta)
+			box.MaxLng = normalizeLongitude(lng + lngDelta)
+		}
+	}
+
+	var rows []struct {
+		ID         uuid.UUID
+		DistanceKm float64
+	}
+	distance := distanceKm(r.DB)
+	err := withinBox(listed(r.DB.WithContext(ctx)).Model(&models.Property{}), box).
+		Select("properties.id, "+distance+" AS distance_km", lat, lat, lng).
+		Where(distance+" <= ?", lat, lat, lng, search.RadiusKm).
+		Order("distance_km, properties.id").
+		Limit(search.Limit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch nearby properties: %w", err)
+	}
+	if len(rows) == 0 {
+		return []models.PropertyDistance{}, nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.ID)
+	}
 	var properties []models.Property
-	if err := r.DB.WithContext(ctx).Where("owner_id = ?", ownerID).Preload("Owner").Find(&properties).Error; err != nil {
-		return nil, fmt.Errorf("failed to fetch properties for owner: %w", err)
+	if err := withDetails(r.DB.WithContext(ctx)).Preload("Owner").Where("id IN ?", ids).Find(&properties).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch nearby properties: %w", err)
 	}
-	return properties, nil
+	byID := make(map[uuid.UUID]models.Property, len(properties))
+	for _, property := range properties {
+		byID[property.ID] = property
+	}
+
+	results := make([]models.PropertyDistance, 0, len(rows))
+	for _, row := range rows {
+		if property, ok := byID[row.ID]; ok {
+			results = append(results, models.PropertyDistance{Property: property, DistanceKm: row.DistanceKm})
+		}
+	}
+	return results, nil
+}
+
+// withDetails preloads what a property's description shows besides its own
+// columns and owner: its photos in display order and its amenities.
+func withDetails(query *gorm.DB) *gorm.DB {
+	return query.Preload("Photos", inPhotoOrder).Preload("Amenities")
+}
+
+// listed restricts a properties query to those guests can see.
+func listed(query *gorm.DB) *gorm.DB {
+	return query.Where("properties.unlisted_at IS NULL")
+}
+
+// withinBox restricts a properties query to those located inside box,
+// including boxes that cross the antimeridian.
+func withinBox(query *gorm.DB, box models.BoundingBox) *gorm.DB {
+	query = query.Where("properties.latitude BETWEEN ? AND ?", box.MinLat, box.MaxLat)
+	if box.MinLng <= box.MaxLng {
+		return query.Where("properties.longitude BETWEEN ? AND ?", box.MinLng, box.MaxLng)
+	}
+	return query.Where("(properties.longitude >= ? OR properties.longitude <= ?)", box.MinLng, box.MaxLng)
+}
+
+func normalizeLongitude(lng float64) float64 {
+	if lng > 180 {
+		return lng - 360
+	}
+	if lng < -180 {
+		return lng + 360
+	}
+	return lng
+}
+
+// containsText is a case-insensitive LIKE condition on column for a pattern
+// escaped with escapeLike: ILIKE on Postgres, where the trigram indexes serve
+// it, and LIKE on lowered text on SQLite, which has no ILIKE.
+func containsText(db *gorm.DB, column string) string {
+	if db.Dialector.Name() == "sqlite" {
+		return "LOWER(" + column + `) LIKE LOWER(?) ESCAPE '\'`
+	}
+	return column + ` ILIKE ? ESCAPE '\'`
+}
+
+// escapeLike escapes LIKE wildcards so user input matches literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
 }
 
-func (r *PropertyRepo) CreatePropertyOwner(ctx context.Context, owner *models.PropertyOwner) error {
-	if err := r.DB.WithContext(ctx).Create(owner).Error; err != nil {
-		return fmt.Errorf("failed to create property owner: %w", err)
+// UpdateProperty saves property's columns and, unless amenityKeys is nil,
+// replaces its amenities with the catalogue entries it names.
+func (r *PropertyRepo) UpdateProperty(ctx context.Context, property *models.Property, amenityKeys []string) error {
+	property.UpdatedAt = time.Now()
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(property).Error; err != nil {
+			return err
+		}
+		if amenityKeys == nil {
+			return nil
+		}
+		amenities, err := findAmenities(tx, amenityKeys)
+		if err != nil {
+			return err
+		}
+		if err := tx.Omit("Amenities.*").Model(property).Association("Amenities").Replace(amenities); err != nil {
+			return err
+		}
+		property.Amenities = amenities
+		return nil
+	})
+	if err != nil {
+		var repoErr *Error
+		if errors.As(err, &repoErr) {
+			return err
+		}
+		return fmt.Errorf("failed to update property: %w", err)
 	}
 	return nil
 }
 
-func (r *PropertyRepo) GetPropertyOwnerByID(ctx context.Context, ownerID uuid.UUID) (*models.PropertyOwner, error) {
-	var owner models.PropertyOwner
-	if err := r.DB.WithContext(ctx).First(&owner, "id = ?", ownerID).Error; err != nil {
-		return nil, fmt.Errorf("property owner not found: %w", err)
+// DeleteProperty delists a property owned by ownerID. Properties with guests
+// checked in cannot be deleted. Upcoming confirmed bookings, those checking
+// out after now, block deletion unless force is set, in which case they are
+// cancelled by the owner with a full refund; upcoming pending bookings are
+// always declined. Pending and confirmed bookings whose stay has already
+// ended are left as they are, keeping the stay's history and reviews. The
+// property is taken off every wishlist it was saved to.
+func (r *PropertyRepo) DeleteProperty(ctx context.Context, ownerID, id uuid.UUID, force bool, now time.Time) error {
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, err := lockOwnedProperty(tx, id, ownerID); err != nil {
+			return err
+		}
+
+		var bookings []models.Booking
+		err := tx.Where("property_id = ?", id).
+			Where("status = ? OR (status IN ? AND check_out > ?)", models.CheckedIn, []string{models.Pending, models.Confirmed}, now).
+			Find(&bookings).Error
+		if err != nil {
+			return err
+		}
+		for _, booking := range bookings {
+			if booking.Status == models.CheckedIn {
+				return ErrPropertyOccupied
+			}
+			if booking.Status == models.Confirmed && !force {
+				return ErrPropertyHasBookings
+			}
+		}
+
+		actor := models.NewActor(ownerID, models.HostRole)
+		for _, booking := range bookings {
+			from, to := booking.Status, models.Declined
+			updates := map[string]interface{}{"status": to}
+			if from == models.Confirmed {
+				to = models.Cancelled
+				updates = map[string]interface{}{
+					"status":              to,
+					"refund_amount":       booking.TotalPrice,
+					"cancellation_reason": "property delisted",
+					"cancelled_by_id":     ownerID,
+					"cancelled_by_role":   models.HostRole,
+					"cancelled_at":        now,
+				}
+			}
+			if err := tx.Model(&booking).Updates(updates).Error; err != nil {
+				return err
+			}
+			if err := recordTransition(tx, booking.ID, from, to, actor); err != nil {
+				return err
+			}
+		}
+
+		if err := removeFromWishlists(tx, []uuid.UUID{id}); err != nil {
+			return err
+		}
+		return tx.Delete(&models.Property{}, "id = ?", id).Error
+	})
+	if err != nil {
+		var repoErr *Error
+		if errors.As(err, &repoErr) {
+			return err
+		}
+		return fmt.Errorf("failed to delete property: %w", err)
 	}
-	return &owner, nil
+	return nil
 }
 
-func (r *PropertyRepo) GetPropertyOwnerByEmail(ctx context.Context, email string) (*models.PropertyOwner, error) {
-	var owner models.PropertyOwner
-	if err := r.DB.WithContext(ctx).Where("email = ?", email).First(&owner).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("property owner not found")
+// UnlistProperty hides a property from guests on behalf of adminID. Its owner
+// still sees it and its bookings are left as they are; cancel them one by one
+// if they should not go ahead.
+func (r *PropertyRepo) UnlistProperty(ctx context.Context, id, adminID uuid.UUID, reason string, now time.Time) error {
+	return r.setUnlisted(ctx, id, adminID, reason, &now)
+}
+
+// RelistProperty shows a property an admin unlisted to guests again.
+func (r *PropertyRepo) RelistProperty(ctx context.Context, id, adminID uuid.UUID, reason string) error {
+	return r.setUnlisted(ctx, id, adminID, reason, nil)
+}
+
+func (r *PropertyRepo) setUnlisted(ctx context.Context, id, adminID uuid.UUID, reason string, unlistedAt *time.Time) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		property, err := lockPropertyRow(tx.Where("id = ?", id))
+		if err != nil {
+			return err
+		}
+		action := models.AuditUnlistProperty
+		switch {
+		case unlistedAt != nil && property.UnlistedAt != nil:
+			return ErrAlreadyUnlisted
+		case unlistedAt == nil && property.UnlistedAt == nil:
+			return ErrNotUnlisted
+		case unlistedAt == nil:
+			action = models.AuditRelistProperty
+		}
+		if err := tx.Model(property).Update("unlisted_at", unlistedAt).Error; err != nil {
+			return err
 		}
-		return nil, err
+		return recordAudit(tx, adminID, action, models.AuditTargetProperty, id, reason)
+	})
+}
+
+func (r *PropertyRepo) GetPropertiesByOwnerID(ctx context.Context, ownerID uuid.UUID) ([]models.Property, error) {
+	var properties []models.Property
+	if err := r.DB.WithContext(ctx).Where("owner_id = ?", ownerID).Preload("Owner").Find(&properties).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch properties for owner: %w", err)
 	}
-	return &owner, nil
+	return properties, nil
 }
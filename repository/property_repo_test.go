@@ -0,0 +1,410 @@
+package repository
+
+import (
+	"airbnb/models"
+	"errors"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// createProperties adds the properties, in order, for a new owner.
+func createProperties(t *testing.T, db *gorm.DB, properties ...*models.Property) {
+	t.Helper()
+	owner := createOwner(t, db)
+	repo := NewPropertyRepo(db)
+	for _, property := range properties {
+		property.OwnerID = owner.ID
+		if property.Currency == "" {
+			property.Currency = models.DefaultCurrency
+		}
+		if property.MaxGuests == 0 {
+			property.MaxGuests = 1
+		}
+		if err := repo.CreateProperty(t.Context(), property, nil); err != nil {
+			t.Fatalf("create property %s: %v", property.Name, err)
+		}
+	}
+}
+
+func names(properties []models.Property) []string {
+	var names []string
+	for _, property := range properties {
+		names = append(names, property.Name)
+	}
+	return names
+}
+
+func TestSearchProperties(t *testing.T) {
+	db := newTestDB(t)
+	createProperties(t, db,
+		&models.Property{Name: "Lake House", Description: "by the water", Price: 30000, MaxGuests: 6, Location: "Ikeja, Lagos", RatingAverage: 4.5},
+		&models.Property{Name: "Studio", Description: "100% central", Price: 10000, MaxGuests: 2, Location: "Yaba, Lagos", RatingAverage: 4.9},
+		&models.Property{Name: "Loft", Description: "lakeside views", Price: 20000, MaxGuests: 4, Location: "Wuse, Abuja"},
+		&models.Property{Name: "Cabin", Description: "quiet", Price: 50000, MaxGuests: 8, Location: "Jos, Plateau", RatingAverage: 3},
+		&models.Property{Name: "Flat", Description: "1000 sq ft", Price: 40000, MaxGuests: 3, Location: "Lekki, Lagos", RatingAverage: 4.5},
+	)
+	repo := NewPropertyRepo(db)
+	search := func(s models.PropertySearch) []string {
+		t.Helper()
+		s.Sort = models.SortPriceAsc
+		if err := s.Normalize(); err != nil {
+			t.Fatalf("normalize: %v", err)
+		}
+		properties, _, err := repo.SearchProperties(t.Context(), s)
+		if err != nil {
+			t.Fatalf("search: %v", err)
+		}
+		return names(properties)
+	}
+	price := func(p int64) *int64 { return &p }
+
+	for _, tc := range []struct {
+		name   string
+		search models.PropertySearch
+		want   []string
+	}{
+		{"text in name or description, any case", models.PropertySearch{Query: "LAKE"}, []string{"Loft", "Lake House"}},
+		{"wildcards match literally", models.PropertySearch{Query: "100%"}, []string{"Studio"}},
+		{"location", models.PropertySearch{Location: "lagos"}, []string{"Studio", "Lake House", "Flat"}},
+		{"price range", models.PropertySearch{MinPrice: price(20000), MaxPrice: price(40000)}, []string{"Loft", "Lake House", "Flat"}},
+		{"guests", models.PropertySearch{Guests: 5}, []string{"Lake House", "Cabin"}},
+		{"filters combine", models.PropertySearch{Location: "lagos", Guests: 3}, []string{"Lake House", "Flat"}},
+	} {
+		if got := search(tc.search); !slices.Equal(got, tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestSearchPropertiesByDetails(t *testing.T) {
+	db := newTestDB(t)
+	for _, key := range []string{"wifi", "pool", "kitchen"} {
+		if err := NewAmenityRepo(db).CreateAmenity(t.Context(), &models.Amenity{Key: key, Name: key}); err != nil {
+			t.Fatalf("create amenity: %v", err)
+		}
+	}
+	owner := createOwner(t, db)
+	repo := NewPropertyRepo(db)
+	for _, p := range []struct {
+		property  models.Property
+		amenities []string
+	}{
+		{models.Property{Name: "villa", Price: 20000, MaxGuests: 6, PropertyType: models.EntireHome, Bedrooms: 3, Beds: 4, Bathrooms: 2.5, HouseRules: models.HouseRules{PetsAllowed: true}}, []string{"wifi", "pool"}},
+		{models.Property{Name: "room", Price: 4000, MaxGuests: 1, PropertyType: models.PrivateRoom, Beds: 1, Bathrooms: 1}, []string{"wifi"}},
+		{models.Property{Name: "tent", Price: 1000, MaxGuests: 2, PropertyType: models.SharedRoom, Beds: 2, Bathrooms: 0.5}, nil},
+	} {
+		property := p.property
+		property.OwnerID, property.Currency, property.Location = owner.ID, models.DefaultCurrency, "Lagos"
+		if err := repo.CreateProperty(t.Context(), &property, p.amenities); err != nil {
+			t.Fatalf("create property %s: %v", property.Name, err)
+		}
+	}
+
+	for _, tc := range []struct {
+		search models.PropertySearch
+		want   []string
+	}{
+		{models.PropertySearch{PropertyType: models.PrivateRoom}, []string{"room"}},
+		{models.PropertySearch{MinBedrooms: 2, MinBeds: 4}, []string{"villa"}},
+		{models.PropertySearch{MinBeds: 2}, []string{"tent", "villa"}},
+		{models.PropertySearch{MinBathrooms: 1}, []string{"room", "villa"}},
+		{models.PropertySearch{Amenities: "wifi"}, []string{"room", "villa"}},
+		{models.PropertySearch{Amenities: "pool, wifi"}, []string{"villa"}},
+		{models.PropertySearch{Amenities: "kitchen"}, nil},
+		{models.PropertySearch{PetsAllowed: true}, []string{"villa"}},
+		{models.PropertySearch{PetsAllowed: true, Guests: 7}, nil},
+		{models.PropertySearch{SmokingAllowed: true}, nil},
+	} {
+		search := tc.search
+		search.Sort = models.SortPriceAsc
+		if err := search.Normalize(); err != nil {
+			t.Fatalf("normalize %+v: %v", tc.search, err)
+		}
+		properties, _, err := repo.SearchProperties(t.Context(), search)
+		if err != nil {
+			t.Fatalf("search %+v: %v", tc.search, err)
+		}
+		got := names(properties)
+		slices.Sort(got)
+		if !slices.Equal(got, tc.want) {
+			t.Errorf("search %+v = %v, want %v", tc.search, got, tc.want)
+		}
+	}
+}
+
+func TestSearchPropertiesPaging(t *testing.T) {
+	db := newTestDB(t)
+	// Three properties share a price and two a rating, so ties fall across
+	// page boundaries and are broken by ID.
+	createProperties(t, db,
+		&models.Property{Name: "a", Price: 30000, Location: "x", RatingAverage: 4.5},
+		&models.Property{Name: "b", Price: 10000, Location: "x", RatingAverage: 4.9},
+		&models.Property{Name: "c", Price: 30000, Location: "x"},
+		&models.Property{Name: "d", Price: 30000, Location: "x", RatingAverage: 3},
+		&models.Property{Name: "e", Price: 40000, Location: "x", RatingAverage: 4.5},
+	)
+	repo := NewPropertyRepo(db)
+	// walk fetches every page of two and returns the properties in the order
+	// they came, with each page's size.
+	walk := func(sort string) ([]models.Property, []int) {
+		t.Helper()
+		var all []models.Property
+		var sizes []int
+		search := models.PropertySearch{Sort: sort, Limit: 2}
+		if err := search.Normalize(); err != nil {
+			t.Fatalf("normalize: %v", err)
+		}
+		for {
+			properties, next, err := repo.SearchProperties(t.Context(), search)
+			if err != nil {
+				t.Fatalf("search %s: %v", sort, err)
+			}
+			all, sizes = append(all, properties...), append(sizes, len(properties))
+			if next == "" || len(sizes) > 5 {
+				return all, sizes
+			}
+			search.Cursor = next
+		}
+	}
+
+	for sort, key := range map[string]func(models.Property) float64{
+		models.SortNewest:    func(p models.Property) float64 { return -float64(p.CreatedAt.UnixNano()) },
+		models.SortPriceAsc:  func(p models.Property) float64 { return float64(p.Price) },
+		models.SortPriceDesc: func(p models.Property) float64 { return -float64(p.Price) },
+		models.SortRating:    func(p models.Property) float64 { return -p.RatingAverage },
+	} {
+		all, sizes := walk(sort)
+		seen := names(all)
+		if !slices.Equal(sizes, []int{2, 2, 1}) || len(slices.Compact(slices.Sorted(slices.Values(seen)))) != 5 {
+			t.Fatalf("%s: pages of %v with %v", sort, sizes, seen)
+		}
+		if !slices.IsSortedFunc(all, func(a, b models.Property) int {
+			if key(a) != key(b) {
+				return cmpFloat(key(a), key(b))
+			}
+			return cmpUUID(a, b, sort)
+		}) {
+			t.Fatalf("%s: out of order: %v", sort, seen)
+		}
+	}
+
+	if _, _, err := repo.SearchProperties(t.Context(), models.PropertySearch{Sort: models.SortPriceAsc, Limit: 2, Cursor: "bogus"}); err != ErrInvalidCursor {
+		t.Fatalf("bad cursor: %v", err)
+	}
+}
+
+func cmpFloat(a, b float64) int {
+	if a < b {
+		return -1
+	}
+	return 1
+}
+
+// cmpUUID orders properties that tie on the sort column by ID, descending for
+// the descending sorts.
+func cmpUUID(a, b models.Property, sort string) int {
+	c := strings.Compare(a.ID.String(), b.ID.String())
+	if sort != models.SortPriceAsc {
+		c = -c
+	}
+	return c
+}
+
+func TestNearbyProperties(t *testing.T) {
+	db := newTestDB(t)
+	at := func(lat, lng float64) (*float64, *float64) { return &lat, &lng }
+	ikejaLat, ikejaLng := at(6.6018, 3.3515)
+	lekkiLat, lekkiLng := at(6.4698, 3.5852)
+	ibadanLat, ibadanLng := at(7.3775, 3.9470)
+	abujaLat, abujaLng := at(9.0765, 7.3986)
+	hiddenLat, hiddenLng := at(6.4550, 3.3950)
+	unlistedAt := time.Now()
+	createProperties(t, db,
+		&models.Property{Name: "Lekki", Price: 1, Location: "x", Latitude: lekkiLat, Longitude: lekkiLng},
+		&models.Property{Name: "Ibadan", Price: 1, Location: "x", Latitude: ibadanLat, Longitude: ibadanLng},
+		&models.Property{Name: "Ikeja", Price: 1, Location: "x", Latitude: ikejaLat, Longitude: ikejaLng},
+		&models.Property{Name: "Abuja", Price: 1, Location: "x", Latitude: abujaLat, Longitude: abujaLng},
+		&models.Property{Name: "No coordinates", Price: 1, Location: "x"},
+		&models.Property{Name: "Unlisted", Price: 1, Location: "x", Latitude: hiddenLat, Longitude: hiddenLng, UnlistedAt: &unlistedAt},
+	)
+	repo := NewPropertyRepo(db)
+	// Lagos Island.
+	lat, lng := 6.4541, 3.3947
+	nearby := func(radiusKm float64) ([]string, []float64) {
+		t.Helper()
+		search := models.NearbySearch{Latitude: &lat, Longitude: &lng, RadiusKm: radiusKm}
+		if err := search.Normalize(); err != nil {
+			t.Fatalf("normalize: %v", err)
+		}
+		results, err := repo.NearbyProperties(t.Context(), search)
+		if err != nil {
+			t.Fatalf("nearby: %v", err)
+		}
+		var names []string
+		var distances []float64
+		for _, result := range results {
+			names, distances = append(names, result.Property.Name), append(distances, result.DistanceKm)
+		}
+		return names, distances
+	}
+
+	// About 17km, 21km, 119km and 530km away.
+	km := map[string]float64{
+		"Ikeja":  haversineKm(lat, lng, *ikejaLat, *ikejaLng),
+		"Lekki":  haversineKm(lat, lng, *lekkiLat, *lekkiLng),
+		"Ibadan": haversineKm(lat, lng, *ibadanLat, *ibadanLng),
+	}
+	for _, tc := range []struct {
+		radiusKm float64
+		want     []string
+	}{
+		{radiusKm: 10, want: nil},
+		{radiusKm: 50, want: []string{"Ikeja", "Lekki"}},
+		{radiusKm: 150, want: []string{"Ikeja", "Lekki", "Ibadan"}},
+		{radiusKm: 500, want: []string{"Ikeja", "Lekki", "Ibadan"}},
+	} {
+		got, distances := nearby(tc.radiusKm)
+		if !slices.Equal(got, tc.want) {
+			t.Fatalf("within %vkm: got %v, want %v", tc.radiusKm, got, tc.want)
+		}
+		for i, name := range got {
+			if math.Abs(distances[i]-km[name]) > 0.01 {
+				t.Fatalf("within %vkm: %s is %vkm away, want %vkm", tc.radiusKm, name, distances[i], km[name])
+			}
+		}
+	}
+}
+
+func haversineKm(lat1, lng1, lat2, lng2 float64) float64 {
+	rad := math.Pi / 180
+	a := math.Pow(math.Sin((lat2-lat1)*rad/2), 2) + math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Pow(math.Sin((lng2-lng1)*rad/2), 2)
+	return 6371 * 2 * math.Asin(math.Sqrt(a))
+}
+
+func TestContainsText(t *testing.T) {
+	// Postgres keeps ILIKE so the trigram indexes on the raw columns apply.
+	pg, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=unused"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatalf("open postgres: %v", err)
+	}
+	if got := containsText(pg, "properties.name"); !strings.HasPrefix(got, "properties.name ILIKE ?") {
+		t.Fatalf("postgres condition = %s", got)
+	}
+	if got := containsText(newTestDB(t), "properties.name"); !strings.HasPrefix(got, "LOWER(properties.name) LIKE LOWER(?)") {
+		t.Fatalf("sqlite condition = %s", got)
+	}
+}
+
+func TestDeleteProperty(t *testing.T) {
+	now := time.Now()
+	today := models.Today(now)
+	setup := func(t *testing.T, statuses map[string]time.Time) (*gorm.DB, *models.Property, map[string]*models.Booking) {
+		t.Helper()
+		db := newTestDB(t)
+		property := &models.Property{Name: "cabin", Price: 10000, Location: "lake"}
+		createProperties(t, db, property)
+		guest := createGuest(t, db)
+		bookings := map[string]*models.Booking{}
+		for status, checkIn := range statuses {
+			booking := &models.Booking{UserID: guest.ID, PropertyID: property.ID, CheckIn: checkIn, CheckOut: checkIn.AddDate(0, 0, 3), Guests: 1, TotalPrice: 30000, Currency: models.DefaultCurrency, Status: status}
+			if err := db.Create(booking).Error; err != nil {
+				t.Fatalf("create booking: %v", err)
+			}
+			bookings[status] = booking
+		}
+		return db, property, bookings
+	}
+	status := func(t *testing.T, db *gorm.DB, booking *models.Booking) *models.Booking {
+		t.Helper()
+		var got models.Booking
+		if err := db.First(&got, "id = ?", booking.ID).Error; err != nil {
+			t.Fatalf("get booking: %v", err)
+		}
+		return &got
+	}
+	deleted := func(t *testing.T, db *gorm.DB, property *models.Property) bool {
+		t.Helper()
+		var count int64
+		if err := db.Model(&models.Property{}).Where("id = ?", property.ID).Count(&count).Error; err != nil {
+			t.Fatalf("count properties: %v", err)
+		}
+		return count == 0
+	}
+
+	t.Run("past stays", func(t *testing.T) {
+		// Stays that ended but were never moved on are history, not bookings
+		// to cancel.
+		db, property, bookings := setup(t, map[string]time.Time{
+			models.Confirmed: today.AddDate(0, 0, -10),
+			models.Pending:   today.AddDate(0, 0, -3),
+		})
+		if err := NewPropertyRepo(db).DeleteProperty(t.Context(), property.OwnerID, property.ID, false, now); err != nil {
+			t.Fatalf("delete: %v", err)
+		}
+		for want, booking := range bookings {
+			if got := status(t, db, booking); got.Status != want || got.RefundAmount != 0 {
+				t.Fatalf("%s booking = %s, refund %d", want, got.Status, got.RefundAmount)
+			}
+		}
+	})
+
+	t.Run("upcoming", func(t *testing.T) {
+		db, property, bookings := setup(t, map[string]time.Time{
+			models.Confirmed: today.AddDate(0, 0, 10),
+			models.Pending:   today.AddDate(0, 0, 20),
+		})
+		repo := NewPropertyRepo(db)
+		if err := repo.DeleteProperty(t.Context(), property.OwnerID, property.ID, false, now); !errors.Is(err, ErrPropertyHasBookings) {
+			t.Fatalf("delete without force: err = %v, want %v", err, ErrPropertyHasBookings)
+		}
+		if deleted(t, db, property) || status(t, db, bookings[models.Pending]).Status != models.Pending {
+			t.Fatal("refused delete changed the property or its bookings")
+		}
+		if err := repo.DeleteProperty(t.Context(), property.OwnerID, property.ID, true, now); err != nil {
+			t.Fatalf("delete with force: %v", err)
+		}
+		if got := status(t, db, bookings[models.Confirmed]); got.Status != models.Cancelled || got.RefundAmount != 30000 || got.CancelledByRole != models.HostRole {
+			t.Fatalf("confirmed booking = %+v", got)
+		}
+		if got := status(t, db, bookings[models.Pending]); got.Status != models.Declined {
+			t.Fatalf("pending booking = %s, want %s", got.Status, models.Declined)
+		}
+		if !deleted(t, db, property) {
+			t.Fatal("property not deleted")
+		}
+	})
+
+	t.Run("checked in", func(t *testing.T) {
+		db, property, _ := setup(t, map[string]time.Time{models.CheckedIn: today.AddDate(0, 0, -1)})
+		if err := NewPropertyRepo(db).DeleteProperty(t.Context(), property.OwnerID, property.ID, true, now); !errors.Is(err, ErrPropertyOccupied) {
+			t.Fatalf("delete: err = %v, want %v", err, ErrPropertyOccupied)
+		}
+		if deleted(t, db, property) {
+			t.Fatal("occupied property deleted")
+		}
+	})
+
+	t.Run("wishlists", func(t *testing.T) {
+		db, property, _ := setup(t, nil)
+		wishlist := models.Wishlist{GuestID: createGuest(t, db).ID, Name: "lake trip"}
+		if err := db.Create(&wishlist).Error; err != nil {
+			t.Fatalf("create wishlist: %v", err)
+		}
+		if err := db.Create(&models.WishlistItem{WishlistID: wishlist.ID, PropertyID: property.ID}).Error; err != nil {
+			t.Fatalf("save property: %v", err)
+		}
+		if err := NewPropertyRepo(db).DeleteProperty(t.Context(), property.OwnerID, property.ID, false, now); err != nil {
+			t.Fatalf("delete: %v", err)
+		}
+		var items int64
+		if err := db.Model(&models.WishlistItem{}).Where("property_id = ?", property.ID).Count(&items).Error; err != nil || items != 0 {
+			t.Fatalf("wishlist items left = %d, %v", items, err)
+		}
+	})
+}
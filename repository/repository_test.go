@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"airbnb/models"
+	"fmt"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestDB opens an in-memory SQLite database with the schema created from
+// the models.
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", uuid.NewString())), &gorm.Config{
+		Logger:         logger.Discard,
+		TranslateError: true,
+	})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	err = db.AutoMigrate(&models.Account{}, &models.Role{}, &models.Property{}, &models.Booking{}, &models.BlockedDate{}, &models.BookingTransition{}, &models.Review{}, &models.Conversation{}, &models.Message{}, &models.Wishlist{}, &models.WishlistItem{}, &models.Photo{}, &models.Amenity{}, &models.PriceRule{})
+	if err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+// createOwner adds a host account.
+func createOwner(t *testing.T, db *gorm.DB) *models.Account {
+	t.Helper()
+	return createAccount(t, db, "owner", models.HostRole)
+}
+
+// createGuest adds a guest account.
+func createGuest(t *testing.T, db *gorm.DB) *models.Account {
+	t.Helper()
+	return createAccount(t, db, "guest", models.GuestRole)
+}
+
+func createAccount(t *testing.T, db *gorm.DB, name, role string) *models.Account {
+	t.Helper()
+	account := models.Account{Name: name, Email: uuid.NewString() + "@example.com", Password: "x", Roles: []models.Role{{Name: role}}}
+	if err := NewAccountRepo(db).CreateAccount(t.Context(), &account); err != nil {
+		t.Fatalf("create %s: %v", name, err)
+	}
+	return &account
+}
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"airbnb/models"
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrReviewNotFound   = NewError(ErrNotFound, "review_not_found", "review not found")
+	ErrReviewNotAllowed = NewError(ErrConflict, "review_not_allowed", "reviews can be left once a confirmed stay has checked out")
+	ErrAlreadyReviewed  = NewError(ErrConflict, "already_reviewed", "this booking has already been reviewed")
+	ErrAlreadyReplied   = NewError(ErrConflict, "already_replied", "this review already has a reply")
+)
+
+const reviewCursorKind = "reviews"
+
+// reviewableStatuses are the statuses of bookings that were confirmed and so
+// may be reviewed once their check-out date has passed.
+var reviewableStatuses = []string{models.Confirmed, models.CheckedIn, models.Completed}
+
+type ReviewRepo struct {
+	DB *gorm.DB
+}
+
+func NewReviewRepo(db *gorm.DB) *ReviewRepo {
+	return &ReviewRepo{DB: db}
+}
+
+// CreateReview stores a review of the stay booked as bookingID by the actor,
+// a guest reviewing the property or the owning host reviewing the guest. It
+// returns ErrBookingNotFound unless the booking is the actor's, and
+// ErrReviewNotAllowed until a confirmed stay has checked out. A guest's rating
+// is folded into the property's running average in the same transaction.
+func (r *ReviewRepo) CreateReview(ctx context.Context, bookingID uuid.UUID, actor models.Actor, review *models.Review, now time.Time) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var booking models.Booking
+		err := scopeBookingsToActor(tx, actor).Preload("Property").First(&booking, "id = ?", bookingID).Error
+		if err != nil {
+			return translate(err, ErrBookingNotFound)
+		}
+		if !slices.Contains(reviewableStatuses, booking.Status) || booking.CheckOut.After(now) {
+			return ErrReviewNotAllowed
+		}
+
+		review.BookingID = booking.ID
+		review.PropertyID = booking.PropertyID
+		review.AuthorID = *actor.ID
+		review.AuthorRole = actor.Role
+		review.SubjectID = booking.UserID
+		if actor.Role == models.GuestRole {
+			review.SubjectID = booking.Property.OwnerID
+		}
+		var count int64
+		err = tx.Model(&models.Review{}).
+			Where("booking_id = ? AND author_role = ?", booking.ID, actor.Role).
+			Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrAlreadyReviewed
+		}
+		if err := tx.Omit(clause.Associations).Create(review).Error; err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return ErrAlreadyReviewed
+			}
+			return err
+		}
+		if actor.Role != models.GuestRole {
+			return nil
+		}
+
+		// Unlisted properties keep collecting ratings from past stays.
+		property, err := lockPropertyRow(tx.Where("id = ?", booking.PropertyID))
+		if err != nil {
+			return err
+		}
+		average, ratings := models.AddRating(property.RatingAverage, property.RatingCount, review.Rating)
+		return tx.Model(property).Updates(map[string]any{
+			"rating_average": average,
+			"rating_count":   ratings,
+		}).Error
+	})
+}
+
+// ReplyToReview stores the host's reply to a guest's review of one of
+// ownerID's properties. Other reviews are reported as not found.
+func (r *ReviewRepo) ReplyToReview(ctx context.Context, ownerID, reviewID uuid.UUID, reply string, now time.Time) (*models.Review, error) {
+	var review models.Review
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// The reply is returned with the review's author, who may have since
+		// deleted their account.
+		err := tx.Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate}).
+			Preload("Author", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+			Where("author_role = ? AND subject_id = ?", models.GuestRole, ownerID).
+			First(&review, "id = ?", reviewID).Error
+		if err != nil {
+			return translate(err, ErrReviewNotFound)
+		}
+		if review.RepliedAt != nil {
+			return ErrAlreadyReplied
+		}
+		review.Reply = reply
+		review.RepliedAt = &now
+		return tx.Model(&review).Select("reply", "replied_at").Updates(&review).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// GetPropertyReviews returns one page of guests' reviews of a property, newest
+// first, and the cursor for the next page ("" on the last page).
+func (r *ReviewRepo) GetPropertyReviews(ctx context.Context, propertyID uuid.UUID, search models.ReviewSearch) ([]models.Review, string, error) {
+	var count int64
+	if err := listed(r.DB.WithContext(ctx)).Model(&models.Property{}).Where("id = ?", propertyID).Count(&count).Error; err != nil {
+		return nil, "", err
+	}
+	if count == 0 {
+		return nil, "", ErrPropertyNotFound
+	}
+	return r.listReviews(ctx, search, "property_id = ? AND author_role = ?", propertyID, models.GuestRole)
+}
+
+// GetGuestReviews returns one page of hosts' reviews of a guest, newest first.
+func (r *ReviewRepo) GetGuestReviews(ctx context.Context, guestID uuid.UUID, search models.ReviewSearch) ([]models.Review, string, error) {
+	return r.listReviews(ctx, search, "subject_id = ? AND author_role = ?", guestID, models.HostRole)
+}
+
+func (r *ReviewRepo) listReviews(ctx context.Context, search models.ReviewSearch, query string, args ...any) ([]models.Review, string, error) {
+	// Reviews by accounts that have since been deleted are kept, under the
+	// erased name.
+	withAuthor := r.DB.WithContext(ctx).
+		Preload("Author", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Where(query, args...)
+	paged, err := newestFirst(withAuthor, "reviews", reviewCursorKind, search.Cursor, search.Limit)
+	if err != nil {
+		return nil, "", err
+	}
+	var reviews []models.Review
+	if err := paged.Find(&reviews).Error; err != nil {
+		return nil, "", err
+	}
+	return nextPage(reviews, reviewCursorKind, search.Limit, func(r models.Review) (time.Time, uuid.UUID) {
+		return r.CreatedAt, r.ID
+	})
+}
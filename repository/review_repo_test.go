@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"airbnb/models"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCreateReview(t *testing.T) {
+	db := newTestDB(t)
+	owner, guest, other := createOwner(t, db), createGuest(t, db), createGuest(t, db)
+	property := models.Property{Name: "cabin", Price: 10000, Currency: models.DefaultCurrency, Location: "lake", OwnerID: owner.ID}
+	if err := NewPropertyRepo(db).CreateProperty(t.Context(), &property, nil); err != nil {
+		t.Fatalf("create property: %v", err)
+	}
+
+	today := models.Today(time.Now())
+	now := today.Add(12 * time.Hour)
+	stay := func(status string, checkOut time.Time) *models.Booking {
+		t.Helper()
+		booking := &models.Booking{UserID: guest.ID, PropertyID: property.ID, CheckIn: checkOut.AddDate(0, 0, -2), CheckOut: checkOut, Guests: 1, Currency: models.DefaultCurrency, Status: status}
+		if err := db.Create(booking).Error; err != nil {
+			t.Fatalf("create booking: %v", err)
+		}
+		return booking
+	}
+	asGuest := models.NewActor(guest.ID, models.GuestRole)
+	asHost := models.NewActor(owner.ID, models.HostRole)
+	repo := NewReviewRepo(db)
+	review := func(booking *models.Booking, actor models.Actor, rating int) error {
+		return repo.CreateReview(t.Context(), booking.ID, actor, &models.Review{Rating: rating, Comment: "stay"}, now)
+	}
+
+	for _, tc := range []struct {
+		name    string
+		booking *models.Booking
+		actor   models.Actor
+		want    error
+	}{
+		{"pending", stay(models.Pending, today.AddDate(0, 0, -1)), asGuest, ErrReviewNotAllowed},
+		{"cancelled", stay(models.Cancelled, today.AddDate(0, 0, -1)), asGuest, ErrReviewNotAllowed},
+		{"before check-out", stay(models.Confirmed, today.AddDate(0, 0, 1)), asGuest, ErrReviewNotAllowed},
+		{"someone else's stay", stay(models.Completed, today), models.NewActor(other.ID, models.GuestRole), ErrBookingNotFound},
+	} {
+		if err := review(tc.booking, tc.actor, 5); !errors.Is(err, tc.want) {
+			t.Errorf("%s: err = %v, want %v", tc.name, err, tc.want)
+		}
+	}
+
+	// Only guests' ratings count towards the property's average.
+	first, second := stay(models.Confirmed, today), stay(models.Completed, today.AddDate(0, 0, -7))
+	for _, tc := range []struct {
+		booking *models.Booking
+		actor   models.Actor
+		rating  int
+	}{
+		{first, asGuest, 5},
+		{second, asGuest, 2},
+		{first, asHost, 1},
+	} {
+		if err := review(tc.booking, tc.actor, tc.rating); err != nil {
+			t.Fatalf("review: %v", err)
+		}
+	}
+	if err := review(first, asGuest, 4); !errors.Is(err, ErrAlreadyReviewed) {
+		t.Fatalf("second review: err = %v, want %v", err, ErrAlreadyReviewed)
+	}
+	got, err := NewPropertyRepo(db).GetPropertyByID(t.Context(), property.ID)
+	if err != nil {
+		t.Fatalf("get property: %v", err)
+	}
+	if got.RatingAverage != 3.5 || got.RatingCount != 2 {
+		t.Fatalf("rating = %v over %d, want 3.5 over 2", got.RatingAverage, got.RatingCount)
+	}
+}
@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"airbnb/models"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrRefreshTokenInvalid = NewError(ErrUnauthorized, "invalid_refresh_token", "refresh token is invalid or expired")
+	ErrRefreshTokenReused  = NewError(ErrUnauthorized, "refresh_token_reused", "refresh token was already used; sign in again")
+)
+
+type TokenRepo struct {
+	DB *gorm.DB
+}
+
+func NewTokenRepo(db *gorm.DB) *TokenRepo {
+	return &TokenRepo{DB: db}
+}
+
+func (r *TokenRepo) CreateRefreshToken(ctx context.Context, token *models.RefreshToken) error {
+	return r.DB.WithContext(ctx).Create(token).Error
+}
+
+// RotateRefreshToken exchanges the unexpired refresh token with the given hash
+// for the one issue returns for its account, in the same family. If the token
+// was already revoked the whole family is revoked and ErrRefreshTokenReused is
+// returned along with the access tokens that were denylisted.
+func (r *TokenRepo) RotateRefreshToken(ctx context.Context, hash string, now time.Time, issue func(account *models.Account) (*models.RefreshToken, error)) ([]models.RevokedToken, error) {
+	var revoked []models.RevokedToken
+	reused := false
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var current models.RefreshToken
+		err := tx.Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate}).
+			Where("token_hash = ?", hash).
+			First(&current).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrRefreshTokenInvalid
+			}
+			return err
+		}
+		if current.RevokedAt != nil {
+			reused = true
+			revoked, err = revokeTokens(tx, "family_id = ?", current.FamilyID, now)
+			return err
+		}
+		if !current.ExpiresAt.After(now) {
+			return ErrRefreshTokenInvalid
+		}
+
+		var account models.Account
+		if err := tx.Preload("Roles").First(&account, "id = ?", current.AccountID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrRefreshTokenInvalid
+			}
+			return err
+		}
+		if account.IsSuspended() {
+			return ErrAccountSuspended
+		}
+		next, err := issue(&account)
+		if err != nil {
+			return err
+		}
+		next.AccountID = current.AccountID
+		next.FamilyID = current.FamilyID
+		if err := tx.Create(next).Error; err != nil {
+			return err
+		}
+		return tx.Model(&current).Updates(map[string]any{
+			"revoked_at":     now,
+			"replaced_by_id": next.ID,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	if reused {
+		return revoked, ErrRefreshTokenReused
+	}
+	return nil, nil
+}
+
+// RevokeRefreshToken revokes the family of accountID's refresh token with the
+// given hash and denylists the family's unexpired access tokens.
+func (r *TokenRepo) RevokeRefreshToken(ctx context.Context, accountID uuid.UUID, hash string, now time.Time) ([]models.RevokedToken, error) {
+	var revoked []models.RevokedToken
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var token models.RefreshToken
+		err := tx.Where("token_hash = ? AND account_id = ?", hash, accountID).First(&token).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrRefreshTokenInvalid
+			}
+			return err
+		}
+		revoked, err = revokeTokens(tx, "family_id = ?", token.FamilyID, now)
+		return err
+	})
+	return revoked, err
+}
+
+// RevokeAccountTokens revokes every refresh token of the account and
+// denylists the access tokens issued with them.
+func (r *TokenRepo) RevokeAccountTokens(ctx context.Context, accountID uuid.UUID, now time.Time) ([]models.RevokedToken, error) {
+	var revoked []models.RevokedToken
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		revoked, err = revokeTokens(tx, "account_id = ?", accountID, now)
+		return err
+	})
+	return revoked, err
+}
+
+// revokeTokens revokes the refresh tokens matching the condition and
+// denylists their access tokens that have not expired yet.
+func revokeTokens(tx *gorm.DB, query string, arg any, now time.Time) ([]models.RevokedToken, error) {
+	var tokens []models.RefreshToken
+	if err := tx.Where(query, arg).Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+	var ids []uuid.UUID
+	var revoked []models.RevokedToken
+	for _, token := range tokens {
+		ids = append(ids, token.ID)
+		if token.AccessExpiresAt.After(now) {
+			revoked = append(revoked, models.RevokedToken{JTI: token.AccessJTI, ExpiresAt: token.AccessExpiresAt})
+		}
+	}
+	if len(ids) > 0 {
+		err := tx.Model(&models.RefreshToken{}).
+			Where("id IN ? AND revoked_at IS NULL", ids).
+			Update("revoked_at", now).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := denyAccessTokens(tx, revoked); err != nil {
+		return nil, err
+	}
+	return revoked, nil
+}
+
+// DenyAccessToken denylists a single access token until it expires.
+func (r *TokenRepo) DenyAccessToken(ctx context.Context, token models.RevokedToken) error {
+	return denyAccessTokens(r.DB.WithContext(ctx), []models.RevokedToken{token})
+}
+
+func denyAccessTokens(tx *gorm.DB, tokens []models.RevokedToken) error {
+	if len(tokens) == 0 {
+		return nil
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&tokens).Error
+}
+
+// GetRevokedTokens returns the denylisted access tokens that have not expired.
+func (r *TokenRepo) GetRevokedTokens(ctx context.Context, now time.Time) ([]models.RevokedToken, error) {
+	var tokens []models.RevokedToken
+	err := r.DB.WithContext(ctx).Where("expires_at > ?", now).Find(&tokens).Error
+	return tokens, err
+}
+
+// PruneExpiredTokens deletes denylist entries, refresh tokens and emailed
+// account tokens that have expired, since none of them can be used any more.
+func (r *TokenRepo) PruneExpiredTokens(ctx context.Context, now time.Time) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("expires_at <= ?", now).Delete(&models.RevokedToken{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("expires_at <= ?", now).Delete(&models.RefreshToken{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("expires_at <= ?", now).Delete(&models.AccountToken{}).Error
+	})
+}
@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"airbnb/models"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrWishlistNotFound = NewError(ErrNotFound, "wishlist_not_found", "wishlist not found")
+	ErrWishlistFull     = NewError(ErrConflict, "wishlist_full", "a wishlist can hold up to 100 properties")
+)
+
+type WishlistRepo struct {
+	DB *gorm.DB
+}
+
+func NewWishlistRepo(db *gorm.DB) *WishlistRepo {
+	return &WishlistRepo{DB: db}
+}
+
+func (r *WishlistRepo) CreateWishlist(ctx context.Context, wishlist *models.Wishlist) error {
+	return r.DB.WithContext(ctx).Omit(clause.Associations).Create(wishlist).Error
+}
+
+// ListWishlists returns guestID's wishlists, newest first, with how many
+// listed properties each holds.
+func (r *WishlistRepo) ListWishlists(ctx context.Context, guestID uuid.UUID) ([]models.WishlistSummary, error) {
+	summaries := []models.WishlistSummary{}
+	err := r.DB.WithContext(ctx).
+		Table("wishlists").
+		Select("wishlists.id AS wishlist_id, wishlists.name, wishlists.share_token_hash IS NOT NULL AS shared, COUNT(properties.id) AS property_count, wishlists.created_at").
+		Joins("LEFT JOIN wishlist_items ON wishlist_items.wishlist_id = wishlists.id").
+		Joins("LEFT JOIN properties ON properties.id = wishlist_items.property_id AND properties.deleted_at IS NULL AND properties.unlisted_at IS NULL").
+		Where("wishlists.guest_id = ? AND wishlists.deleted_at IS NULL", guestID).
+		Group("wishlists.id, wishlists.name, wishlists.share_token_hash, wishlists.created_at").
+		Order("wishlists.created_at DESC, wishlists.id DESC").
+		Scan(&summaries).Error
+	return summaries, err
+}
+
+// GetWishlist returns one of guestID's wishlists with its listed properties.
+func (r *WishlistRepo) GetWishlist(ctx context.Context, guestID, id uuid.UUID) (*models.Wishlist, error) {
+	return r.getWishlist(ctx, r.DB.WithContext(ctx).Where("id = ? AND guest_id = ?", id, guestID))
+}
+
+// GetSharedWishlist returns the wishlist whose share link carries the token
+// hashed as tokenHash, with its listed properties.
+func (r *WishlistRepo) GetSharedWishlist(ctx context.Context, tokenHash string) (*models.Wishlist, error) {
+	return r.getWishlist(ctx, r.DB.WithContext(ctx).Where("share_token_hash = ?", tokenHash))
+}
+
+func (r *WishlistRepo) getWishlist(ctx context.Context, query *gorm.DB) (*models.Wishlist, error) {
+	var wishlist models.Wishlist
+	if err := query.First(&wishlist).Error; err != nil {
+		return nil, translate(err, ErrWishlistNotFound)
+	}
+	err := listed(r.DB.WithContext(ctx).
+		Joins("JOIN properties ON properties.id = wishlist_items.property_id AND properties.deleted_at IS NULL")).
+		Preload("Property.Owner").
+		Preload("Property.Photos", inPhotoOrder).
+		Preload("Property.Amenities").
+		Preload("Property.PriceRules", inRuleOrder).
+		Where("wishlist_items.wishlist_id = ?", wishlist.ID).
+		Order("wishlist_items.created_at DESC, wishlist_items.property_id").
+		Find(&wishlist.Items).Error
+	if err != nil {
+		return nil, err
+	}
+	return &wishlist, nil
+}
+
+// DeleteWishlist deletes one of guestID's wishlists and its share link.
+func (r *WishlistRepo) DeleteWishlist(ctx context.Context, guestID, id uuid.UUID) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, err := lockWishlist(tx, guestID, id); err != nil {
+			return err
+		}
+		return deleteWishlists(tx, "id = ?", id)
+	})
+}
+
+// SaveProperty adds a listed property to one of guestID's wishlists. Saving a
+// property that is already on the list does nothing.
+func (r *WishlistRepo) SaveProperty(ctx context.Context, guestID, wishlistID, propertyID uuid.UUID) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, err := lockWishlist(tx, guestID, wishlistID); err != nil {
+			return err
+		}
+		var count int64
+		if err := listed(tx.Model(&models.Property{})).Where("id = ?", propertyID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return ErrPropertyNotFound
+		}
+		if err := tx.Model(&models.WishlistItem{}).Where("wishlist_id = ?", wishlistID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count >= models.MaxWishlistProperties {
+			return ErrWishlistFull
+		}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Omit(clause.Associations).
+			Create(&models.WishlistItem{WishlistID: wishlistID, PropertyID: propertyID}).Error
+	})
+}
+
+// RemoveProperty takes a property off one of guestID's wishlists. Removing one
+// that is not on the list does nothing.
+func (r *WishlistRepo) RemoveProperty(ctx context.Context, guestID, wishlistID, propertyID uuid.UUID) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, err := lockWishlist(tx, guestID, wishlistID); err != nil {
+			return err
+		}
+		return tx.Where("wishlist_id = ? AND property_id = ?", wishlistID, propertyID).Delete(&models.WishlistItem{}).Error
+	})
+}
+
+// ShareWishlist sets the hash of the token in a wishlist's share link,
+// replacing any earlier link, or stops sharing it when tokenHash is nil.
+func (r *WishlistRepo) ShareWishlist(ctx context.Context, guestID, id uuid.UUID, tokenHash *string) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		wishlist, err := lockWishlist(tx, guestID, id)
+		if err != nil {
+			return err
+		}
+		return tx.Model(wishlist).Update("share_token_hash", tokenHash).Error
+	})
+}
+
+// UnavailableProperties reports which of propertyIDs have an active booking or
+// blocked dates overlapping the nights from (inclusive) to (exclusive).
+func (r *WishlistRepo) UnavailableProperties(ctx context.Context, propertyIDs []uuid.UUID, from, to time.Time) (map[uuid.UUID]bool, error) {
+	unavailable := map[uuid.UUID]bool{}
+	if len(propertyIDs) == 0 {
+		return unavailable, nil
+	}
+	var booked, blocked []uuid.UUID
+	err := r.DB.WithContext(ctx).Model(&models.Booking{}).
+		Where("property_id IN ? AND status IN ?", propertyIDs, activeStatuses).
+		Where("check_in < ? AND check_out > ?", to, from).
+		Distinct().Pluck("property_id", &booked).Error
+	if err != nil {
+		return nil, err
+	}
+	err = r.DB.WithContext(ctx).Model(&models.BlockedDate{}).
+		Where("property_id IN ?", propertyIDs).
+		Where("start_date < ? AND end_date > ?", to, from).
+		Distinct().Pluck("property_id", &blocked).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range append(booked, blocked...) {
+		unavailable[id] = true
+	}
+	return unavailable, nil
+}
+
+func lockWishlist(tx *gorm.DB, guestID, id uuid.UUID) (*models.Wishlist, error) {
+	var wishlist models.Wishlist
+	err := tx.Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate}).
+		Where("id = ? AND guest_id = ?", id, guestID).
+		First(&wishlist).Error
+	if err != nil {
+		return nil, translate(err, ErrWishlistNotFound)
+	}
+	return &wishlist, nil
+}
+
+// deleteWishlists deletes the wishlists matching query and args, with their
+// items and share links.
+func deleteWishlists(tx *gorm.DB, query string, args ...any) error {
+	ids := tx.Session(&gorm.Session{NewDB: true}).Model(&models.Wishlist{}).Select("id").Where(query, args...)
+	if err := tx.Where("wishlist_id IN (?)", ids).Delete(&models.WishlistItem{}).Error; err != nil {
+		return err
+	}
+	// Deleted wishlists are soft-deleted; clear the link so it stops working
+	// even for queries that see deleted rows.
+	if err := tx.Model(&models.Wishlist{}).Where(query, args...).Update("share_token_hash", nil).Error; err != nil {
+		return err
+	}
+	return tx.Where(query, args...).Delete(&models.Wishlist{}).Error
+}
+
+// removeFromWishlists takes the properties matching propertyIDs, a list or a
+// subquery, off every wishlist. It runs before the properties are deleted.
+func removeFromWishlists(tx *gorm.DB, propertyIDs any) error {
+	return tx.Where("property_id IN (?)", propertyIDs).Delete(&models.WishlistItem{}).Error
+}
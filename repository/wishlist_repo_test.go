@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"airbnb/models"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestUnavailableProperties(t *testing.T) {
+	db := newTestDB(t)
+	owner := createOwner(t, db)
+	from := models.Today(time.Now()).AddDate(0, 0, 10)
+	to := from.AddDate(0, 0, 3)
+
+	// Each property holds one booking or block; taken says whether it
+	// overlaps the nights from to to.
+	properties := map[string]struct {
+		taken bool
+		add   func(propertyID uuid.UUID) any
+	}{
+		"pending": {true, func(id uuid.UUID) any {
+			return &models.Booking{PropertyID: id, CheckIn: from.AddDate(0, 0, -1), CheckOut: from.AddDate(0, 0, 1), Status: models.Pending}
+		}},
+		"confirmed": {true, func(id uuid.UUID) any {
+			return &models.Booking{PropertyID: id, CheckIn: to.AddDate(0, 0, -1), CheckOut: to.AddDate(0, 0, 2), Status: models.Confirmed}
+		}},
+		"cancelled": {false, func(id uuid.UUID) any {
+			return &models.Booking{PropertyID: id, CheckIn: from, CheckOut: to, Status: models.Cancelled}
+		}},
+		"checks out on arrival": {false, func(id uuid.UUID) any {
+			return &models.Booking{PropertyID: id, CheckIn: from.AddDate(0, 0, -2), CheckOut: from, Status: models.Confirmed}
+		}},
+		"checks in on departure": {false, func(id uuid.UUID) any {
+			return &models.Booking{PropertyID: id, CheckIn: to, CheckOut: to.AddDate(0, 0, 2), Status: models.Confirmed}
+		}},
+		"blocked": {true, func(id uuid.UUID) any {
+			return &models.BlockedDate{PropertyID: id, StartDate: from.AddDate(0, 0, 1), EndDate: from.AddDate(0, 0, 2)}
+		}},
+		"blocked before": {false, func(id uuid.UUID) any {
+			return &models.BlockedDate{PropertyID: id, StartDate: from.AddDate(0, 0, -5), EndDate: from}
+		}},
+	}
+	ids := map[uuid.UUID]string{}
+	for name, p := range properties {
+		property := models.Property{Name: name, Price: 10000, Currency: models.DefaultCurrency, Location: "lake", OwnerID: owner.ID}
+		if err := NewPropertyRepo(db).CreateProperty(t.Context(), &property, nil); err != nil {
+			t.Fatalf("create property: %v", err)
+		}
+		row := p.add(property.ID)
+		if booking, ok := row.(*models.Booking); ok {
+			booking.UserID, booking.Guests, booking.Currency = owner.ID, 1, models.DefaultCurrency
+		}
+		if err := db.Create(row).Error; err != nil {
+			t.Fatalf("create %s: %v", name, err)
+		}
+		ids[property.ID] = name
+	}
+
+	idList := make([]uuid.UUID, 0, len(ids))
+	for id := range ids {
+		idList = append(idList, id)
+	}
+	unavailable, err := NewWishlistRepo(db).UnavailableProperties(t.Context(), idList, from, to)
+	if err != nil {
+		t.Fatalf("unavailable properties: %v", err)
+	}
+	for id, name := range ids {
+		if unavailable[id] != properties[name].taken {
+			t.Errorf("%s: unavailable = %v, want %v", name, unavailable[id], properties[name].taken)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	_ "airbnb/docs"
 	"airbnb/handlers"
 	"airbnb/middleware"
+	"airbnb/models"
 	"airbnb/repository"
 
 	"github.com/gin-gonic/gin"
@@ -12,50 +13,148 @@ import (
 )
 
 func Routes(
-	userRepo *repository.UserRepo,
+	auth *middleware.Authenticator,
 	propertyRepo *repository.PropertyRepo,
 	propertyHandlers *handlers.PropertyHandlers,
-	userHandlers *handlers.UserHandlers,
+	accountHandlers *handlers.AccountHandlers,
 	bookingHandlers *handlers.BookingHandlers,
+	adminHandlers *handlers.AdminHandlers,
+	reviewHandlers *handlers.ReviewHandlers,
+	messageHandlers *handlers.MessageHandlers,
+	wishlistHandlers *handlers.WishlistHandlers,
+	photoHandlers *handlers.PhotoHandlers,
+	amenityHandlers *handlers.AmenityHandlers,
+	priceRuleHandlers *handlers.PriceRuleHandlers,
 ) *gin.Engine {
 	router := gin.Default()
 
-	router.Use(gin.Logger())
+	router.Use(gin.Logger(), middleware.Problems())
+	router.NoRoute(middleware.RouteNotFound)
 
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
 	router.GET("/property/all", propertyHandlers.GetProperties)
-	router.DELETE("/cancel/booking/:bookingid", bookingHandlers.CancelBooking)
+	router.GET("/property/nearby", propertyHandlers.GetNearbyProperties)
+	router.GET("/property/:propertyid/availability", bookingHandlers.GetAvailability)
+	router.POST("/property/:propertyid/quote", propertyHandlers.GetQuote)
+	router.GET("/property/:propertyid/reviews", reviewHandlers.GetPropertyReviews)
+	router.GET("/wishlists/shared/:token", wishlistHandlers.GetSharedWishlist)
+	router.GET("/media/*key", photoHandlers.GetMedia)
+	router.GET("/amenities", amenityHandlers.ListAmenities)
 
-	router.POST("/property/owner/signup", propertyHandlers.CreatePropertyOwner)
-	router.POST("/property/owner/login", propertyHandlers.LoginPropertyOwner)
-	userRoutes := router.Group("/user")
+	router.POST("/account/signup", accountHandlers.CreateAccount)
+	router.POST("/account/login", accountHandlers.LoginAccount)
+	router.GET("/.well-known/jwks.json", accountHandlers.JWKS)
+	router.POST("/auth/refresh", accountHandlers.RefreshTokens)
+	router.GET("/auth/verify-email", accountHandlers.VerifyEmail)
+	router.POST("/auth/password/forgot", accountHandlers.ForgotPassword)
+	router.POST("/auth/password/reset", accountHandlers.ResetPassword)
+	authRoutes := router.Group("/auth")
+	authRoutes.Use(auth.RequireRole())
 	{
-		userRoutes.POST("/signup", userHandlers.CreateUser)
-		userRoutes.POST("/login", userHandlers.LoginUser)
+		authRoutes.POST("/logout", accountHandlers.Logout)
+		authRoutes.POST("/logout-all", accountHandlers.LogoutAll)
+		authRoutes.POST("/verify-email/resend", accountHandlers.ResendVerification)
+	}
+	accountRoutes := router.Group("/account")
+	accountRoutes.Use(auth.RequireRole())
+	{
+		accountRoutes.POST("/roles", accountHandlers.AddRole)
+	}
+	profileRoutes := router.Group("/me")
+	profileRoutes.Use(auth.RequireRole())
+	{
+		profileRoutes.GET("", accountHandlers.GetProfile)
+		profileRoutes.PATCH("", accountHandlers.UpdateProfile)
+		profileRoutes.PUT("/password", accountHandlers.ChangePassword)
+		profileRoutes.DELETE("", accountHandlers.DeleteAccount)
 	}
 
 	userBookingRoutes := router.Group("/user")
-	userBookingRoutes.Use(middleware.AuthUser(userRepo))
+	userBookingRoutes.Use(auth.RequireRole(models.GuestRole))
 	{
-		userBookingRoutes.POST("/booking/:propertyid", bookingHandlers.CreateBooking)
+		userBookingRoutes.POST("/booking/:propertyid", middleware.RequireVerifiedEmail, bookingHandlers.CreateBooking)
 		userBookingRoutes.GET("/booking", bookingHandlers.GetUserBookings)
 		userBookingRoutes.GET("/booking/:bookingid", bookingHandlers.GetUserBookingByID)
+		userBookingRoutes.POST("/review/:bookingid", reviewHandlers.ReviewProperty)
+		userBookingRoutes.GET("/reviews", reviewHandlers.GetGuestReviews)
+		userBookingRoutes.POST("/conversations", middleware.RequireVerifiedEmail, messageHandlers.StartConversation)
+		userBookingRoutes.POST("/wishlists", wishlistHandlers.CreateWishlist)
+		userBookingRoutes.GET("/wishlists", wishlistHandlers.ListWishlists)
+		userBookingRoutes.GET("/wishlists/:wishlistid", wishlistHandlers.GetWishlist)
+		userBookingRoutes.DELETE("/wishlists/:wishlistid", wishlistHandlers.DeleteWishlist)
+		userBookingRoutes.PUT("/wishlists/:wishlistid/properties/:propertyid", wishlistHandlers.SaveProperty)
+		userBookingRoutes.DELETE("/wishlists/:wishlistid/properties/:propertyid", wishlistHandlers.RemoveProperty)
+		userBookingRoutes.POST("/wishlists/:wishlistid/share", wishlistHandlers.ShareWishlist)
+		userBookingRoutes.DELETE("/wishlists/:wishlistid/share", wishlistHandlers.UnshareWishlist)
 	}
 
 	propertyRoutes := router.Group("/property")
-	propertyRoutes.Use(middleware.AuthPropertyOwner(propertyRepo))
+	propertyRoutes.Use(auth.RequireRole(models.HostRole))
 	{
 		propertyRoutes.POST("/create", propertyHandlers.CreateProperty)
-		propertyRoutes.GET("/:propertyid", propertyHandlers.GetPropertyByID)
 		propertyRoutes.GET("/owner", propertyHandlers.GetAllProperties)
 	}
+	ownedPropertyRoutes := router.Group("/property/:propertyid")
+	ownedPropertyRoutes.Use(auth.RequireRole(models.HostRole), middleware.OwnedProperty(propertyRepo))
+	{
+		ownedPropertyRoutes.GET("", propertyHandlers.GetPropertyByID)
+		ownedPropertyRoutes.PATCH("", propertyHandlers.UpdateProperty)
+		ownedPropertyRoutes.DELETE("", propertyHandlers.DeleteProperty)
+		ownedPropertyRoutes.POST("/block", bookingHandlers.BlockDates)
+		ownedPropertyRoutes.DELETE("/block/:blockid", bookingHandlers.UnblockDates)
+		ownedPropertyRoutes.POST("/photos", photoHandlers.UploadPhoto)
+		ownedPropertyRoutes.PUT("/photos", photoHandlers.ReorderPhotos)
+		ownedPropertyRoutes.PUT("/photos/:photoid/cover", photoHandlers.SetCoverPhoto)
+		ownedPropertyRoutes.DELETE("/photos/:photoid", photoHandlers.DeletePhoto)
+		ownedPropertyRoutes.GET("/pricing", priceRuleHandlers.GetPricing)
+		ownedPropertyRoutes.POST("/pricing/rules", priceRuleHandlers.AddPriceRule)
+		ownedPropertyRoutes.DELETE("/pricing/rules/:ruleid", priceRuleHandlers.DeletePriceRule)
+	}
+	router.GET("/inbox", auth.RequireRole(models.GuestRole, models.HostRole), messageHandlers.GetInbox)
+	conversationRoutes := router.Group("/conversations")
+	conversationRoutes.Use(auth.RequireRole(models.GuestRole, models.HostRole))
+	{
+		conversationRoutes.GET("/:conversationid/messages", messageHandlers.GetMessages)
+		conversationRoutes.POST("/:conversationid/messages", messageHandlers.SendMessage)
+		conversationRoutes.PUT("/:conversationid/read", messageHandlers.MarkRead)
+	}
+	cancelRoutes := router.Group("/cancel/booking")
+	cancelRoutes.Use(auth.RequireRole(models.GuestRole, models.HostRole))
+	{
+		cancelRoutes.DELETE("/:bookingid", bookingHandlers.CancelBooking)
+	}
 	ownerBookingRoutes := router.Group("/owner/booking")
-	ownerBookingRoutes.Use(middleware.AuthPropertyOwner(propertyRepo))
+	ownerBookingRoutes.Use(auth.RequireRole(models.HostRole))
 	{
 		ownerBookingRoutes.GET("/all", bookingHandlers.GetPropertyBookings)
 		ownerBookingRoutes.GET("/:bookingid", bookingHandlers.GetPropertyBookingByID)
 		ownerBookingRoutes.PUT("/:bookingid", bookingHandlers.ConfirmBooking)
+		ownerBookingRoutes.PUT("/:bookingid/decline", bookingHandlers.DeclineBooking)
+		ownerBookingRoutes.PUT("/:bookingid/checkin", bookingHandlers.CheckInBooking)
+		ownerBookingRoutes.PUT("/:bookingid/complete", bookingHandlers.CompleteBooking)
+		ownerBookingRoutes.POST("/:bookingid/review", reviewHandlers.ReviewGuest)
+	}
+	ownerReviewRoutes := router.Group("/owner/review")
+	ownerReviewRoutes.Use(auth.RequireRole(models.HostRole))
+	{
+		ownerReviewRoutes.PUT("/:reviewid/reply", reviewHandlers.ReplyToReview)
+	}
+
+	adminRoutes := router.Group("/admin")
+	adminRoutes.Use(auth.RequireRole(models.AdminRole))
+	{
+		adminRoutes.GET("/accounts", adminHandlers.ListAccounts)
+		adminRoutes.GET("/accounts/:accountid", adminHandlers.GetAccount)
+		adminRoutes.POST("/accounts/:accountid/suspend", adminHandlers.SuspendAccount)
+		adminRoutes.POST("/accounts/:accountid/reinstate", adminHandlers.ReinstateAccount)
+		adminRoutes.POST("/bookings/:bookingid/cancel", adminHandlers.CancelBooking)
+		adminRoutes.POST("/properties/:propertyid/unlist", adminHandlers.UnlistProperty)
+		adminRoutes.POST("/properties/:propertyid/relist", adminHandlers.RelistProperty)
+		adminRoutes.GET("/audit", adminHandlers.GetAuditLog)
+		adminRoutes.POST("/amenities", amenityHandlers.CreateAmenity)
+		adminRoutes.PATCH("/amenities/:key", amenityHandlers.UpdateAmenity)
+		adminRoutes.DELETE("/amenities/:key", amenityHandlers.DeleteAmenity)
 	}
 
 	return router
@@ -0,0 +1,1897 @@
+package routes
+
+import (
+	"airbnb/handlers"
+	"airbnb/mailer"
+	"airbnb/middleware"
+	"airbnb/models"
+	"airbnb/repository"
+	"airbnb/storage"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// publicRoutes need no token. notTenantScopedRoutes need a token but expose no
+// resource that belongs to another user or owner. Every other route must be
+// covered by a cross-tenant case below.
+var (
+	publicRoutes = map[string]bool{
+		"GET /swagger/*any":                      true,
+		"GET /property/all":                      true,
+		"GET /property/nearby":                   true,
+		"GET /property/:propertyid/availability": true,
+		"POST /property/:propertyid/quote":       true,
+		"GET /property/:propertyid/reviews":      true,
+		"GET /wishlists/shared/:token":           true,
+		"GET /media/*key":                        true,
+		"GET /amenities":                         true,
+		"POST /account/signup":                   true,
+		"POST /account/login":                    true,
+		"POST /auth/refresh":                     true,
+		"GET /.well-known/jwks.json":             true,
+		"GET /auth/verify-email":                 true,
+		"POST /auth/password/forgot":             true,
+		"POST /auth/password/reset":              true,
+	}
+	notTenantScopedRoutes = map[string]bool{
+		"POST /user/booking/:propertyid": true,
+		"POST /property/create":          true,
+		"POST /account/roles":            true,
+		"POST /auth/logout":              true,
+		"POST /auth/logout-all":          true,
+		"POST /auth/verify-email/resend": true,
+		"GET /me":                        true,
+		"PATCH /me":                      true,
+		"PUT /me/password":               true,
+		"DELETE /me":                     true,
+		"GET /user/reviews":              true,
+		"GET /inbox":                     true,
+		"POST /user/wishlists":           true,
+	}
+)
+
+type fixture struct {
+	router         *gin.Engine
+	db             *gorm.DB
+	auth           *middleware.Authenticator
+	mailDir        string
+	userA          string
+	userB          string
+	ownerA         string
+	ownerB         string
+	admin          string
+	adminID        uuid.UUID
+	userAID        uuid.UUID
+	propertyID     uuid.UUID
+	bookingID      uuid.UUID
+	reviewID       uuid.UUID
+	conversationID uuid.UUID
+	wishlistID     uuid.UUID
+	photoID        uuid.UUID
+	priceRuleID    uuid.UUID
+	blockID        uuid.UUID
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", uuid.NewString())), &gorm.Config{
+		Logger:         logger.Discard,
+		TranslateError: true,
+	})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	err = db.AutoMigrate(&models.Account{}, &models.Role{}, &models.Property{}, &models.Booking{}, &models.BlockedDate{}, &models.BookingTransition{}, &models.RefreshToken{}, &models.RevokedToken{}, &models.AccountToken{}, &models.AuditEntry{}, &models.Review{}, &models.Conversation{}, &models.Message{}, &models.Wishlist{}, &models.WishlistItem{}, &models.Photo{}, &models.Amenity{}, &models.PriceRule{})
+	if err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	accountRepo := repository.NewAccountRepo(db)
+	propertyRepo := repository.NewPropertyRepo(db)
+	bookingRepo := repository.NewBookingRepo(db)
+	tokenRepo := repository.NewTokenRepo(db)
+	auditRepo := repository.NewAuditRepo(db)
+	messageRepo := repository.NewMessageRepo(db)
+	wishlistRepo := repository.NewWishlistRepo(db)
+	blobs := storage.NewLocalStore(t.TempDir(), "http://api.test")
+	testKey := middleware.SigningKey{ID: "test", Algorithm: middleware.HS256, Secret: []byte("test-secret")}
+	keys, err := middleware.NewKeyManager(models.AccessTokenTTL, testKey.ID, testKey)
+	if err != nil {
+		t.Fatalf("keys: %v", err)
+	}
+	denylist := middleware.NewDenylist(tokenRepo)
+	auth := middleware.NewAuthenticator(accountRepo, denylist, keys)
+
+	mailDir := t.TempDir()
+	f := &fixture{
+		db:      db,
+		auth:    auth,
+		mailDir: mailDir,
+		router: Routes(
+			auth,
+			propertyRepo,
+			handlers.NewPropertyHandlers(propertyRepo, blobs),
+			handlers.NewAccountHandlers(accountRepo, tokenRepo, auth, &mailer.LogMailer{Dir: mailDir}, "http://api.test"),
+			handlers.NewBookingHandlers(bookingRepo),
+			handlers.NewAdminHandlers(accountRepo, bookingRepo, propertyRepo, auditRepo, denylist),
+			handlers.NewReviewHandlers(repository.NewReviewRepo(db)),
+			handlers.NewMessageHandlers(messageRepo),
+			handlers.NewWishlistHandlers(wishlistRepo, blobs, "http://api.test"),
+			handlers.NewPhotoHandlers(repository.NewPhotoRepo(db), blobs),
+			handlers.NewAmenityHandlers(repository.NewAmenityRepo(db)),
+			handlers.NewPriceRuleHandlers(repository.NewPriceRuleRepo(db)),
+		),
+	}
+
+	ctx := t.Context()
+	verifiedAt := time.Now()
+	var userIDs, ownerIDs []uuid.UUID
+	for _, name := range []string{"a", "b"} {
+		guest := models.Account{Name: "user " + name, Email: "user-" + name + "@example.com", Password: "x", EmailVerifiedAt: &verifiedAt, Roles: []models.Role{{Name: models.GuestRole}}}
+		if err := accountRepo.CreateAccount(ctx, &guest); err != nil {
+			t.Fatalf("create guest: %v", err)
+		}
+		userIDs = append(userIDs, guest.ID)
+		host := models.Account{Name: "owner " + name, Email: "owner-" + name + "@example.com", Password: "x", EmailVerifiedAt: &verifiedAt, Roles: []models.Role{{Name: models.HostRole}}}
+		if err := accountRepo.CreateAccount(ctx, &host); err != nil {
+			t.Fatalf("create host: %v", err)
+		}
+		ownerIDs = append(ownerIDs, host.ID)
+		switch name {
+		case "a":
+			f.userA, f.ownerA = f.token(t, &guest), f.token(t, &host)
+			f.userAID = guest.ID
+		case "b":
+			f.userB, f.ownerB = f.token(t, &guest), f.token(t, &host)
+		}
+	}
+
+	admin := models.Account{Name: "admin", Email: "admin@example.com", Password: "x", EmailVerifiedAt: &verifiedAt, Roles: []models.Role{{Name: models.AdminRole}}}
+	if err := accountRepo.CreateAccount(ctx, &admin); err != nil {
+		t.Fatalf("create admin: %v", err)
+	}
+	f.admin, f.adminID = f.token(t, &admin), admin.ID
+
+	property := models.Property{Name: "cabin", Price: 10000, Currency: models.DefaultCurrency, Location: "lake", OwnerID: ownerIDs[0]}
+	if err := propertyRepo.CreateProperty(ctx, &property, nil); err != nil {
+		t.Fatalf("create property: %v", err)
+	}
+	f.propertyID = property.ID
+
+	checkIn := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 1, 0)
+	booking := models.Booking{
+		UserID:     userIDs[0],
+		PropertyID: property.ID,
+		CheckIn:    checkIn,
+		CheckOut:   checkIn.AddDate(0, 0, 3),
+		Guests:     1,
+		Status:     models.Pending,
+	}
+	if err := bookingRepo.CreateBooking(ctx, &booking, time.Now()); err != nil {
+		t.Fatalf("create booking: %v", err)
+	}
+	f.bookingID = booking.ID
+
+	// Seeded directly: the booking has not checked out, so the API would
+	// refuse it.
+	review := models.Review{BookingID: booking.ID, PropertyID: property.ID, AuthorID: userIDs[0], AuthorRole: models.GuestRole, SubjectID: ownerIDs[0], Rating: 4, Comment: "nice"}
+	if err := db.Create(&review).Error; err != nil {
+		t.Fatalf("create review: %v", err)
+	}
+	f.reviewID = review.ID
+
+	conversation, err := messageRepo.StartConversation(ctx, userIDs[0], property.ID, &booking.ID, "hello", time.Now())
+	if err != nil {
+		t.Fatalf("start conversation: %v", err)
+	}
+	f.conversationID = conversation.ID
+
+	wishlist := models.Wishlist{GuestID: userIDs[0], Name: "maybe"}
+	if err := wishlistRepo.CreateWishlist(ctx, &wishlist); err != nil {
+		t.Fatalf("create wishlist: %v", err)
+	}
+	if err := wishlistRepo.SaveProperty(ctx, userIDs[0], wishlist.ID, property.ID); err != nil {
+		t.Fatalf("save property: %v", err)
+	}
+	f.wishlistID = wishlist.ID
+
+	// Seeded without files; uploads are covered by TestPropertyPhotos.
+	photo := models.Photo{PropertyID: property.ID, Width: 800, Height: 600}
+	if err := db.Create(&photo).Error; err != nil {
+		t.Fatalf("create photo: %v", err)
+	}
+	f.photoID = photo.ID
+
+	start, end := checkIn.AddDate(0, 2, 0), checkIn.AddDate(0, 2, 7)
+	rule := models.PriceRule{PropertyID: property.ID, Kind: models.PriceOverride, StartDate: &start, EndDate: &end, Price: 15000}
+	if err := repository.NewPriceRuleRepo(db).AddPriceRule(ctx, ownerIDs[0], &rule); err != nil {
+		t.Fatalf("add price rule: %v", err)
+	}
+	f.priceRuleID = rule.ID
+
+	block := models.BlockedDate{PropertyID: property.ID, StartDate: checkIn.AddDate(0, 1, 0), EndDate: checkIn.AddDate(0, 1, 2)}
+	if err := bookingRepo.BlockDates(ctx, ownerIDs[0], &block); err != nil {
+		t.Fatalf("block dates: %v", err)
+	}
+	f.blockID = block.ID
+
+	return f
+}
+
+func (f *fixture) token(t *testing.T, account *models.Account) string {
+	t.Helper()
+	token, _, err := f.auth.GenerateAccessToken(account)
+	if err != nil {
+		t.Fatalf("token: %v", err)
+	}
+	return token
+}
+
+func (f *fixture) do(method, path, token, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	f.router.ServeHTTP(w, req)
+	return w
+}
+
+type tenantCase struct {
+	route  string
+	path   string
+	token  string
+	body   string
+	status int
+}
+
+// crossTenantCases has user B or owner B reach for resources that belong to
+// user A or owner A.
+func crossTenantCases(f *fixture) []tenantCase {
+	booking := f.bookingID.String()
+	property := f.propertyID.String()
+	reason := `{"reason":"test"}`
+	review := `{"rating":5,"comment":"great"}`
+	conversation := f.conversationID.String()
+	wishlist := f.wishlistID.String()
+	photo := f.photoID.String()
+	return []tenantCase{
+		{route: "GET /user/booking", path: "/user/booking", token: f.userB, status: http.StatusOK},
+		{route: "GET /user/booking/:bookingid", path: "/user/booking/" + booking, token: f.userB, status: http.StatusNotFound},
+		{route: "GET /property/owner", path: "/property/owner", token: f.ownerB, status: http.StatusOK},
+		{route: "GET /property/:propertyid", path: "/property/" + property, token: f.ownerB, status: http.StatusNotFound},
+		{route: "PATCH /property/:propertyid", path: "/property/" + property, token: f.ownerB, body: `{"price":1}`, status: http.StatusNotFound},
+		{route: "DELETE /property/:propertyid", path: "/property/" + property + "?force=true", token: f.ownerB, status: http.StatusNotFound},
+		{route: "POST /property/:propertyid/block", path: "/property/" + property + "/block", token: f.ownerB, body: `{"start_date":"` + stayDate(0) + `","end_date":"` + stayDate(4) + `"}`, status: http.StatusNotFound},
+		{route: "DELETE /property/:propertyid/block/:blockid", path: "/property/" + property + "/block/" + f.blockID.String(), token: f.ownerB, status: http.StatusNotFound},
+		{route: "POST /property/:propertyid/photos", path: "/property/" + property + "/photos", token: f.ownerB, status: http.StatusNotFound},
+		{route: "PUT /property/:propertyid/photos", path: "/property/" + property + "/photos", token: f.ownerB, body: `{"photo_ids":["` + photo + `"]}`, status: http.StatusNotFound},
+		{route: "PUT /property/:propertyid/photos/:photoid/cover", path: "/property/" + property + "/photos/" + photo + "/cover", token: f.ownerB, status: http.StatusNotFound},
+		{route: "DELETE /property/:propertyid/photos/:photoid", path: "/property/" + property + "/photos/" + photo, token: f.ownerB, status: http.StatusNotFound},
+		{route: "GET /property/:propertyid/pricing", path: "/property/" + property + "/pricing", token: f.ownerB, status: http.StatusNotFound},
+		{route: "POST /property/:propertyid/pricing/rules", path: "/property/" + property + "/pricing/rules", token: f.ownerB, body: `{"kind":"weekday","weekdays":["sat"],"adjustment":5000}`, status: http.StatusNotFound},
+		{route: "DELETE /property/:propertyid/pricing/rules/:ruleid", path: "/property/" + property + "/pricing/rules/" + f.priceRuleID.String(), token: f.ownerB, status: http.StatusNotFound},
+		{route: "GET /owner/booking/all", path: "/owner/booking/all", token: f.ownerB, status: http.StatusOK},
+		{route: "GET /owner/booking/:bookingid", path: "/owner/booking/" + booking, token: f.ownerB, status: http.StatusNotFound},
+		{route: "PUT /owner/booking/:bookingid", path: "/owner/booking/" + booking, token: f.ownerB, status: http.StatusNotFound},
+		{route: "PUT /owner/booking/:bookingid/decline", path: "/owner/booking/" + booking + "/decline", token: f.ownerB, status: http.StatusNotFound},
+		{route: "PUT /owner/booking/:bookingid/checkin", path: "/owner/booking/" + booking + "/checkin", token: f.ownerB, status: http.StatusNotFound},
+		{route: "PUT /owner/booking/:bookingid/complete", path: "/owner/booking/" + booking + "/complete", token: f.ownerB, status: http.StatusNotFound},
+		{route: "DELETE /cancel/booking/:bookingid", path: "/cancel/booking/" + booking, status: http.StatusUnauthorized},
+		{route: "DELETE /cancel/booking/:bookingid", path: "/cancel/booking/" + booking, token: f.userB, status: http.StatusNotFound},
+		{route: "DELETE /cancel/booking/:bookingid", path: "/cancel/booking/" + booking, token: f.ownerB, status: http.StatusNotFound},
+		{route: "POST /user/review/:bookingid", path: "/user/review/" + booking, token: f.userB, body: review, status: http.StatusNotFound},
+		{route: "POST /owner/booking/:bookingid/review", path: "/owner/booking/" + booking + "/review", token: f.ownerB, body: review, status: http.StatusNotFound},
+		{route: "PUT /owner/review/:reviewid/reply", path: "/owner/review/" + f.reviewID.String() + "/reply", token: f.ownerB, body: `{"reply":"thanks"}`, status: http.StatusNotFound},
+		{route: "POST /user/conversations", path: "/user/conversations", token: f.userB, body: `{"property_id":"` + property + `","booking_id":"` + booking + `","body":"hi"}`, status: http.StatusNotFound},
+		{route: "GET /conversations/:conversationid/messages", path: "/conversations/" + conversation + "/messages", token: f.userB, status: http.StatusNotFound},
+		{route: "GET /conversations/:conversationid/messages", path: "/conversations/" + conversation + "/messages", token: f.ownerB, status: http.StatusNotFound},
+		{route: "POST /conversations/:conversationid/messages", path: "/conversations/" + conversation + "/messages", token: f.ownerB, body: `{"body":"hi"}`, status: http.StatusNotFound},
+		{route: "PUT /conversations/:conversationid/read", path: "/conversations/" + conversation + "/read", token: f.userB, status: http.StatusNotFound},
+		{route: "GET /user/wishlists", path: "/user/wishlists", token: f.userB, status: http.StatusOK},
+		{route: "GET /user/wishlists/:wishlistid", path: "/user/wishlists/" + wishlist, token: f.userB, status: http.StatusNotFound},
+		{route: "DELETE /user/wishlists/:wishlistid", path: "/user/wishlists/" + wishlist, token: f.userB, status: http.StatusNotFound},
+		{route: "PUT /user/wishlists/:wishlistid/properties/:propertyid", path: "/user/wishlists/" + wishlist + "/properties/" + property, token: f.userB, status: http.StatusNotFound},
+		{route: "DELETE /user/wishlists/:wishlistid/properties/:propertyid", path: "/user/wishlists/" + wishlist + "/properties/" + property, token: f.userB, status: http.StatusNotFound},
+		{route: "POST /user/wishlists/:wishlistid/share", path: "/user/wishlists/" + wishlist + "/share", token: f.userB, status: http.StatusNotFound},
+		{route: "DELETE /user/wishlists/:wishlistid/share", path: "/user/wishlists/" + wishlist + "/share", token: f.userB, status: http.StatusNotFound},
+		{route: "GET /admin/accounts", path: "/admin/accounts", token: f.ownerB, status: http.StatusForbidden},
+		{route: "GET /admin/accounts/:accountid", path: "/admin/accounts/" + f.userAID.String(), token: f.userB, status: http.StatusForbidden},
+		{route: "POST /admin/accounts/:accountid/suspend", path: "/admin/accounts/" + f.userAID.String() + "/suspend", token: f.userB, body: reason, status: http.StatusForbidden},
+		{route: "POST /admin/accounts/:accountid/reinstate", path: "/admin/accounts/" + f.userAID.String() + "/reinstate", token: f.userB, body: reason, status: http.StatusForbidden},
+		{route: "POST /admin/bookings/:bookingid/cancel", path: "/admin/bookings/" + booking + "/cancel", token: f.ownerB, body: reason, status: http.StatusForbidden},
+		{route: "POST /admin/properties/:propertyid/unlist", path: "/admin/properties/" + property + "/unlist", token: f.ownerB, body: reason, status: http.StatusForbidden},
+		{route: "POST /admin/properties/:propertyid/relist", path: "/admin/properties/" + property + "/relist", token: f.ownerB, body: reason, status: http.StatusForbidden},
+		{route: "GET /admin/audit", path: "/admin/audit", token: f.ownerB, status: http.StatusForbidden},
+		{route: "POST /admin/amenities", path: "/admin/amenities", token: f.ownerB, body: `{"key":"sauna","name":"Sauna"}`, status: http.StatusForbidden},
+		{route: "PATCH /admin/amenities/:key", path: "/admin/amenities/wifi", token: f.ownerB, body: `{"name":"Fast wifi"}`, status: http.StatusForbidden},
+		{route: "DELETE /admin/amenities/:key", path: "/admin/amenities/wifi", token: f.userB, status: http.StatusForbidden},
+	}
+}
+
+func TestCrossTenantAccessIsBlocked(t *testing.T) {
+	f := newFixture(t)
+	for _, tc := range crossTenantCases(f) {
+		t.Run(tc.route, func(t *testing.T) {
+			method, _, _ := strings.Cut(tc.route, " ")
+			w := f.do(method, tc.path, tc.token, tc.body)
+			if w.Code != tc.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tc.status, w.Body.String())
+			}
+			for _, id := range []uuid.UUID{f.bookingID, f.propertyID, f.blockID, f.photoID} {
+				if strings.Contains(w.Body.String(), id.String()) {
+					t.Fatalf("response leaks %s: %s", id, w.Body.String())
+				}
+			}
+		})
+	}
+
+	// Nothing above may have changed user A's booking.
+	w := f.do(http.MethodGet, "/user/booking/"+f.bookingID.String(), f.userA, "")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"status":"pending"`) {
+		t.Fatalf("booking changed by another tenant: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestTenantCanAccessOwnResources(t *testing.T) {
+	f := newFixture(t)
+	cases := []tenantCase{
+		{route: "GET /user/booking", path: "/user/booking", token: f.userA},
+		{route: "GET /user/booking/:bookingid", path: "/user/booking/" + f.bookingID.String(), token: f.userA},
+		{route: "GET /property/owner", path: "/property/owner", token: f.ownerA},
+		{route: "GET /property/:propertyid", path: "/property/" + f.propertyID.String(), token: f.ownerA},
+		{route: "GET /owner/booking/all", path: "/owner/booking/all", token: f.ownerA},
+		{route: "GET /owner/booking/:bookingid", path: "/owner/booking/" + f.bookingID.String(), token: f.ownerA},
+		{route: "GET /conversations/:conversationid/messages", path: "/conversations/" + f.conversationID.String() + "/messages", token: f.userA},
+		{route: "GET /conversations/:conversationid/messages", path: "/conversations/" + f.conversationID.String() + "/messages", token: f.ownerA},
+		{route: "GET /user/wishlists/:wishlistid", path: "/user/wishlists/" + f.wishlistID.String(), token: f.userA},
+		{route: "GET /property/:propertyid/pricing", path: "/property/" + f.propertyID.String() + "/pricing", token: f.ownerA},
+	}
+	for _, tc := range cases {
+		t.Run(tc.route, func(t *testing.T) {
+			w := f.do(http.MethodGet, tc.path, tc.token, "")
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestEveryRouteHasAccessCoverage(t *testing.T) {
+	f := newFixture(t)
+	covered := map[string]bool{}
+	for _, tc := range crossTenantCases(f) {
+		covered[tc.route] = true
+	}
+	for _, route := range f.router.Routes() {
+		key := route.Method + " " + route.Path
+		if !publicRoutes[key] && !notTenantScopedRoutes[key] && !covered[key] {
+			t.Errorf("route %s has no cross-tenant test case", key)
+		}
+	}
+}
+
+func TestRoutesRequireTheirRole(t *testing.T) {
+	f := newFixture(t)
+	cases := []tenantCase{
+		{route: "GET /user/booking", path: "/user/booking", token: f.ownerA},
+		{route: "POST /user/booking/:propertyid", path: "/user/booking/" + f.propertyID.String(), token: f.ownerA, body: `{"check_in":"` + stayDate(0) + `","check_out":"` + stayDate(1) + `","guests":1}`},
+		{route: "GET /property/owner", path: "/property/owner", token: f.userA},
+		{route: "GET /property/:propertyid", path: "/property/" + f.propertyID.String(), token: f.userA},
+		{route: "GET /owner/booking/all", path: "/owner/booking/all", token: f.userA},
+	}
+	for _, tc := range cases {
+		t.Run(tc.route, func(t *testing.T) {
+			method, _, _ := strings.Cut(tc.route, " ")
+			w := f.do(method, tc.path, tc.token, tc.body)
+			if w.Code != http.StatusForbidden {
+				t.Fatalf("status = %d, want 403: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestGuestCanBecomeHost(t *testing.T) {
+	f := newFixture(t)
+	w := f.do(http.MethodPost, "/account/signup", "", `{"name":"c","email":"c@example.com","password":"secret-123"}`)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"roles":["guest"]`) {
+		t.Fatalf("signup: %d %s", w.Code, w.Body.String())
+	}
+	w = f.do(http.MethodPost, "/account/signup", "", `{"name":"c","email":"c@example.com","password":"secret-123"}`)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("duplicate signup: %d %s", w.Code, w.Body.String())
+	}
+
+	guest := loginToken(t, f, `{"email":"c@example.com","password":"secret-123"}`)
+	if w := f.do(http.MethodGet, "/property/owner", guest, ""); w.Code != http.StatusForbidden {
+		t.Fatalf("guest listing properties: %d %s", w.Code, w.Body.String())
+	}
+	w = f.do(http.MethodPost, "/account/roles", guest, `{"role":"admin"}`)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("self-assigned admin: %d %s", w.Code, w.Body.String())
+	}
+	w = f.do(http.MethodPost, "/account/roles", guest, `{"role":"host"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("add host role: %d %s", w.Code, w.Body.String())
+	}
+
+	both := loginToken(t, f, `{"email":"c@example.com","password":"secret-123"}`)
+	for _, path := range []string{"/property/owner", "/user/booking"} {
+		if w := f.do(http.MethodGet, path, both, ""); w.Code != http.StatusOK {
+			t.Fatalf("GET %s as guest and host: %d %s", path, w.Code, w.Body.String())
+		}
+	}
+}
+
+func loginToken(t *testing.T, f *fixture, body string) string {
+	t.Helper()
+	return signIn(t, f, "/account/login", body).Token
+}
+
+func signIn(t *testing.T, f *fixture, path, body string) models.AuthTokens {
+	t.Helper()
+	w := f.do(http.MethodPost, path, "", body)
+	if w.Code != http.StatusOK {
+		t.Fatalf("POST %s: %d %s", path, w.Code, w.Body.String())
+	}
+	var tokens models.AuthTokens
+	if err := json.Unmarshal(w.Body.Bytes(), &tokens); err != nil {
+		t.Fatalf("POST %s response: %v", path, err)
+	}
+	return tokens
+}
+
+func TestRefreshTokenRotationAndLogout(t *testing.T) {
+	f := newFixture(t)
+	first := signIn(t, f, "/account/signup", `{"name":"d","email":"d@example.com","password":"secret-123"}`)
+	second := signIn(t, f, "/auth/refresh", `{"refresh_token":"`+first.RefreshToken+`"}`)
+	if second.RefreshToken == first.RefreshToken || second.Token == first.Token {
+		t.Fatal("refresh did not rotate the tokens")
+	}
+	if w := f.do(http.MethodGet, "/user/booking", second.Token, ""); w.Code != http.StatusOK {
+		t.Fatalf("refreshed access token: %d %s", w.Code, w.Body.String())
+	}
+
+	// Reusing a rotated refresh token revokes the whole family.
+	if w := f.do(http.MethodPost, "/auth/refresh", "", `{"refresh_token":"`+first.RefreshToken+`"}`); w.Code != http.StatusUnauthorized {
+		t.Fatalf("reused refresh token: %d %s", w.Code, w.Body.String())
+	}
+	if w := f.do(http.MethodGet, "/user/booking", second.Token, ""); w.Code != http.StatusUnauthorized {
+		t.Fatalf("access token after reuse: %d %s", w.Code, w.Body.String())
+	}
+	if w := f.do(http.MethodPost, "/auth/refresh", "", `{"refresh_token":"`+second.RefreshToken+`"}`); w.Code != http.StatusUnauthorized {
+		t.Fatalf("refresh token after reuse: %d %s", w.Code, w.Body.String())
+	}
+
+	session := signIn(t, f, "/account/login", `{"email":"d@example.com","password":"secret-123"}`)
+	other := signIn(t, f, "/account/login", `{"email":"d@example.com","password":"secret-123"}`)
+	w := f.do(http.MethodPost, "/auth/logout", session.Token, `{"refresh_token":"`+session.RefreshToken+`"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("logout: %d %s", w.Code, w.Body.String())
+	}
+	if w := f.do(http.MethodGet, "/user/booking", session.Token, ""); w.Code != http.StatusUnauthorized {
+		t.Fatalf("access token after logout: %d %s", w.Code, w.Body.String())
+	}
+	if w := f.do(http.MethodGet, "/user/booking", other.Token, ""); w.Code != http.StatusOK {
+		t.Fatalf("other session after logout: %d %s", w.Code, w.Body.String())
+	}
+
+	w = f.do(http.MethodPost, "/auth/logout-all", other.Token, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("logout-all: %d %s", w.Code, w.Body.String())
+	}
+	if w := f.do(http.MethodPost, "/auth/refresh", "", `{"refresh_token":"`+other.RefreshToken+`"}`); w.Code != http.StatusUnauthorized {
+		t.Fatalf("refresh token after logout-all: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestSigningKeyRotation(t *testing.T) {
+	f := newFixture(t)
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	oldKey := middleware.SigningKey{ID: "test", Algorithm: middleware.HS256, Secret: []byte("test-secret")}
+	newKey := middleware.SigningKey{ID: "ed-1", Algorithm: middleware.EdDSA, PrivateKey: private}
+	if err := f.auth.Keys.SetKeys(newKey.ID, []middleware.SigningKey{oldKey, newKey}, time.Now()); err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+
+	w := f.do(http.MethodGet, "/.well-known/jwks.json", "", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("jwks: %d %s", w.Code, w.Body.String())
+	}
+	var jwks models.JWKS
+	if err := json.Unmarshal(w.Body.Bytes(), &jwks); err != nil {
+		t.Fatalf("jwks response: %v", err)
+	}
+	if len(jwks.Keys) != 1 || jwks.Keys[0].Kid != "ed-1" || jwks.Keys[0].Crv != "Ed25519" {
+		t.Fatalf("jwks should publish only the Ed25519 key: %s", w.Body.String())
+	}
+
+	newToken := signIn(t, f, "/account/signup", `{"name":"e","email":"e@example.com","password":"secret-123"}`).Token
+	header, _, _ := strings.Cut(newToken, ".")
+	if raw, _ := base64.RawURLEncoding.DecodeString(header); !strings.Contains(string(raw), `"kid":"ed-1"`) {
+		t.Fatalf("token not signed with the new key: %s", raw)
+	}
+	for _, token := range []string{newToken, f.userA} {
+		if w := f.do(http.MethodGet, "/user/booking", token, ""); w.Code != http.StatusOK {
+			t.Fatalf("token after rotation: %d %s", w.Code, w.Body.String())
+		}
+	}
+
+	// A dropped key keeps verifying until its tokens have expired.
+	if err := f.auth.Keys.SetKeys(newKey.ID, []middleware.SigningKey{newKey}, time.Now()); err != nil {
+		t.Fatalf("drop old key: %v", err)
+	}
+	if w := f.do(http.MethodGet, "/user/booking", f.userA, ""); w.Code != http.StatusOK {
+		t.Fatalf("old token inside retention: %d %s", w.Code, w.Body.String())
+	}
+	if err := f.auth.Keys.SetKeys(newKey.ID, []middleware.SigningKey{newKey}, time.Now().Add(models.AccessTokenTTL+time.Minute)); err != nil {
+		t.Fatalf("expire old key: %v", err)
+	}
+	if w := f.do(http.MethodGet, "/user/booking", f.userA, ""); w.Code != http.StatusUnauthorized {
+		t.Fatalf("old token after retention: %d %s", w.Code, w.Body.String())
+	}
+}
+
+// lastMail returns the body of the most recent email sent to to.
+func lastMail(t *testing.T, f *fixture, to string) string {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join(f.mailDir, "*-"+strings.ReplaceAll(to, "@", "_at_")+".eml"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no email sent to %s", to)
+	}
+	data, err := os.ReadFile(files[len(files)-1])
+	if err != nil {
+		t.Fatalf("read email: %v", err)
+	}
+	_, body, _ := strings.Cut(string(data), "\r\n\r\n")
+	return body
+}
+
+// waitMail waits for the most recent email sent to to to match re, as some
+// emails are sent after the response, and returns re's submatches.
+func waitMail(t *testing.T, f *fixture, to string, re *regexp.Regexp) []string {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		files, _ := filepath.Glob(filepath.Join(f.mailDir, "*-"+strings.ReplaceAll(to, "@", "_at_")+".eml"))
+		if len(files) == 0 {
+			continue
+		}
+		if match := re.FindStringSubmatch(lastMail(t, f, to)); match != nil {
+			return match
+		}
+	}
+	t.Fatalf("no email to %s matching %s", to, re)
+	return nil
+}
+
+var (
+	verifyLink = regexp.MustCompile(`/auth/verify-email\?token=([\w-]+)`)
+	resetCode  = regexp.MustCompile(`\r\n\r\n([\w-]{43})\r\n`)
+)
+
+func TestEmailVerificationAndPasswordReset(t *testing.T) {
+	f := newFixture(t)
+	tokens := signIn(t, f, "/account/signup", `{"name":"f","email":"f@example.com","password":"secret-123"}`)
+	booking := "/user/booking/" + f.propertyID.String()
+	if w := f.do(http.MethodPost, booking, tokens.Token, `{}`); w.Code != http.StatusForbidden {
+		t.Fatalf("unverified booking: %d %s", w.Code, w.Body.String())
+	}
+
+	match := verifyLink.FindStringSubmatch(lastMail(t, f, "f@example.com"))
+	if match == nil {
+		t.Fatal("verification email has no link")
+	}
+	if w := f.do(http.MethodGet, "/auth/verify-email?token="+match[1], "", ""); w.Code != http.StatusOK {
+		t.Fatalf("verify: %d %s", w.Code, w.Body.String())
+	}
+	if w := f.do(http.MethodGet, "/auth/verify-email?token="+match[1], "", ""); w.Code != http.StatusBadRequest {
+		t.Fatalf("reused verification link: %d %s", w.Code, w.Body.String())
+	}
+	if w := f.do(http.MethodPost, booking, tokens.Token, `{}`); w.Code == http.StatusForbidden {
+		t.Fatalf("verified booking: %d %s", w.Code, w.Body.String())
+	}
+	if w := f.do(http.MethodPost, "/auth/verify-email/resend", tokens.Token, ""); w.Code != http.StatusConflict {
+		t.Fatalf("resend after verifying: %d %s", w.Code, w.Body.String())
+	}
+
+	// Unknown emails get the same answer and no email.
+	if w := f.do(http.MethodPost, "/auth/password/forgot", "", `{"email":"nobody@example.com"}`); w.Code != http.StatusOK {
+		t.Fatalf("forgot unknown email: %d %s", w.Code, w.Body.String())
+	}
+	if w := f.do(http.MethodPost, "/auth/password/forgot", "", `{"email":"f@example.com"}`); w.Code != http.StatusOK {
+		t.Fatalf("forgot: %d %s", w.Code, w.Body.String())
+	}
+	match = waitMail(t, f, "f@example.com", resetCode)
+	if files, _ := filepath.Glob(filepath.Join(f.mailDir, "*nobody*")); len(files) != 0 {
+		t.Fatal("reset email sent to an unknown address")
+	}
+	reset := `{"token":"` + match[1] + `","password":"new-secret-456"}`
+	if w := f.do(http.MethodPost, "/auth/password/reset", "", reset); w.Code != http.StatusOK {
+		t.Fatalf("reset: %d %s", w.Code, w.Body.String())
+	}
+	if w := f.do(http.MethodPost, "/auth/password/reset", "", reset); w.Code != http.StatusBadRequest {
+		t.Fatalf("reused reset code: %d %s", w.Code, w.Body.String())
+	}
+
+	// Resetting the password signs out every session.
+	if w := f.do(http.MethodGet, "/user/booking", tokens.Token, ""); w.Code != http.StatusUnauthorized {
+		t.Fatalf("access token after reset: %d %s", w.Code, w.Body.String())
+	}
+	if w := f.do(http.MethodPost, "/auth/refresh", "", `{"refresh_token":"`+tokens.RefreshToken+`"}`); w.Code != http.StatusUnauthorized {
+		t.Fatalf("refresh token after reset: %d %s", w.Code, w.Body.String())
+	}
+	if w := f.do(http.MethodPost, "/account/login", "", `{"email":"f@example.com","password":"secret-123"}`); w.Code != http.StatusUnauthorized {
+		t.Fatalf("login with old password: %d %s", w.Code, w.Body.String())
+	}
+	loginToken(t, f, `{"email":"f@example.com","password":"new-secret-456"}`)
+}
+
+func TestValidationErrorsListFields(t *testing.T) {
+	f := newFixture(t)
+	cases := []struct {
+		name, method, path, token, body string
+		want                            []models.FieldError
+	}{
+		{
+			name: "signup", method: http.MethodPost, path: "/account/signup",
+			body: `{"name":"","email":"not-an-email","password":"short","roles":["guest","admin"]}`,
+			want: []models.FieldError{
+				{Field: "name", Code: "required"},
+				{Field: "email", Code: "email"},
+				{Field: "password", Code: "password"},
+				{Field: "roles[1]", Code: "oneof"},
+			},
+		},
+		{
+			name: "create property", method: http.MethodPost, path: "/property/create", token: f.ownerA,
+			body: `{"property_name":"loft","price":-5,"address":{"country":"NG"}}`,
+			want: []models.FieldError{
+				{Field: "price", Code: "gt"},
+				{Field: "address.city", Code: "required"},
+			},
+		},
+		{
+			name: "update property", method: http.MethodPatch, path: "/property/" + f.propertyID.String(), token: f.ownerA,
+			body: `{"property_name":"","currency":"dollars"}`,
+			want: []models.FieldError{
+				{Field: "property_name", Code: "min"},
+				{Field: "currency", Code: "len"},
+			},
+		},
+		{
+			name: "times and dates", method: http.MethodPatch, path: "/property/" + f.propertyID.String(), token: f.ownerA,
+			body: `{"house_rules":{"quiet_hours_start":"late","quiet_hours_end":"07:00"},"check_in_time":"4pm"}`,
+			want: []models.FieldError{
+				{Field: "house_rules.quiet_hours_start", Code: "datetime", Message: "house_rules.quiet_hours_start must be a time in HH:MM format"},
+				{Field: "check_in_time", Code: "datetime", Message: "check_in_time must be a time in HH:MM format"},
+			},
+		},
+		{
+			name: "date", method: http.MethodPost, path: "/property/" + f.propertyID.String() + "/quote",
+			body: `{"check_in":"tomorrow","check_out":"` + stayDate(2) + `","guests":1}`,
+			want: []models.FieldError{{Field: "check_in", Code: "datetime", Message: "check_in must be a date in YYYY-MM-DD format"}},
+		},
+		{
+			name: "wrong type", method: http.MethodPost, path: "/property/" + f.propertyID.String() + "/quote",
+			body: `{"check_in":"` + stayDate(0) + `","check_out":"` + stayDate(2) + `","guests":"two"}`,
+			want: []models.FieldError{{Field: "guests", Code: "type"}},
+		},
+		{
+			name: "query", method: http.MethodGet, path: "/property/all?limit=500&sort=cheapest",
+			want: []models.FieldError{
+				{Field: "sort", Code: "oneof"},
+				{Field: "limit", Code: "max"},
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := f.do(tc.method, tc.path, tc.token, tc.body)
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want 400: %s", w.Code, w.Body.String())
+			}
+			var resp models.Problem
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("response: %v", err)
+			}
+			if resp.Code != "validation_failed" {
+				t.Fatalf("code = %q, want validation_failed", resp.Code)
+			}
+			if len(resp.Errors) != len(tc.want) {
+				t.Fatalf("errors = %+v, want %+v", resp.Errors, tc.want)
+			}
+			for i, want := range tc.want {
+				got := resp.Errors[i]
+				if got.Field != want.Field || got.Code != want.Code || got.Message == "" || (want.Message != "" && got.Message != want.Message) {
+					t.Fatalf("errors[%d] = %+v, want field %q code %q", i, got, want.Field, want.Code)
+				}
+			}
+		})
+	}
+}
+
+func TestErrorsAreProblemDetails(t *testing.T) {
+	f := newFixture(t)
+	signIn(t, f, "/account/signup", `{"name":"g","email":"g@example.com","password":"secret-123"}`)
+	quote := `{"check_in":"` + stayDate(0) + `","check_out":"` + stayDate(2) + `","guests":1}`
+	cases := []struct {
+		name, method, path, token, body string
+		status                          int
+		code                            string
+	}{
+		{"duplicate email", http.MethodPost, "/account/signup", "", `{"name":"g","email":"g@example.com","password":"secret-123"}`, http.StatusConflict, "email_taken"},
+		{"missing property", http.MethodPost, "/property/" + uuid.NewString() + "/quote", "", quote, http.StatusNotFound, "property_not_found"},
+		{"invalid id", http.MethodPost, "/property/not-a-uuid/quote", "", quote, http.StatusBadRequest, "invalid_property_id"},
+		{"unknown route", http.MethodGet, "/nowhere", "", "", http.StatusNotFound, "route_not_found"},
+		{"no token", http.MethodGet, "/user/booking", "", "", http.StatusUnauthorized, "missing_token"},
+		{"bad token", http.MethodGet, "/user/booking", "not-a-jwt", "", http.StatusUnauthorized, "invalid_token"},
+		{"wrong role", http.MethodGet, "/user/booking", f.ownerA, "", http.StatusForbidden, "role_required"},
+		{"bad credentials", http.MethodPost, "/account/login", "", `{"email":"g@example.com","password":"wrong"}`, http.StatusUnauthorized, "invalid_credentials"},
+		{"invalid transition", http.MethodPut, "/owner/booking/" + f.bookingID.String() + "/complete", f.ownerA, "", http.StatusConflict, "invalid_transition"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := f.do(tc.method, tc.path, tc.token, tc.body)
+			problem := decodeProblem(t, w)
+			if w.Code != tc.status || problem.Status != tc.status || problem.Code != tc.code {
+				t.Fatalf("got %d %s, want %d %s", w.Code, w.Body.String(), tc.status, tc.code)
+			}
+		})
+	}
+
+	// Unexpected database errors are reported without their message.
+	if err := f.db.Migrator().DropTable(&models.BlockedDate{}); err != nil {
+		t.Fatalf("drop table: %v", err)
+	}
+	w := f.do(http.MethodGet, "/property/"+f.propertyID.String()+"/availability?from="+stayDate(0)+"&to="+stayDate(4), "", "")
+	problem := decodeProblem(t, w)
+	if w.Code != http.StatusInternalServerError || problem.Code != "internal_error" || strings.Contains(w.Body.String(), "blocked_dates") {
+		t.Fatalf("database error: %d %s", w.Code, w.Body.String())
+	}
+}
+
+// stayDate is offset days after a Monday over a year from now: far enough
+// ahead that stays built from it are never in the past, and clear of the
+// fixture's booking a month out.
+func stayDate(offset int) string {
+	monday := models.Today(time.Now()).AddDate(1, 0, 0)
+	for monday.Weekday() != time.Monday {
+		monday = monday.AddDate(0, 0, 1)
+	}
+	return monday.AddDate(0, 0, offset).Format(models.DateLayout)
+}
+
+func decodeProblem(t *testing.T, w *httptest.ResponseRecorder) models.Problem {
+	t.Helper()
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/problem+json") {
+		t.Fatalf("content type = %q: %s", ct, w.Body.String())
+	}
+	var problem models.Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("problem: %v", err)
+	}
+	return problem
+}
+
+func TestAdminModeration(t *testing.T) {
+	f := newFixture(t)
+	reason := `{"reason":"spam reports"}`
+
+	// Listing pages through the guests newest first.
+	var page models.AdminAccounts
+	seen := map[uuid.UUID]bool{}
+	path := "/admin/accounts?role=guest&limit=1"
+	for {
+		w := f.do(http.MethodGet, path, f.admin, "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("list accounts: %d %s", w.Code, w.Body.String())
+		}
+		page = models.AdminAccounts{}
+		if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+			t.Fatalf("list accounts response: %v", err)
+		}
+		for _, account := range page.Accounts {
+			if seen[account.AccountID] || !slices.Contains(account.Roles, models.GuestRole) {
+				t.Fatalf("unexpected account in listing: %s", w.Body.String())
+			}
+			seen[account.AccountID] = true
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		path = "/admin/accounts?role=guest&limit=1&cursor=" + page.NextCursor
+	}
+	if len(seen) != 2 {
+		t.Fatalf("listed %d guests, want 2", len(seen))
+	}
+
+	// A suspended account is signed out, cannot sign in and can be reinstated.
+	tokens := signIn(t, f, "/account/signup", `{"name":"e","email":"e@example.com","password":"secret-123"}`)
+	suspend := "/admin/accounts/" + tokens.AccountID.String() + "/suspend"
+	if w := f.do(http.MethodPost, suspend, f.admin, `{}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("suspend without reason: %d %s", w.Code, w.Body.String())
+	}
+	if w := f.do(http.MethodPost, suspend, f.admin, reason); w.Code != http.StatusOK {
+		t.Fatalf("suspend: %d %s", w.Code, w.Body.String())
+	}
+	if w := f.do(http.MethodPost, suspend, f.admin, reason); w.Code != http.StatusConflict {
+		t.Fatalf("suspend twice: %d %s", w.Code, w.Body.String())
+	}
+	if w := f.do(http.MethodGet, "/user/booking", tokens.Token, ""); w.Code != http.StatusUnauthorized {
+		t.Fatalf("access token after suspension: %d %s", w.Code, w.Body.String())
+	}
+	if w := f.do(http.MethodPost, "/auth/refresh", "", `{"refresh_token":"`+tokens.RefreshToken+`"}`); w.Code != http.StatusUnauthorized {
+		t.Fatalf("refresh after suspension: %d %s", w.Code, w.Body.String())
+	}
+	w := f.do(http.MethodPost, "/account/login", "", `{"email":"e@example.com","password":"secret-123"}`)
+	if problem := decodeProblem(t, w); w.Code != http.StatusForbidden || problem.Code != "account_suspended" {
+		t.Fatalf("login while suspended: %d %s", w.Code, w.Body.String())
+	}
+	if w := f.do(http.MethodGet, "/admin/accounts?status=suspended", f.admin, ""); !strings.Contains(w.Body.String(), tokens.AccountID.String()) {
+		t.Fatalf("suspended accounts: %d %s", w.Code, w.Body.String())
+	}
+	if w := f.do(http.MethodPost, "/admin/accounts/"+f.adminID.String()+"/suspend", f.admin, reason); w.Code != http.StatusForbidden {
+		t.Fatalf("suspend self: %d %s", w.Code, w.Body.String())
+	}
+	if w := f.do(http.MethodPost, "/admin/accounts/"+tokens.AccountID.String()+"/reinstate", f.admin, reason); w.Code != http.StatusOK {
+		t.Fatalf("reinstate: %d %s", w.Code, w.Body.String())
+	}
+	loginToken(t, f, `{"email":"e@example.com","password":"secret-123"}`)
+
+	// Tokens issued before a suspension stop working even if never revoked.
+	if w := f.do(http.MethodPost, "/admin/accounts/"+f.userAID.String()+"/suspend", f.admin, reason); w.Code != http.StatusOK {
+		t.Fatalf("suspend user a: %d %s", w.Code, w.Body.String())
+	}
+	w = f.do(http.MethodGet, "/user/booking", f.userA, "")
+	if problem := decodeProblem(t, w); w.Code != http.StatusForbidden || problem.Code != "account_suspended" {
+		t.Fatalf("suspended guest: %d %s", w.Code, w.Body.String())
+	}
+
+	// Force-cancelling refunds the guest in full.
+	w = f.do(http.MethodPost, "/admin/bookings/"+f.bookingID.String()+"/cancel", f.admin, reason)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"cancelled_by_role":"admin"`) {
+		t.Fatalf("force cancel: %d %s", w.Code, w.Body.String())
+	}
+	var cancelled models.CancelledBooking
+	if err := json.Unmarshal(w.Body.Bytes(), &cancelled); err != nil || cancelled.RefundAmount != cancelled.TotalPrice {
+		t.Fatalf("force cancel refund: %s", w.Body.String())
+	}
+	if w := f.do(http.MethodPost, "/admin/bookings/"+f.bookingID.String()+"/cancel", f.admin, reason); w.Code != http.StatusConflict {
+		t.Fatalf("force cancel twice: %d %s", w.Code, w.Body.String())
+	}
+
+	// An unlisted property is hidden from guests but not from its owner.
+	property := f.propertyID.String()
+	quote := `{"check_in":"` + stayDate(0) + `","check_out":"` + stayDate(2) + `","guests":1}`
+	if w := f.do(http.MethodPost, "/admin/properties/"+property+"/unlist", f.admin, reason); w.Code != http.StatusOK {
+		t.Fatalf("unlist: %d %s", w.Code, w.Body.String())
+	}
+	if w := f.do(http.MethodPost, "/property/"+property+"/quote", "", quote); w.Code != http.StatusNotFound {
+		t.Fatalf("quote unlisted property: %d %s", w.Code, w.Body.String())
+	}
+	if w := f.do(http.MethodGet, "/property/all", "", ""); strings.Contains(w.Body.String(), property) {
+		t.Fatalf("unlisted property in listing: %s", w.Body.String())
+	}
+	if w := f.do(http.MethodPost, "/user/booking/"+property, f.userB, quote); w.Code != http.StatusNotFound {
+		t.Fatalf("book unlisted property: %d %s", w.Code, w.Body.String())
+	}
+	if w := f.do(http.MethodGet, "/property/"+property, f.ownerA, ""); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"unlisted_at"`) {
+		t.Fatalf("owner viewing unlisted property: %d %s", w.Code, w.Body.String())
+	}
+	if w := f.do(http.MethodPost, "/admin/properties/"+property+"/relist", f.admin, reason); w.Code != http.StatusOK {
+		t.Fatalf("relist: %d %s", w.Code, w.Body.String())
+	}
+	if w := f.do(http.MethodPost, "/property/"+property+"/quote", "", quote); w.Code != http.StatusOK {
+		t.Fatalf("quote relisted property: %d %s", w.Code, w.Body.String())
+	}
+
+	// Every action above is in the audit log.
+	w = f.do(http.MethodGet, "/admin/audit", f.admin, "")
+	var log models.AuditLog
+	if err := json.Unmarshal(w.Body.Bytes(), &log); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("audit log: %d %s", w.Code, w.Body.String())
+	}
+	var actions []string
+	for _, entry := range log.Entries {
+		if entry.ActorID != f.adminID || entry.Reason != "spam reports" {
+			t.Fatalf("audit entry: %+v", entry)
+		}
+		actions = append(actions, entry.Action)
+	}
+	want := []string{models.AuditRelistProperty, models.AuditUnlistProperty, models.AuditCancelBooking, models.AuditSuspendAccount, models.AuditReinstateAccount, models.AuditSuspendAccount}
+	if !slices.Equal(actions, want) {
+		t.Fatalf("audit actions = %v, want %v", actions, want)
+	}
+	w = f.do(http.MethodGet, "/admin/audit?target_id="+property, f.admin, "")
+	if err := json.Unmarshal(w.Body.Bytes(), &log); err != nil || len(log.Entries) != 2 {
+		t.Fatalf("audit log for property: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestProfileManagement(t *testing.T) {
+	f := newFixture(t)
+	tokens := signIn(t, f, "/account/signup", `{"name":"p","email":"p@example.com","password":"secret-123"}`)
+
+	w := f.do(http.MethodGet, "/me", tokens.Token, "")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"email":"p@example.com"`) {
+		t.Fatalf("profile: %d %s", w.Code, w.Body.String())
+	}
+	if strings.Contains(strings.ToLower(w.Body.String()), "password") || strings.Contains(w.Body.String(), "$2a$") {
+		t.Fatalf("profile leaks the password hash: %s", w.Body.String())
+	}
+	if w := f.do(http.MethodPatch, "/me", tokens.Token, `{"email":"user-a@example.com"}`); w.Code != http.StatusConflict {
+		t.Fatalf("taken email: %d %s", w.Code, w.Body.String())
+	}
+
+	// A new email has to be verified again.
+	match := verifyLink.FindStringSubmatch(lastMail(t, f, "p@example.com"))
+	if w := f.do(http.MethodGet, "/auth/verify-email?token="+match[1], "", ""); w.Code != http.StatusOK {
+		t.Fatalf("verify: %d %s", w.Code, w.Body.String())
+	}
+	w = f.do(http.MethodPatch, "/me", tokens.Token, `{"name":"q","email":"q@example.com"}`)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"name":"q"`) || !strings.Contains(w.Body.String(), `"email_verified":false`) {
+		t.Fatalf("update profile: %d %s", w.Code, w.Body.String())
+	}
+	match = verifyLink.FindStringSubmatch(lastMail(t, f, "q@example.com"))
+	if match == nil {
+		t.Fatal("no verification email sent to the new address")
+	}
+	booking := `{"check_in":"` + stayDate(0) + `","check_out":"` + stayDate(2) + `","guests":1}`
+	if w := f.do(http.MethodPost, "/user/booking/"+f.propertyID.String(), tokens.Token, booking); w.Code != http.StatusForbidden {
+		t.Fatalf("booking with unverified new email: %d %s", w.Code, w.Body.String())
+	}
+	if w := f.do(http.MethodGet, "/auth/verify-email?token="+match[1], "", ""); w.Code != http.StatusOK {
+		t.Fatalf("verify new email: %d %s", w.Code, w.Body.String())
+	}
+
+	// Changing the password needs the current one and signs out other sessions.
+	other := loginToken(t, f, `{"email":"q@example.com","password":"secret-123"}`)
+	if w := f.do(http.MethodPut, "/me/password", tokens.Token, `{"current_password":"wrong","new_password":"new-secret-456"}`); w.Code != http.StatusUnauthorized {
+		t.Fatalf("wrong current password: %d %s", w.Code, w.Body.String())
+	}
+	w = f.do(http.MethodPut, "/me/password", tokens.Token, `{"current_password":"secret-123","new_password":"new-secret-456"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("change password: %d %s", w.Code, w.Body.String())
+	}
+	var changed models.AuthTokens
+	if err := json.Unmarshal(w.Body.Bytes(), &changed); err != nil {
+		t.Fatalf("change password response: %v", err)
+	}
+	for _, token := range []string{tokens.Token, other} {
+		if w := f.do(http.MethodGet, "/me", token, ""); w.Code != http.StatusUnauthorized {
+			t.Fatalf("session after password change: %d %s", w.Code, w.Body.String())
+		}
+	}
+	if w := f.do(http.MethodGet, "/me", changed.Token, ""); w.Code != http.StatusOK {
+		t.Fatalf("new session after password change: %d %s", w.Code, w.Body.String())
+	}
+	session := loginToken(t, f, `{"email":"q@example.com","password":"new-secret-456"}`)
+
+	// Upcoming bookings block deletion until they are cancelled.
+	w = f.do(http.MethodPost, "/user/booking/"+f.propertyID.String(), session, booking)
+	if w.Code != http.StatusOK {
+		t.Fatalf("book: %d %s", w.Code, w.Body.String())
+	}
+	var booked struct {
+		BookingID string `json:"booking_id"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &booked); err != nil || booked.BookingID == "" {
+		t.Fatalf("book response: %s", w.Body.String())
+	}
+	if w := f.do(http.MethodDelete, "/me", session, `{"password":"wrong"}`); w.Code != http.StatusUnauthorized {
+		t.Fatalf("delete with wrong password: %d %s", w.Code, w.Body.String())
+	}
+	if w := f.do(http.MethodDelete, "/me", session, `{"password":"new-secret-456"}`); w.Code != http.StatusConflict {
+		t.Fatalf("delete with upcoming booking: %d %s", w.Code, w.Body.String())
+	}
+	if w := f.do(http.MethodDelete, "/cancel/booking/"+booked.BookingID, session, ""); w.Code != http.StatusOK {
+		t.Fatalf("cancel: %d %s", w.Code, w.Body.String())
+	}
+	if w := f.do(http.MethodDelete, "/me", session, `{"password":"new-secret-456"}`); w.Code != http.StatusOK {
+		t.Fatalf("delete: %d %s", w.Code, w.Body.String())
+	}
+	if w := f.do(http.MethodGet, "/me", session, ""); w.Code != http.StatusUnauthorized {
+		t.Fatalf("session after delete: %d %s", w.Code, w.Body.String())
+	}
+	if w := f.do(http.MethodPost, "/account/login", "", `{"email":"q@example.com","password":"new-secret-456"}`); w.Code != http.StatusUnauthorized {
+		t.Fatalf("login after delete: %d %s", w.Code, w.Body.String())
+	}
+	signIn(t, f, "/account/signup", `{"name":"q","email":"q@example.com","password":"secret-123"}`)
+}
+
+func TestDeletingHostRemovesProperties(t *testing.T) {
+	f := newFixture(t)
+	host := signIn(t, f, "/account/signup", `{"name":"h","email":"h@example.com","password":"secret-123","roles":["host"]}`)
+	w := f.do(http.MethodPost, "/property/create", host.Token, `{"property_name":"loft","price":5000,"max_guests":2,"address":{"city":"Lagos","country":"NG"}}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("create property: %d %s", w.Code, w.Body.String())
+	}
+	var created struct {
+		PropertyID string `json:"property_id"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+		t.Fatalf("create property response: %v", err)
+	}
+	if w := f.do(http.MethodDelete, "/me", host.Token, `{"password":"secret-123"}`); w.Code != http.StatusOK {
+		t.Fatalf("delete host: %d %s", w.Code, w.Body.String())
+	}
+	if w := f.do(http.MethodPost, "/property/"+created.PropertyID+"/quote", "", `{"check_in":"`+stayDate(0)+`","check_out":"`+stayDate(2)+`","guests":1}`); w.Code != http.StatusNotFound {
+		t.Fatalf("quote for deleted host's property: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestReviews(t *testing.T) {
+	f := newFixture(t)
+	property := f.propertyID.String()
+
+	checkIn := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -10)
+	stay := models.Booking{UserID: f.userAID, PropertyID: f.propertyID, CheckIn: checkIn, CheckOut: checkIn.AddDate(0, 0, 3), Guests: 1, Status: models.Completed}
+	if err := f.db.Create(&stay).Error; err != nil {
+		t.Fatalf("create stay: %v", err)
+	}
+	stayID := stay.ID.String()
+
+	if w := f.do(http.MethodPost, "/user/review/"+stayID, f.userA, `{"rating":6,"comment":"great"}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("out of range rating: %d %s", w.Code, w.Body.String())
+	}
+	w := f.do(http.MethodPost, "/user/review/"+stayID, f.userA, `{"rating":5,"comment":"great"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("review property: %d %s", w.Code, w.Body.String())
+	}
+	var guestReview models.GetReview
+	if err := json.Unmarshal(w.Body.Bytes(), &guestReview); err != nil {
+		t.Fatalf("review response: %v", err)
+	}
+	if guestReview.AuthorName != "user a" || guestReview.AuthorRole != models.GuestRole {
+		t.Fatalf("review = %+v", guestReview)
+	}
+	if w := f.do(http.MethodPost, "/user/review/"+stayID, f.userA, `{"rating":1,"comment":"again"}`); w.Code != http.StatusConflict || decodeProblem(t, w).Code != "already_reviewed" {
+		t.Fatalf("second review: %d %s", w.Code, w.Body.String())
+	}
+
+	w = f.do(http.MethodGet, "/property/"+property, f.ownerA, "")
+	var got models.GetProperty
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("property response: %v", err)
+	}
+	if got.RatingAverage != 5 || got.RatingCount != 1 {
+		t.Fatalf("rating = %v over %d, want 5 over 1", got.RatingAverage, got.RatingCount)
+	}
+
+	if w := f.do(http.MethodPost, "/owner/booking/"+stayID+"/review", f.ownerA, `{"rating":3,"comment":"left it messy"}`); w.Code != http.StatusOK {
+		t.Fatalf("review guest: %d %s", w.Code, w.Body.String())
+	}
+	var mine models.GetReviews
+	if err := json.Unmarshal(f.do(http.MethodGet, "/user/reviews", f.userA, "").Body.Bytes(), &mine); err != nil {
+		t.Fatalf("guest reviews response: %v", err)
+	}
+	if len(mine.Reviews) != 1 || mine.Reviews[0].AuthorName != "owner a" || mine.Reviews[0].Rating != 3 {
+		t.Fatalf("guest reviews = %+v", mine.Reviews)
+	}
+
+	reply := "/owner/review/" + guestReview.ReviewID.String() + "/reply"
+	w = f.do(http.MethodPut, reply, f.ownerA, `{"reply":"thanks"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("reply: %d %s", w.Code, w.Body.String())
+	}
+	var replied models.GetReview
+	if err := json.Unmarshal(w.Body.Bytes(), &replied); err != nil {
+		t.Fatalf("reply response: %v", err)
+	}
+	if replied.ReviewID != guestReview.ReviewID || replied.AuthorName != guestReview.AuthorName || replied.AuthorName == "" || replied.Reply != "thanks" || replied.RepliedAt == nil {
+		t.Fatalf("reply = %+v", replied)
+	}
+	if w := f.do(http.MethodPut, reply, f.ownerA, `{"reply":"thanks again"}`); w.Code != http.StatusConflict {
+		t.Fatalf("second reply: %d %s", w.Code, w.Body.String())
+	}
+
+	var page models.GetReviews
+	if err := json.Unmarshal(f.do(http.MethodGet, "/property/"+property+"/reviews?limit=1", "", "").Body.Bytes(), &page); err != nil {
+		t.Fatalf("property reviews response: %v", err)
+	}
+	if len(page.Reviews) != 1 || page.Reviews[0].ReviewID != guestReview.ReviewID || page.Reviews[0].Reply != "thanks" || page.NextCursor == "" {
+		t.Fatalf("first page = %+v", page)
+	}
+	path := "/property/" + property + "/reviews?limit=1&cursor=" + page.NextCursor
+	var last models.GetReviews
+	if err := json.Unmarshal(f.do(http.MethodGet, path, "", "").Body.Bytes(), &last); err != nil {
+		t.Fatalf("property reviews response: %v", err)
+	}
+	if len(last.Reviews) != 1 || last.Reviews[0].ReviewID != f.reviewID || last.NextCursor != "" {
+		t.Fatalf("second page = %+v", last)
+	}
+}
+
+func TestMessaging(t *testing.T) {
+	f := newFixture(t)
+	inquiry := `{"property_id":"` + f.propertyID.String() + `","body":"is there parking?"}`
+
+	var started models.GetConversation
+	for range 2 {
+		w := f.do(http.MethodPost, "/user/conversations", f.userA, inquiry)
+		if w.Code != http.StatusOK {
+			t.Fatalf("start conversation: %d %s", w.Code, w.Body.String())
+		}
+		var got models.GetConversation
+		if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+			t.Fatalf("conversation response: %v", err)
+		}
+		if started.ConversationID != uuid.Nil && got.ConversationID != started.ConversationID {
+			t.Fatalf("second inquiry opened conversation %s, want %s", got.ConversationID, started.ConversationID)
+		}
+		started = got
+	}
+	if started.BookingID != nil || started.Host.Name != "owner a" || started.ConversationID == f.conversationID {
+		t.Fatalf("inquiry = %+v", started)
+	}
+	thread := "/conversations/" + started.ConversationID.String()
+
+	inbox := func(token string) models.Inbox {
+		t.Helper()
+		w := f.do(http.MethodGet, "/inbox", token, "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("inbox: %d %s", w.Code, w.Body.String())
+		}
+		var got models.Inbox
+		if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+			t.Fatalf("inbox response: %v", err)
+		}
+		return got
+	}
+	got := inbox(f.ownerA)
+	if got.UnreadCount != 3 || len(got.Conversations) != 2 || got.Conversations[0].ConversationID != started.ConversationID || got.Conversations[0].UnreadCount != 2 {
+		t.Fatalf("host inbox = %+v", got)
+	}
+	if got := inbox(f.ownerB); len(got.Conversations) != 0 || got.UnreadCount != 0 {
+		t.Fatalf("other host's inbox = %+v", got)
+	}
+
+	var page models.GetMessages
+	if err := json.Unmarshal(f.do(http.MethodGet, thread+"/messages?limit=1", f.ownerA, "").Body.Bytes(), &page); err != nil {
+		t.Fatalf("messages response: %v", err)
+	}
+	if len(page.Messages) != 1 || page.Messages[0].Read || page.NextCursor == "" {
+		t.Fatalf("first page = %+v", page)
+	}
+
+	if w := f.do(http.MethodPost, thread+"/messages", f.ownerA, `{"body":"yes, two spaces"}`); w.Code != http.StatusOK {
+		t.Fatalf("reply: %d %s", w.Code, w.Body.String())
+	}
+	if got := inbox(f.ownerA); got.UnreadCount != 1 {
+		t.Fatalf("host unread after replying = %d, want 1", got.UnreadCount)
+	}
+	if got := inbox(f.userA); got.UnreadCount != 1 || got.Conversations[0].UnreadCount != 1 {
+		t.Fatalf("guest inbox = %+v", got)
+	}
+
+	var messages models.GetMessages
+	if err := json.Unmarshal(f.do(http.MethodGet, thread+"/messages", f.userA, "").Body.Bytes(), &messages); err != nil {
+		t.Fatalf("messages response: %v", err)
+	}
+	if len(messages.Messages) != 3 || messages.Messages[0].Read || !messages.Messages[1].Read || !messages.Messages[2].Read {
+		t.Fatalf("messages = %+v", messages.Messages)
+	}
+	if w := f.do(http.MethodPut, thread+"/read", f.userA, ""); w.Code != http.StatusOK {
+		t.Fatalf("mark read: %d %s", w.Code, w.Body.String())
+	}
+	if got := inbox(f.userA); got.UnreadCount != 0 {
+		t.Fatalf("guest unread after reading = %d, want 0", got.UnreadCount)
+	}
+
+	// Booking threads outlive the listing; new inquiries do not.
+	if w := f.do(http.MethodPost, "/admin/properties/"+f.propertyID.String()+"/unlist", f.admin, `{"reason":"test"}`); w.Code != http.StatusOK {
+		t.Fatalf("unlist: %d %s", w.Code, w.Body.String())
+	}
+	if w := f.do(http.MethodPost, "/user/conversations", f.userB, inquiry); w.Code != http.StatusNotFound {
+		t.Fatalf("inquiry about unlisted property: %d %s", w.Code, w.Body.String())
+	}
+	booking := `{"property_id":"` + f.propertyID.String() + `","booking_id":"` + f.bookingID.String() + `","body":"still on?"}`
+	if w := f.do(http.MethodPost, "/user/conversations", f.userA, booking); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), f.conversationID.String()) {
+		t.Fatalf("booking message about unlisted property: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestWishlists(t *testing.T) {
+	f := newFixture(t)
+	w := f.do(http.MethodPost, "/user/wishlists", f.userA, `{"name":"lake trip"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("create wishlist: %d %s", w.Code, w.Body.String())
+	}
+	var created models.WishlistSummary
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+		t.Fatalf("wishlist response: %v", err)
+	}
+	wishlist := "/user/wishlists/" + created.WishlistID.String()
+
+	w = f.do(http.MethodPost, "/property/create", f.ownerB, `{"property_name":"loft","price":5000,"max_guests":2,"address":{"city":"Lagos","country":"NG"}}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("create property: %d %s", w.Code, w.Body.String())
+	}
+	var loft struct {
+		PropertyID string `json:"property_id"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &loft); err != nil {
+		t.Fatalf("create property response: %v", err)
+	}
+	for _, property := range []string{f.propertyID.String(), loft.PropertyID, loft.PropertyID} {
+		if w := f.do(http.MethodPut, wishlist+"/properties/"+property, f.userA, ""); w.Code != http.StatusOK {
+			t.Fatalf("save property: %d %s", w.Code, w.Body.String())
+		}
+	}
+	var summaries []models.WishlistSummary
+	if err := json.Unmarshal(f.do(http.MethodGet, "/user/wishlists", f.userA, "").Body.Bytes(), &summaries); err != nil {
+		t.Fatalf("wishlists response: %v", err)
+	}
+	if len(summaries) != 2 || summaries[0].WishlistID != created.WishlistID || summaries[0].PropertyCount != 2 {
+		t.Fatalf("wishlists = %+v", summaries)
+	}
+
+	// The fixture booking holds the cabin for these nights.
+	checkIn := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 1, 0)
+	stay := "?check_in=" + checkIn.Format(models.DateLayout) + "&check_out=" + checkIn.AddDate(0, 0, 2).Format(models.DateLayout)
+	get := func(path, token string) models.GetWishlist {
+		t.Helper()
+		w := f.do(http.MethodGet, path, token, "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("get wishlist: %d %s", w.Code, w.Body.String())
+		}
+		var got models.GetWishlist
+		if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+			t.Fatalf("wishlist response: %v", err)
+		}
+		return got
+	}
+	got := get(wishlist+stay, f.userA)
+	if len(got.Properties) != 2 {
+		t.Fatalf("properties = %+v", got.Properties)
+	}
+	for _, saved := range got.Properties {
+		wantAvailable := saved.Property.PropertyID.String() == loft.PropertyID
+		if saved.Available == nil || *saved.Available != wantAvailable || saved.Quote == nil || saved.Quote.Nights != 2 {
+			t.Fatalf("%s: available = %v, quote = %+v", saved.Property.PropertyName, saved.Available, saved.Quote)
+		}
+	}
+	if got := get(wishlist, f.userA); got.Properties[0].Quote != nil {
+		t.Fatalf("quote without dates = %+v", got.Properties[0].Quote)
+	}
+	if w := f.do(http.MethodGet, wishlist+"?check_in="+stayDate(0), f.userA, ""); w.Code != http.StatusBadRequest {
+		t.Fatalf("check_in without check_out: %d %s", w.Code, w.Body.String())
+	}
+
+	share := func() string {
+		t.Helper()
+		w := f.do(http.MethodPost, wishlist+"/share", f.userA, "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("share: %d %s", w.Code, w.Body.String())
+		}
+		var link models.WishlistShare
+		if err := json.Unmarshal(w.Body.Bytes(), &link); err != nil {
+			t.Fatalf("share response: %v", err)
+		}
+		return strings.TrimPrefix(link.ShareURL, "http://api.test")
+	}
+	first := share()
+	if got := get(first+stay, ""); got.WishlistID != created.WishlistID || len(got.Properties) != 2 || got.Properties[0].Quote == nil {
+		t.Fatalf("shared wishlist = %+v", got)
+	}
+	second := share()
+	if w := f.do(http.MethodGet, first, "", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("replaced link: %d %s", w.Code, w.Body.String())
+	}
+	if w := f.do(http.MethodDelete, wishlist+"/share", f.userA, ""); w.Code != http.StatusOK {
+		t.Fatalf("unshare: %d %s", w.Code, w.Body.String())
+	}
+	if w := f.do(http.MethodGet, second, "", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("unshared link: %d %s", w.Code, w.Body.String())
+	}
+
+	if w := f.do(http.MethodDelete, "/property/"+loft.PropertyID, f.ownerB, ""); w.Code != http.StatusOK {
+		t.Fatalf("delete property: %d %s", w.Code, w.Body.String())
+	}
+	var items int64
+	if err := f.db.Model(&models.WishlistItem{}).Where("property_id = ?", loft.PropertyID).Count(&items).Error; err != nil {
+		t.Fatalf("count items: %v", err)
+	}
+	if got := get(wishlist, f.userA); items != 0 || len(got.Properties) != 1 {
+		t.Fatalf("after deleting the loft: %d items left, properties = %+v", items, got.Properties)
+	}
+
+	if w := f.do(http.MethodDelete, wishlist+"/properties/"+f.propertyID.String(), f.userA, ""); w.Code != http.StatusOK {
+		t.Fatalf("remove property: %d %s", w.Code, w.Body.String())
+	}
+	if w := f.do(http.MethodDelete, wishlist, f.userA, ""); w.Code != http.StatusOK {
+		t.Fatalf("delete wishlist: %d %s", w.Code, w.Body.String())
+	}
+	if w := f.do(http.MethodGet, wishlist, f.userA, ""); w.Code != http.StatusNotFound {
+		t.Fatalf("deleted wishlist: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestPropertyPhotos(t *testing.T) {
+	f := newFixture(t)
+	w := f.do(http.MethodPost, "/property/create", f.ownerB, `{"property_name":"loft","price":5000,"address":{"city":"Lagos","country":"NG"}}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("create property: %d %s", w.Code, w.Body.String())
+	}
+	var loft struct {
+		PropertyID string `json:"property_id"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &loft); err != nil {
+		t.Fatalf("create property response: %v", err)
+	}
+	photos := "/property/" + loft.PropertyID + "/photos"
+
+	upload := func(data []byte) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		part, err := form.CreateFormFile("photo", "photo.gif") // the name and type are ignored
+		if err != nil {
+			t.Fatalf("form: %v", err)
+		}
+		part.Write(data)
+		form.Close()
+		req := httptest.NewRequest(http.MethodPost, photos, &body)
+		req.Header.Set("Content-Type", form.FormDataContentType())
+		req.Header.Set("Authorization", "Bearer "+f.ownerB)
+		w := httptest.NewRecorder()
+		f.router.ServeHTTP(w, req)
+		return w
+	}
+	uploaded := func(data []byte) models.GetPhoto {
+		t.Helper()
+		w := upload(data)
+		if w.Code != http.StatusOK {
+			t.Fatalf("upload: %d %s", w.Code, w.Body.String())
+		}
+		var photo models.GetPhoto
+		if err := json.Unmarshal(w.Body.Bytes(), &photo); err != nil {
+			t.Fatalf("upload response: %v", err)
+		}
+		return photo
+	}
+	download := func(url string) []byte {
+		t.Helper()
+		w := f.do(http.MethodGet, strings.TrimPrefix(url, "http://api.test"), "", "")
+		if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "image/jpeg" {
+			t.Fatalf("download %s: %d %s", url, w.Code, w.Header().Get("Content-Type"))
+		}
+		return w.Body.Bytes()
+	}
+
+	var png3000 bytes.Buffer
+	if err := png.Encode(&png3000, image.NewNRGBA(image.Rect(0, 0, 3000, 1500))); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	wide := uploaded(png3000.Bytes())
+	if !wide.IsCover || wide.Width != 2048 || wide.Height != 1024 || len(wide.URLs) != len(models.PhotoSizes) {
+		t.Fatalf("wide photo = %+v", wide)
+	}
+	small, err := jpeg.DecodeConfig(bytes.NewReader(download(wide.URLs["small"])))
+	if err != nil || small.Width != 320 || small.Height != 160 {
+		t.Fatalf("small copy: %+v, %v", small, err)
+	}
+
+	// A JPEG taken sideways: orientation 6 means turn it clockwise.
+	var sideways bytes.Buffer
+	if err := jpeg.Encode(&sideways, image.NewRGBA(image.Rect(0, 0, 60, 30)), nil); err != nil {
+		t.Fatalf("encode jpeg: %v", err)
+	}
+	exif := []byte("Exif\x00\x00MM\x00\x2a\x00\x00\x00\x08\x00\x01\x01\x12\x00\x03\x00\x00\x00\x01\x00\x06\x00\x00\x00\x00\x00\x00")
+	segment := append([]byte{0xFF, 0xE1, 0, byte(len(exif) + 2)}, exif...)
+	tall := uploaded(append(append([]byte{0xFF, 0xD8}, segment...), sideways.Bytes()[2:]...))
+	if tall.IsCover || tall.Width != 30 || tall.Height != 60 {
+		t.Fatalf("sideways photo = %+v", tall)
+	}
+	if large := download(tall.URLs["large"]); bytes.Contains(large, []byte("Exif")) {
+		t.Fatal("stored copy kept its EXIF block")
+	}
+
+	if w := upload([]byte("GIF89a not really")); w.Code != http.StatusUnsupportedMediaType {
+		t.Fatalf("gif upload: %d %s", w.Code, w.Body.String())
+	}
+	if w := upload(append([]byte{0xFF, 0xD8, 0xFF}, make([]byte, 100)...)); w.Code != http.StatusBadRequest {
+		t.Fatalf("corrupt jpeg: %d %s", w.Code, w.Body.String())
+	}
+	if w := upload(make([]byte, models.MaxPhotoBytes+1)); w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("oversized upload: %d %s", w.Code, w.Body.String())
+	}
+	if w := f.do(http.MethodPost, photos, f.ownerB, `{}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("upload without a file: %d %s", w.Code, w.Body.String())
+	}
+
+	arrange := func(method, path, body string) []models.GetPhoto {
+		t.Helper()
+		w := f.do(method, path, f.ownerB, body)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s %s: %d %s", method, path, w.Code, w.Body.String())
+		}
+		var got models.GetPhotos
+		if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+			t.Fatalf("photos response: %v", err)
+		}
+		return got.Photos
+	}
+	got := arrange(http.MethodPut, photos+"/"+tall.PhotoID.String()+"/cover", "")
+	if len(got) != 2 || got[0].PhotoID != tall.PhotoID || !got[0].IsCover || got[1].IsCover {
+		t.Fatalf("after setting the cover: %+v", got)
+	}
+	got = arrange(http.MethodPut, photos, `{"photo_ids":["`+wide.PhotoID.String()+`","`+tall.PhotoID.String()+`"]}`)
+	if got[0].PhotoID != wide.PhotoID || got[1].PhotoID != tall.PhotoID {
+		t.Fatalf("after reordering: %+v", got)
+	}
+	for _, body := range []string{
+		`{"photo_ids":["` + wide.PhotoID.String() + `"]}`,
+		`{"photo_ids":["` + wide.PhotoID.String() + `","` + wide.PhotoID.String() + `"]}`,
+		`{"photo_ids":["` + wide.PhotoID.String() + `","` + f.photoID.String() + `"]}`,
+	} {
+		if w := f.do(http.MethodPut, photos, f.ownerB, body); w.Code != http.StatusBadRequest {
+			t.Fatalf("reorder with %s: %d %s", body, w.Code, w.Body.String())
+		}
+	}
+
+	// The loft is the newest listing.
+	var search models.GetAllProperties
+	if err := json.Unmarshal(f.do(http.MethodGet, "/property/all", "", "").Body.Bytes(), &search); err != nil {
+		t.Fatalf("search response: %v", err)
+	}
+	if len(search.Properties) == 0 || len(search.Properties[0].Photos) != 2 || search.Properties[0].Photos[0].URLs["medium"] != wide.URLs["medium"] {
+		t.Fatalf("search results = %+v", search.Properties)
+	}
+
+	if w := f.do(http.MethodDelete, photos+"/"+wide.PhotoID.String(), f.ownerB, ""); w.Code != http.StatusOK {
+		t.Fatalf("delete photo: %d %s", w.Code, w.Body.String())
+	}
+	if w := f.do(http.MethodGet, strings.TrimPrefix(wide.URLs["large"], "http://api.test"), "", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("deleted photo file: %d", w.Code)
+	}
+	var property models.GetProperty
+	if err := json.Unmarshal(f.do(http.MethodGet, "/property/"+loft.PropertyID, f.ownerB, "").Body.Bytes(), &property); err != nil {
+		t.Fatalf("property response: %v", err)
+	}
+	if len(property.Photos) != 1 || property.Photos[0].PhotoID != tall.PhotoID || !property.Photos[0].IsCover {
+		t.Fatalf("photos after deleting the cover = %+v", property.Photos)
+	}
+	if w := f.do(http.MethodDelete, photos+"/"+wide.PhotoID.String(), f.ownerB, ""); w.Code != http.StatusNotFound {
+		t.Fatalf("delete photo twice: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestPropertyDetails(t *testing.T) {
+	f := newFixture(t)
+	for _, body := range []string{`{"key":"wifi","name":"Wifi"}`, `{"key":"pool","name":"Pool"}`, `{"key":"kitchen","name":"Kitchen"}`} {
+		if w := f.do(http.MethodPost, "/admin/amenities", f.admin, body); w.Code != http.StatusOK {
+			t.Fatalf("create amenity: %d %s", w.Code, w.Body.String())
+		}
+	}
+	if w := f.do(http.MethodPost, "/admin/amenities", f.admin, `{"key":"wifi","name":"Wi-Fi"}`); w.Code != http.StatusConflict {
+		t.Fatalf("duplicate amenity: %d %s", w.Code, w.Body.String())
+	}
+	if w := f.do(http.MethodPost, "/admin/amenities", f.admin, `{"key":"Hot Tub","name":"Hot tub"}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("amenity with a bad key: %d %s", w.Code, w.Body.String())
+	}
+
+	create := func(body string) string {
+		t.Helper()
+		w := f.do(http.MethodPost, "/property/create", f.ownerB, body)
+		if w.Code != http.StatusOK {
+			t.Fatalf("create property: %d %s", w.Code, w.Body.String())
+		}
+		var created struct {
+			PropertyID string `json:"property_id"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+			t.Fatalf("create property response: %v", err)
+		}
+		return created.PropertyID
+	}
+	villa := create(`{"property_name":"villa","price":20000,"max_guests":6,"property_type":"entire_home","bedrooms":3,"beds":4,"bathrooms":2.5,
+		"amenities":["wifi","pool"],"house_rules":{"pets_allowed":true,"quiet_hours_start":"22:00","quiet_hours_end":"7:00"},"check_in_time":"16:00",
+		"address":{"city":"Lagos","country":"NG"}}`)
+	room := create(`{"property_name":"room","price":4000,"property_type":"private_room","amenities":["wifi"],"address":{"city":"Lagos","country":"NG"}}`)
+	for _, body := range []string{
+		`{"property_name":"hut","price":1000,"amenities":["sauna"],"address":{"city":"Lagos","country":"NG"}}`,
+		`{"property_name":"hut","price":1000,"bathrooms":1.2,"address":{"city":"Lagos","country":"NG"}}`,
+		`{"property_name":"hut","price":1000,"house_rules":{"quiet_hours_start":"22:00"},"address":{"city":"Lagos","country":"NG"}}`,
+		`{"property_name":"hut","price":1000,"property_type":"castle","address":{"city":"Lagos","country":"NG"}}`,
+	} {
+		if w := f.do(http.MethodPost, "/property/create", f.ownerB, body); w.Code != http.StatusBadRequest {
+			t.Fatalf("create with %s: %d %s", body, w.Code, w.Body.String())
+		}
+	}
+
+	get := func(id string) models.GetProperty {
+		t.Helper()
+		var property models.GetProperty
+		if err := json.Unmarshal(f.do(http.MethodGet, "/property/"+id, f.ownerB, "").Body.Bytes(), &property); err != nil {
+			t.Fatalf("property response: %v", err)
+		}
+		return property
+	}
+	got := get(villa)
+	if got.PropertyType != models.EntireHome || got.Bedrooms != 3 || got.Beds != 4 || got.Bathrooms != 2.5 || got.CheckInTime != "16:00" || got.CheckOutTime != models.DefaultCheckOutTime {
+		t.Fatalf("villa = %+v", got)
+	}
+	if len(got.Amenities) != 2 || got.Amenities[0].Key != "pool" || got.Amenities[1].Key != "wifi" {
+		t.Fatalf("villa amenities = %+v", got.Amenities)
+	}
+	if !got.HouseRules.PetsAllowed || got.HouseRules.SmokingAllowed || got.HouseRules.QuietHoursEnd != "07:00" {
+		t.Fatalf("villa house rules = %+v", got.HouseRules)
+	}
+	if got := get(room); got.MaxGuests != 1 || got.Beds != 1 || got.Bathrooms != 1 || got.CheckInTime != models.DefaultCheckInTime {
+		t.Fatalf("room defaults = %+v", got)
+	}
+
+	search := func(query string) []string {
+		t.Helper()
+		w := f.do(http.MethodGet, "/property/all?"+query, "", "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("search %s: %d %s", query, w.Code, w.Body.String())
+		}
+		var result models.GetAllProperties
+		if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+			t.Fatalf("search response: %v", err)
+		}
+		var names []string
+		for _, property := range result.Properties {
+			names = append(names, property.PropertyName)
+		}
+		return names
+	}
+	// The filters themselves are tested in repository.
+	for query, want := range map[string][]string{
+		"amenities=wifi":                                   {"room", "villa"},
+		"min_bedrooms=2&min_beds=4":                        {"villa"},
+		"pets_allowed=true&guests=7":                       nil,
+		"property_type=entire_home&amenities=pool,%20wifi": {"villa"},
+	} {
+		if got := search(query); !slices.Equal(got, want) {
+			t.Fatalf("search %s = %v, want %v", query, got, want)
+		}
+	}
+
+	w := f.do(http.MethodPatch, "/property/"+villa, f.ownerB, `{"amenities":["kitchen"],"house_rules":{"smoking_allowed":true},"max_guests":2}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("update property: %d %s", w.Code, w.Body.String())
+	}
+	got = get(villa)
+	if len(got.Amenities) != 1 || got.Amenities[0].Key != "kitchen" || got.HouseRules.PetsAllowed || !got.HouseRules.SmokingAllowed {
+		t.Fatalf("villa after update = %+v", got)
+	}
+	if w := f.do(http.MethodPatch, "/property/"+villa, f.ownerB, `{"price":25000}`); w.Code != http.StatusOK || len(get(villa).Amenities) != 1 {
+		t.Fatalf("update without amenities: %d %s", w.Code, w.Body.String())
+	}
+	if w := f.do(http.MethodPatch, "/property/"+villa, f.ownerB, `{"amenities":["sauna"]}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("update with an unknown amenity: %d %s", w.Code, w.Body.String())
+	}
+
+	stay := `{"check_in":"` + stayDate(0) + `","check_out":"` + stayDate(2) + `","guests":3}`
+	if w := f.do(http.MethodPost, "/property/"+villa+"/quote", "", stay); w.Code != http.StatusBadRequest || decodeProblem(t, w).Code != "too_many_guests" {
+		t.Fatalf("quote above capacity: %d %s", w.Code, w.Body.String())
+	}
+	if w := f.do(http.MethodPost, "/user/booking/"+villa, f.userA, stay); w.Code != http.StatusBadRequest || decodeProblem(t, w).Code != "too_many_guests" {
+		t.Fatalf("booking above capacity: %d %s", w.Code, w.Body.String())
+	}
+	if w := f.do(http.MethodPost, "/user/booking/"+villa, f.userA, strings.Replace(stay, `"guests":3`, `"guests":2`, 1)); w.Code != http.StatusOK {
+		t.Fatalf("booking at capacity: %d %s", w.Code, w.Body.String())
+	}
+
+	if w := f.do(http.MethodPatch, "/admin/amenities/kitchen", f.admin, `{"name":"Full kitchen"}`); w.Code != http.StatusOK {
+		t.Fatalf("rename amenity: %d %s", w.Code, w.Body.String())
+	}
+	if got := get(villa); got.Amenities[0].Name != "Full kitchen" {
+		t.Fatalf("renamed amenity = %+v", got.Amenities)
+	}
+	if w := f.do(http.MethodDelete, "/admin/amenities/kitchen", f.admin, ""); w.Code != http.StatusOK {
+		t.Fatalf("delete amenity: %d %s", w.Code, w.Body.String())
+	}
+	if got := get(villa); len(got.Amenities) != 0 {
+		t.Fatalf("amenities after deleting from the catalogue = %+v", got.Amenities)
+	}
+	if w := f.do(http.MethodDelete, "/admin/amenities/kitchen", f.admin, ""); w.Code != http.StatusNotFound {
+		t.Fatalf("delete amenity twice: %d %s", w.Code, w.Body.String())
+	}
+	var amenities []models.GetAmenity
+	if err := json.Unmarshal(f.do(http.MethodGet, "/amenities", "", "").Body.Bytes(), &amenities); err != nil {
+		t.Fatalf("amenities response: %v", err)
+	}
+	if len(amenities) != 2 || amenities[0].Key != "pool" || amenities[1].Key != "wifi" {
+		t.Fatalf("amenities = %+v", amenities)
+	}
+}
+
+func TestPricingRules(t *testing.T) {
+	f := newFixture(t)
+	w := f.do(http.MethodPost, "/property/create", f.ownerB, `{"property_name":"loft","price":10000,"cleaning_fee":2000,"weekly_discount":1000,"address":{"city":"Lagos","country":"NG"}}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("create property: %d %s", w.Code, w.Body.String())
+	}
+	var loft struct {
+		PropertyID string `json:"property_id"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &loft); err != nil {
+		t.Fatalf("create property response: %v", err)
+	}
+	pricing := "/property/" + loft.PropertyID + "/pricing"
+
+	// stayDate(0) is a Monday. The Saturday is in both date ranges; the
+	// shorter one wins. How rules combine is tested in pricing; this checks
+	// that stored rules reach quotes and bookings.
+	var ruleIDs []string
+	for _, body := range []string{
+		`{"kind":"weekday","weekdays":["fri","sat"],"adjustment":2500}`,
+		`{"kind":"date_range","start_date":"` + stayDate(2) + `","end_date":"` + stayDate(6) + `","price":9000}`,
+		`{"kind":"date_range","start_date":"` + stayDate(5) + `","end_date":"` + stayDate(6) + `","price":20000}`,
+	} {
+		w := f.do(http.MethodPost, pricing+"/rules", f.ownerB, body)
+		if w.Code != http.StatusOK {
+			t.Fatalf("add rule %s: %d %s", body, w.Code, w.Body.String())
+		}
+		var rule models.GetPriceRule
+		if err := json.Unmarshal(w.Body.Bytes(), &rule); err != nil {
+			t.Fatalf("rule response: %v", err)
+		}
+		ruleIDs = append(ruleIDs, rule.RuleID.String())
+	}
+	for _, body := range []string{
+		`{"kind":"weekday","weekdays":["fri"],"adjustment":0}`,
+		`{"kind":"weekday","weekdays":["friday"],"adjustment":1000}`,
+		`{"kind":"weekday","weekdays":["fri"],"adjustment":1000,"price":5000}`,
+		`{"kind":"date_range","start_date":"` + stayDate(6) + `","end_date":"` + stayDate(2) + `","price":9000}`,
+		`{"kind":"date_range","start_date":"` + stayDate(-6) + `","end_date":"` + stayDate(510) + `","price":9000}`,
+		`{"kind":"date_range","start_date":"` + stayDate(2) + `","end_date":"` + stayDate(6) + `"}`,
+	} {
+		if w := f.do(http.MethodPost, pricing+"/rules", f.ownerB, body); w.Code != http.StatusBadRequest {
+			t.Fatalf("add rule %s: %d %s", body, w.Code, w.Body.String())
+		}
+	}
+	var got models.GetPricing
+	if err := json.Unmarshal(f.do(http.MethodGet, pricing, f.ownerB, "").Body.Bytes(), &got); err != nil {
+		t.Fatalf("pricing response: %v", err)
+	}
+	if got.Price != 10000 || got.WeeklyDiscount != 1000 || len(got.Rules) != 3 || !slices.Equal(got.Rules[0].Weekdays, []string{"fri", "sat"}) || got.Rules[1].StartDate != stayDate(2) {
+		t.Fatalf("pricing = %+v", got)
+	}
+
+	quote := func(checkOut string) models.Quote {
+		t.Helper()
+		w := f.do(http.MethodPost, "/property/"+loft.PropertyID+"/quote", "", `{"check_in":"`+stayDate(0)+`","check_out":"`+checkOut+`","guests":1}`)
+		if w.Code != http.StatusOK {
+			t.Fatalf("quote: %d %s", w.Code, w.Body.String())
+		}
+		var q models.Quote
+		if err := json.Unmarshal(w.Body.Bytes(), &q); err != nil {
+			t.Fatalf("quote response: %v", err)
+		}
+		return q
+	}
+	week := quote(stayDate(7))
+	var prices []int64
+	for _, night := range week.NightlyPrices {
+		prices = append(prices, night.Price)
+	}
+	if want := []int64{10000, 10000, 9000, 9000, 9000, 20000, 10000}; !slices.Equal(prices, want) {
+		t.Fatalf("nightly prices = %v, want %v", prices, want)
+	}
+	if week.Amount(models.LineDiscount) != -7700 {
+		t.Fatalf("weekly quote = %+v", week)
+	}
+
+	w = f.do(http.MethodPost, "/user/booking/"+loft.PropertyID, f.userA, `{"check_in":"`+stayDate(0)+`","check_out":"`+stayDate(7)+`","guests":1}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("create booking: %d %s", w.Code, w.Body.String())
+	}
+	var booking struct {
+		TotalPrice int64 `json:"total_price"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &booking); err != nil {
+		t.Fatalf("booking response: %v", err)
+	}
+	if booking.TotalPrice != week.Total {
+		t.Fatalf("booking total = %d, quote total = %d", booking.TotalPrice, week.Total)
+	}
+
+	// The Saturday falls back to the longer range once the shorter one goes.
+	if w := f.do(http.MethodDelete, pricing+"/rules/"+ruleIDs[2], f.ownerB, ""); w.Code != http.StatusOK {
+		t.Fatalf("delete rule: %d %s", w.Code, w.Body.String())
+	}
+	if w := f.do(http.MethodDelete, pricing+"/rules/"+ruleIDs[2], f.ownerB, ""); w.Code != http.StatusNotFound {
+		t.Fatalf("delete rule twice: %d %s", w.Code, w.Body.String())
+	}
+	if got := quote(stayDate(7)); got.NightlyPrices[5].Price != 9000 {
+		t.Fatalf("nightly prices after delete = %+v", got.NightlyPrices)
+	}
+	if w := f.do(http.MethodPatch, "/property/"+loft.PropertyID, f.ownerB, `{"monthly_discount":9500}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("discount above 90%%: %d %s", w.Code, w.Body.String())
+	}
+}
+
+// TestStayRules checks that stay rules are stored and enforced by the
+// booking, quote and availability routes. The rules themselves are tested in
+// models.
+func TestStayRules(t *testing.T) {
+	f := newFixture(t)
+	if w := f.do(http.MethodPost, "/property/create", f.ownerB, `{"property_name":"chalet","price":10000,"address":{"city":"Lagos","country":"NG"},"stay_rules":{"min_nights":3,"max_nights":2}}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("create property with max_nights below min_nights: %d %s", w.Code, w.Body.String())
+	}
+	w := f.do(http.MethodPost, "/property/create", f.ownerB, `{"property_name":"chalet","price":10000,"address":{"city":"Lagos","country":"NG"},"stay_rules":{"min_nights":3,"max_nights":7,"advance_notice_days":2,"booking_window_days":60,"check_in_days":["sat"]}}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("create property: %d %s", w.Code, w.Body.String())
+	}
+	var chalet struct {
+		PropertyID string `json:"property_id"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &chalet); err != nil {
+		t.Fatalf("create property response: %v", err)
+	}
+	var got models.GetProperty
+	if err := json.Unmarshal(f.do(http.MethodGet, "/property/"+chalet.PropertyID, f.ownerB, "").Body.Bytes(), &got); err != nil {
+		t.Fatalf("property response: %v", err)
+	}
+	if got.StayRules.MinNights != 3 || got.StayRules.BookingWindowDays != 60 || !got.StayRules.CheckInDays.Allows(time.Saturday) || got.StayRules.CheckInDays.Allows(time.Friday) {
+		t.Fatalf("stay rules = %+v", got.StayRules)
+	}
+	if w := f.do(http.MethodPatch, "/property/"+chalet.PropertyID, f.ownerB, `{"stay_rules":{"min_nights":5,"max_nights":4}}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("max_nights below min_nights: %d %s", w.Code, w.Body.String())
+	}
+
+	today := models.Today(time.Now())
+	saturday := today.AddDate(0, 0, 2)
+	for saturday.Weekday() != time.Saturday {
+		saturday = saturday.AddDate(0, 0, 1)
+	}
+	day := func(offset int) string { return saturday.AddDate(0, 0, offset).Format(models.DateLayout) }
+
+	// A quote refuses the stay the same way booking it does.
+	tooLong := `{"check_in":"` + day(0) + `","check_out":"` + day(8) + `","guests":1}`
+	for _, w := range []*httptest.ResponseRecorder{
+		f.do(http.MethodPost, "/user/booking/"+chalet.PropertyID, f.userA, tooLong),
+		f.do(http.MethodPost, "/property/"+chalet.PropertyID+"/quote", "", tooLong),
+	} {
+		problem := decodeProblem(t, w)
+		if w.Code != http.StatusBadRequest || problem.Code != "stay_not_allowed" || len(problem.Errors) != 1 || problem.Errors[0].Code != models.StayMaxNights {
+			t.Fatalf("eight-night stay: %d %s", w.Code, w.Body.String())
+		}
+	}
+	if w := f.do(http.MethodPost, "/user/booking/"+chalet.PropertyID, f.userA, `{"check_in":"`+day(0)+`","check_out":"`+day(3)+`","guests":1}`); w.Code != http.StatusOK {
+		t.Fatalf("book allowed stay: %d %s", w.Code, w.Body.String())
+	}
+
+	// The calendar looks past its range to judge the nights near its ends.
+	from, to := day(3), day(5)
+	w = f.do(http.MethodGet, "/property/"+chalet.PropertyID+"/availability?from="+from+"&to="+to, "", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("availability: %d %s", w.Code, w.Body.String())
+	}
+	var calendar models.PropertyAvailability
+	if err := json.Unmarshal(w.Body.Bytes(), &calendar); err != nil {
+		t.Fatalf("availability response: %v", err)
+	}
+	if len(calendar.Nights) != 2 {
+		t.Fatalf("calendar = %+v", calendar.Nights)
+	}
+	for _, night := range calendar.Nights {
+		if night.Status != models.NightUnbookable || night.Reason != models.StayCheckInDay {
+			t.Fatalf("night %+v, want unbookable for %s", night, models.StayCheckInDay)
+		}
+	}
+}
+
+func TestBookingDates(t *testing.T) {
+	f := newFixture(t)
+	// The fixture's booking is pending for the three nights from checkIn.
+	checkIn := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 1, 0)
+	day := func(offset int) string { return checkIn.AddDate(0, 0, offset).Format(models.DateLayout) }
+	book := func(from, to string) *httptest.ResponseRecorder {
+		return f.do(http.MethodPost, "/user/booking/"+f.propertyID.String(), f.userB, `{"check_in":"`+from+`","check_out":"`+to+`","guests":1}`)
+	}
+
+	for _, tc := range []struct {
+		name, from, to string
+		status         int
+		code           string
+	}{
+		{"overlaps pending booking", day(2), day(5), http.StatusConflict, "booking_overlap"},
+		{"past check-in", time.Now().UTC().AddDate(0, 0, -2).Format(models.DateLayout), time.Now().UTC().AddDate(0, 0, 1).Format(models.DateLayout), http.StatusBadRequest, "invalid_dates"},
+		{"check-out on check-in", day(10), day(10), http.StatusBadRequest, "invalid_dates"},
+		{"check-out before check-in", day(12), day(10), http.StatusBadRequest, "invalid_dates"},
+		{"longer than a year", day(10), day(10 + models.MaxStayNights + 1), http.StatusBadRequest, "invalid_dates"},
+	} {
+		w := book(tc.from, tc.to)
+		if problem := decodeProblem(t, w); w.Code != tc.status || problem.Code != tc.code {
+			t.Fatalf("%s: %d %s, want %d %s", tc.name, w.Code, w.Body.String(), tc.status, tc.code)
+		}
+	}
+	// Quotes need no login, so their length is capped before any pricing.
+	w := f.do(http.MethodPost, "/property/"+f.propertyID.String()+"/quote", "", `{"check_in":"`+day(10)+`","check_out":"9999-01-01","guests":1}`)
+	if problem := decodeProblem(t, w); w.Code != http.StatusBadRequest || problem.Code != "invalid_dates" {
+		t.Fatalf("quote for thousands of years: %d %s", w.Code, w.Body.String())
+	}
+
+	// Back-to-back stays share no night.
+	if w := book(day(3), day(5)); w.Code != http.StatusOK {
+		t.Fatalf("book from the check-out day: %d %s", w.Code, w.Body.String())
+	}
+	if w := f.do(http.MethodPut, "/owner/booking/"+f.bookingID.String(), f.ownerA, ""); w.Code != http.StatusOK {
+		t.Fatalf("confirm booking: %d %s", w.Code, w.Body.String())
+	}
+	w = book(day(-1), day(1))
+	if problem := decodeProblem(t, w); w.Code != http.StatusConflict || problem.Code != "booking_overlap" {
+		t.Fatalf("overlaps confirmed booking: %d %s", w.Code, w.Body.String())
+	}
+
+	property := "/property/" + f.propertyID.String()
+	w = f.do(http.MethodPost, property+"/block", f.ownerA, `{"start_date":"`+day(10)+`","end_date":"`+day(12)+`"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("block dates: %d %s", w.Code, w.Body.String())
+	}
+	var block struct {
+		BlockID string `json:"block_id"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &block); err != nil {
+		t.Fatalf("block response: %v", err)
+	}
+	calendar := func() []string {
+		t.Helper()
+		var got models.PropertyAvailability
+		if err := json.Unmarshal(f.do(http.MethodGet, property+"/availability?from="+day(2)+"&to="+day(13), "", "").Body.Bytes(), &got); err != nil {
+			t.Fatalf("availability response: %v", err)
+		}
+		var statuses []string
+		for _, night := range got.Nights {
+			statuses = append(statuses, night.Status)
+		}
+		return statuses
+	}
+	booked, free, blocked := models.NightBooked, models.NightAvailable, models.NightBlocked
+	if got, want := calendar(), []string{booked, booked, booked, free, free, free, free, free, blocked, blocked, free}; !slices.Equal(got, want) {
+		t.Fatalf("calendar = %v, want %v", got, want)
+	}
+	if w := book(day(9), day(12)); w.Code != http.StatusConflict || decodeProblem(t, w).Code != "dates_blocked" {
+		t.Fatalf("book across blocked dates: %d %s", w.Code, w.Body.String())
+	}
+	if w := f.do(http.MethodDelete, property+"/block/"+block.BlockID, f.ownerA, ""); w.Code != http.StatusOK {
+		t.Fatalf("unblock dates: %d %s", w.Code, w.Body.String())
+	}
+	if got := calendar(); slices.Contains(got, blocked) {
+		t.Fatalf("calendar after unblocking = %v", got)
+	}
+	if w := book(day(9), day(12)); w.Code != http.StatusOK {
+		t.Fatalf("book unblocked dates: %d %s", w.Code, w.Body.String())
+	}
+}